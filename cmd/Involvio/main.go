@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Slava02/Involvio/pkg/logger"
 	"log/slog"
@@ -9,6 +10,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// embedded IANA database, the runtime image has no zoneinfo
+	_ "time/tzdata"
 
 	"github.com/Slava02/Involvio/config"
 	"github.com/Slava02/Involvio/internal/app"
@@ -29,42 +32,53 @@ func main() {
 	err = run(ctx, cancel, cfg, slog.Default())
 	if err != nil {
 		slog.Error(fmt.Sprintf("Failed to run application: %v", err))
-		return
+		os.Exit(1)
 	}
 }
 
 func run(ctx context.Context, cancelFunc context.CancelFunc, cfg *config.Config, logger *slog.Logger) error {
 	// Run the application
 	application := app.NewApp()
-	go app.Run(application.Server, cfg)
 
-	stopped := make(chan struct{})
+	runErr := make(chan error, 1)
 	go func() {
-		// Используем буферизированный канал, как рекомендовано внутри signal.Notify функции
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		runErr <- app.Run(ctx, application.Server, cfg)
+	}()
 
-		// Блокируемся и ожидаем из канала quit - interrupt signal,
-		// чтобы сделать gracefully shutdown с таймаутом в 10 сек
-		<-quit
+	// Используем буферизированный канал, как рекомендовано внутри signal.Notify функции
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-		// Завершаем работу горутин
+	// Блокируемся и ожидаем из канала quit - interrupt signal,
+	// чтобы сделать gracefully shutdown с таймаутом в 10 сек.
+	// Если приложение не смогло запуститься, завершаемся с ошибкой
+	select {
+	case err := <-runErr:
 		cancelFunc()
+		if err == nil {
+			err = errors.New("server stopped unexpectedly")
+		}
+		return err
+	case <-quit:
+	}
 
-		// Получили SIGINT (0x2) или SIGTERM (0xf), выполняем graceful shutdown
-		exitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
+	// Завершаем работу горутин
+	cancelFunc()
 
-		if err := application.Server.ShutdownWithContext(exitCtx); err != nil {
-			logger.Error("gracefully shutdown error")
-		} else {
-			logger.Warn("Server stopped")
-		}
+	// Получили SIGINT (0x2) или SIGTERM (0xf), выполняем graceful shutdown
+	exitCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-		close(stopped)
-	}()
+	if err := application.Server.ShutdownWithContext(exitCtx); err != nil {
+		logger.Error("gracefully shutdown error")
+	} else {
+		logger.Warn("Server stopped")
+	}
 
-	<-stopped
+	// Ждём, пока Run закроет соединения и отправит оставшиеся спаны
+	if err := <-runErr; err != nil {
+		return err
+	}
 
 	slog.Info("Server gracefully stopped, bye, bye!")
 
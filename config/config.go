@@ -6,14 +6,19 @@ import (
 	"log/slog"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
 type (
 	Config struct {
-		App  `json:"app"`
-		HTTP `json:"rest"`
-		DB   `json:"db"`
-		Log  `json:"logger"`
+		App         `json:"app"`
+		HTTP        `json:"rest"`
+		DB          `json:"db"`
+		Log         `json:"logger"`
+		Tracing     `json:"tracing"`
+		Retention   `json:"retention"`
+		RateLimit   `json:"rate_limit"`
+		Idempotency `json:"idempotency"`
 	}
 
 	App struct {
@@ -23,6 +28,8 @@ type (
 
 	HTTP struct {
 		Port string `env-required:"false" json:"port" env:"HTTP_PORT"`
+		// RequireIfMatch rejects updates without If-Match with 428.
+		RequireIfMatch bool `json:"require_if_match" env:"HTTP_REQUIRE_IF_MATCH" env-default:"false"`
 	}
 
 	DB struct {
@@ -37,6 +44,39 @@ type (
 	Log struct {
 		Level slog.Level `env-required:"false" json:"level"   env:"LOG_LEVEL"`
 	}
+
+	// Tracing configures OTLP export, spans are dropped when URL is empty.
+	Tracing struct {
+		URL         string  `json:"url"          env:"TRACING_URL"`
+		SampleRatio float64 `json:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	}
+
+	// Retention configures purging of soft deleted rows. Durations are set
+	// through env only, encoding/json can't parse "720h".
+	Retention struct {
+		Period   time.Duration `json:"-" env:"RETENTION_PERIOD"   env-default:"720h"`
+		Interval time.Duration `json:"-" env:"RETENTION_INTERVAL" env-default:"1h"`
+	}
+
+	// RateLimit configures request limits per user or IP. Store is "memory"
+	// for a single replica or "postgres" to share buckets between replicas.
+	RateLimit struct {
+		Store      string                   `json:"store"      env:"RATE_LIMIT_STORE" env-default:"memory"`
+		Default    RateLimitRule            `json:"default"`
+		Operations map[string]RateLimitRule `json:"operations"`
+	}
+
+	// Idempotency configures how long responses are replayed for Idempotency-Key retries.
+	Idempotency struct {
+		TTL             time.Duration `json:"-" env:"IDEMPOTENCY_TTL"              env-default:"24h"`
+		CleanupInterval time.Duration `json:"-" env:"IDEMPOTENCY_CLEANUP_INTERVAL" env-default:"1h"`
+	}
+
+	// RateLimitRule allows PerMinute requests with bursts up to Burst, zero PerMinute disables the limit.
+	RateLimitRule struct {
+		PerMinute float64 `json:"per_minute"`
+		Burst     int     `json:"burst"`
+	}
 )
 
 // LoadConfig returns Involvio config.
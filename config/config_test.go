@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -20,6 +21,8 @@ func TestLoadConfig(t *testing.T) {
 	os.Setenv("PG_POOL_MAX", "10")
 	os.Setenv("LOG_LEVEL", "info")
 	os.Setenv("TRACING_URL", "http://localhost:14268/api/traces")
+	os.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	os.Setenv("RETENTION_PERIOD", "48h")
 
 	// Load the configuration
 	cfg, err := LoadConfig()
@@ -39,6 +42,14 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "testdb", cfg.DB.DBName)
 	assert.Equal(t, int32(10), cfg.DB.PoolMax)
 	assert.Equal(t, slog.LevelInfo, cfg.Log.Level)
+	assert.Equal(t, "http://localhost:14268/api/traces", cfg.Tracing.URL)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	assert.Equal(t, 48*time.Hour, cfg.Retention.Period)
+	assert.Equal(t, time.Hour, cfg.Retention.Interval)
+	assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+	assert.Equal(t, "memory", cfg.RateLimit.Store)
+	assert.Equal(t, RateLimitRule{PerMinute: 120, Burst: 60}, cfg.RateLimit.Default)
+	assert.Equal(t, RateLimitRule{PerMinute: 5, Burst: 5}, cfg.RateLimit.Operations["CreateSpace"])
 }
 
 func TestLoadConfigMissingRequiredField(t *testing.T) {
@@ -2,22 +2,37 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/Slava02/Involvio/config"
 	"github.com/Slava02/Involvio/internal/app/route"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/middleware"
+	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/usecase"
 	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"github.com/Slava02/Involvio/pkg/ratelimit"
+	"github.com/Slava02/Involvio/pkg/tracing"
+	"github.com/Slava02/Involvio/pkg/worker"
+	"github.com/exaring/otelpgx"
 	"github.com/gofiber/contrib/otelfiber/v2"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/healthcheck"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/monitor"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"log/slog"
 	"os"
+	"sync"
+	"time"
 )
 
+const rateLimitStorePostgres = "postgres"
+
 // Run creates objects via constructors.
 // TODO: добавить внедрение зависимостей
 type App struct {
@@ -30,13 +45,27 @@ func NewApp() *App {
 	}
 }
 
-func Run(router *fiber.App, cfg *config.Config) {
+// Run returns when server stops, error means the application couldn't start or serve.
+func Run(ctx context.Context, router *fiber.App, cfg *config.Config) error {
 	// fiber middlewares
-	router.Use(logger.New())
+	router.Use(requestid.New())
+	router.Use(logger.New(logger.Config{
+		Format: "${time} | ${locals:requestid} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
+	}))
 
 	// open telemetry
+	shutdownTracing, err := tracing.New(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("tracing setup failed: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("tracing shutdown failed", slog.String("error", err.Error()))
+		}
+	}()
+
 	router.Use(otelfiber.Middleware())
-	router.Use(healthcheck.New())
+	router.Use(middleware.StoreSpan)
 	router.Use(recover.New(recover.Config{
 		EnableStackTrace: true,
 	}))
@@ -47,21 +76,79 @@ func Run(router *fiber.App, cfg *config.Config) {
 	}
 
 	// Connect to Database
-	pg, err := database.New(cfg, database.MaxPoolSize(cfg.DB.PoolMax), database.Isolation(pgx.ReadCommitted))
+	pg, err := database.New(cfg,
+		database.MaxPoolSize(cfg.DB.PoolMax),
+		database.Isolation(pgx.ReadCommitted),
+		database.Tracer(otelpgx.NewTracer()),
+	)
 	if err != nil {
-		slog.Error("postgres connection failed", slog.String("error", err.Error()))
-		return
+		return fmt.Errorf("postgres connection failed: %w", err)
 	}
 	defer pg.Close()
 
 	err = applyMigrations(cfg.DB)
 	if err != nil {
-		slog.Error("apply migrations failed", slog.String("error", err.Error()))
-		return
+		return fmt.Errorf("apply migrations failed: %w", err)
+	}
+
+	migration, err := latestMigration(migrationsDir)
+	if err != nil {
+		return fmt.Errorf("read migrations failed: %w", err)
+	}
+
+	// Purge rows soft deleted longer than retention period
+	retentionOnce := sync.Once{}
+	retentionUseCase := usecase.NewRetentionUseCase(repository.NewRetentionRepository(&retentionOnce, pg), cfg.Retention.Period)
+	retentionWorker := worker.New("retention", cfg.Retention.Interval, retentionUseCase.Purge)
+	go retentionWorker.Run(ctx)
+
+	workers := []*worker.Worker{retentionWorker}
+
+	// Rate limits, buckets in postgres are shared by replicas
+	var store ratelimit.Store = ratelimit.NewMemory()
+	if cfg.RateLimit.Store == rateLimitStorePostgres {
+		rateLimitOnce := sync.Once{}
+		rateLimitRepo := repository.NewRateLimitRepository(&rateLimitOnce, pg)
+		store = rateLimitRepo
+
+		rateLimitWorker := worker.New("rate_limit", time.Minute, func(ctx context.Context) error {
+			_, err := rateLimitRepo.Purge(ctx, time.Now())
+			return err
+		})
+		go rateLimitWorker.Run(ctx)
+		workers = append(workers, rateLimitWorker)
 	}
+	limiter := middleware.NewRateLimiter(store, rateLimits(cfg.RateLimit))
+
+	// Responses replayed to retried create requests
+	idempotencyOnce := sync.Once{}
+	idempotencyRepo := repository.NewIdempotencyRepository(&idempotencyOnce, pg)
+	idempotencyWorker := worker.New("idempotency", cfg.Idempotency.CleanupInterval, func(ctx context.Context) error {
+		_, err := idempotencyRepo.Purge(ctx, time.Now())
+		return err
+	})
+	go idempotencyWorker.Run(ctx)
+	workers = append(workers, idempotencyWorker)
+	idempotency := middleware.NewIdempotency(idempotencyRepo, cfg.Idempotency.TTL, "CreateUser", "CreateSpace", "CreateEvent", "CreateRound", "PublishRoundPreview")
+
+	// Probes
+	probes := &health{pg: pg, migration: migration, workers: workers}
+	router.Get("/livez", probes.livez)
+	router.Get("/readyz", probes.readyz)
+
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(pg.Pool))
+	router.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
 
 	// Setup routes
-	route.SetupRoutes(router, pg)
+	middlewares := []route.Middleware{limiter}
+	if cfg.HTTP.RequireIfMatch {
+		middlewares = append(middlewares, middleware.NewRequireIfMatch(
+			"UpdateSpace", "PatchSpace", "UpdateSpaceSettings", "UpdateUser", "PatchUser", "UpdateUserForm", "PatchUserForm",
+		))
+	}
+	middlewares = append(middlewares, idempotency)
+
+	route.SetupRoutes(router, pg, middlewares...)
 
 	PrintSystemData()
 	PrintMemoryInfo()
@@ -69,6 +156,20 @@ func Run(router *fiber.App, cfg *config.Config) {
 	// Start server
 	slog.Info("Starting server on port: " + cfg.HTTP.Port)
 	if err := router.Listen(":" + cfg.HTTP.Port); err != nil {
-		slog.Error(fmt.Sprintf("server starting error: %v", err))
+		return fmt.Errorf("server starting error: %w", err)
 	}
+
+	return nil
+}
+
+func rateLimits(cfg config.RateLimit) ratelimit.Limits {
+	limits := ratelimit.Limits{
+		Default:    ratelimit.Limit{PerMinute: cfg.Default.PerMinute, Burst: cfg.Default.Burst},
+		Operations: make(map[string]ratelimit.Limit, len(cfg.Operations)),
+	}
+	for operation, rule := range cfg.Operations {
+		limits.Operations[operation] = ratelimit.Limit{PerMinute: rule.PerMinute, Burst: rule.Burst}
+	}
+
+	return limits
 }
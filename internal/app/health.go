@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/Slava02/Involvio/pkg/worker"
+	"github.com/gofiber/fiber/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	migrationsDir      = "migrations"
+	readinessTimeout   = time.Second
+	statusOK           = "ok"
+	statusUnavailable  = "unavailable"
+	migrationsTableSQL = `SELECT version, dirty FROM schema_migrations`
+)
+
+// health answers liveness and readiness probes.
+type health struct {
+	pg        *database.Postgres
+	migration int64
+	workers   []*worker.Worker
+}
+
+type (
+	check struct {
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	readiness struct {
+		Status     string          `json:"status"`
+		Database   check           `json:"database"`
+		Migrations check           `json:"migrations"`
+		Workers    []worker.Status `json:"workers"`
+	}
+)
+
+// livez reports that the process is up and serving requests.
+func (h *health) livez(c *fiber.Ctx) error {
+	return c.JSON(check{Status: statusOK})
+}
+
+// readyz reports whether the service can handle traffic: database is reachable,
+// schema is migrated to the version this build expects and workers are running.
+func (h *health) readyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+	defer cancel()
+
+	resp := readiness{
+		Status:     statusOK,
+		Database:   check{Status: statusOK},
+		Migrations: check{Status: statusOK},
+		Workers:    make([]worker.Status, 0, len(h.workers)),
+	}
+
+	fail := func(c *check, err error) {
+		c.Status, c.Error = statusUnavailable, err.Error()
+		resp.Status = statusUnavailable
+	}
+
+	if err := h.pg.Pool.Ping(ctx); err != nil {
+		fail(&resp.Database, err)
+	}
+
+	if err := h.checkMigration(ctx); err != nil {
+		fail(&resp.Migrations, err)
+	}
+
+	for _, w := range h.workers {
+		status := w.Status()
+		if !status.Running {
+			resp.Status = statusUnavailable
+		}
+		resp.Workers = append(resp.Workers, status)
+	}
+
+	if resp.Status != statusOK {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+
+	return c.JSON(resp)
+}
+
+func (h *health) checkMigration(ctx context.Context) error {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := h.pg.Pool.QueryRow(ctx, migrationsTableSQL).Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("couldn't read migration version: %w", err)
+	}
+
+	switch {
+	case dirty:
+		return fmt.Errorf("migration %d is dirty", version)
+	case version != h.migration:
+		return fmt.Errorf("schema version %d, expected %d", version, h.migration)
+	}
+
+	return nil
+}
+
+// latestMigration returns the highest version among migration files in dir.
+func latestMigration(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration name %q: %w", e.Name(), err)
+		}
+
+		latest = max(latest, version)
+	}
+
+	return latest, nil
+}
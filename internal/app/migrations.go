@@ -62,6 +62,7 @@ func applyMigrations(cfg config.DB) error {
 
 	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		slog.Error(fmt.Sprintf("Migrate: up error: %s", err))
+		return err
 	}
 
 	if errors.Is(err, migrate.ErrNoChange) {
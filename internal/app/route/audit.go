@@ -0,0 +1,45 @@
+package route
+
+import (
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/audit"
+	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/danielgtaylor/huma/v2"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+func setupAuditRoutes(api huma.API, pg *database.Postgres) {
+	auditOnce, spaceOnce := sync.Once{}, sync.Once{}
+	auditUseCase := usecase.NewAuditUseCase(repository.NewAuditRepository(&auditOnce, pg), repository.NewSpaceRepository(&spaceOnce, pg))
+
+	auditHandler := audit.NewAuditHandler(auditUseCase)
+
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	auditLogSchema := huma.SchemaFromType(registry, reflect.TypeOf(&audit.AuditLogResponse{}))
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "GetSpaceAuditLog",
+		Method:        http.MethodGet,
+		Path:          "/spaces/{id}/audit",
+		Summary:       "get space audit log",
+		Description:   "List audit entries of administrative and membership actions in the space to its admins.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusOK,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Audit entries page",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: auditLogSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, auditHandler.GetAuditLog)
+}
@@ -14,8 +14,14 @@ import (
 
 //nolint:funlen
 func setupEventRoutes(api huma.API, pg *database.Postgres) {
-	o := sync.Once{}
-	eventUseCase := usecase.NewEventUseCase(repository.NewEventRepository(&o, pg))
+	eventOnce, spaceOnce, auditOnce := sync.Once{}, sync.Once{}, sync.Once{}
+	eventUseCase := usecase.NewAuditedEventUseCase(
+		usecase.NewEventUseCase(
+			repository.NewEventRepository(&eventOnce, pg),
+			repository.NewSpaceRepository(&spaceOnce, pg),
+		),
+		repository.NewAuditRepository(&auditOnce, pg),
+	)
 
 	eventHandler := event.NewEventHandler(eventUseCase)
 
@@ -46,33 +52,8 @@ func setupEventRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, eventHandler.CreateEvent)
 
@@ -92,46 +73,9 @@ func setupEventRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IEventUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IEventUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, eventHandler.GetEvent)
 
@@ -148,46 +92,9 @@ func setupEventRoutes(api huma.API, pg *database.Postgres) {
 				Description: "joined IEventUC",
 				Content:     map[string]*huma.MediaType{},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IEventUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IEventUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, eventHandler.JoinEvent)
 
@@ -204,46 +111,34 @@ func setupEventRoutes(api huma.API, pg *database.Postgres) {
 				Description: "deleted IEventUC",
 				Content:     map[string]*huma.MediaType{},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IEventUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IEventUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, eventHandler.DeleteEvent)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "RestoreEvent",
+		Method:        http.MethodPost,
+		Path:          "/events/{id}/restore",
+		Summary:       "restore event",
+		Description:   "Restore deleted event with its participants.",
+		Tags:          []string{"Events"},
+		DefaultStatus: http.StatusOK,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "IEventUC restored",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
+						Schema: eventSchema,
 					},
 				},
 			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Deleted event not found"),
+			"409": problemResponse(api, "Space of the event is deleted"),
+			"500": problemResponse(api, "Internal server error"),
 		},
-	}, eventHandler.DeleteEvent)
+	}, eventHandler.RestoreEvent)
 }
@@ -0,0 +1,130 @@
+package route
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/meeting"
+	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/danielgtaylor/huma/v2"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+//nolint:funlen
+func setupMeetingRoutes(api huma.API, pg *database.Postgres) {
+	meetingOnce, roundOnce, spaceOnce, auditOnce := sync.Once{}, sync.Once{}, sync.Once{}, sync.Once{}
+	meetingRepo := repository.NewMeetingRepository(&meetingOnce, pg)
+	meetingUseCase := usecase.NewAuditedMeetingUseCase(
+		usecase.NewMeetingUseCase(
+			meetingRepo,
+			usecase.NewRematcher(
+				repository.NewRoundRepository(&roundOnce, pg),
+				meetingRepo,
+				repository.NewSpaceRepository(&spaceOnce, pg),
+			),
+		),
+		repository.NewAuditRepository(&auditOnce, pg),
+	)
+
+	meetingHandler := meeting.NewMeetingHandler(meetingUseCase)
+
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	meetingSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.Meeting{}))
+
+	meetingResponse := &huma.Response{
+		Description: "Meeting response",
+		Content: map[string]*huma.MediaType{
+			"application/json": {
+				Schema: meetingSchema,
+			},
+		},
+	}
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetMeeting",
+		Method:      http.MethodGet,
+		Path:        "/meetings/{id}",
+		Summary:     "meeting by id",
+		Description: "Get meeting with its participants.",
+		Tags:        []string{"Meetings"},
+		Responses: map[string]*huma.Response{
+			"200": meetingResponse,
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "Meeting not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, meetingHandler.GetMeeting)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "AcceptMeeting",
+		Method:      http.MethodPost,
+		Path:        "/meetings/{id}/accept",
+		Summary:     "accept meeting",
+		Description: "Accept proposed meeting, it becomes accepted when all participants accept it.",
+		Tags:        []string{"Meetings"},
+		Responses: map[string]*huma.Response{
+			"200": meetingResponse,
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "User is not a participant"),
+			"404": problemResponse(api, "Meeting not found"),
+			"409": problemResponse(api, "Meeting can't be accepted in its state"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, meetingHandler.AcceptMeeting)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "DeclineMeeting",
+		Method:      http.MethodPost,
+		Path:        "/meetings/{id}/decline",
+		Summary:     "decline meeting",
+		Description: "Decline meeting, the decline is recorded for the participant.",
+		Tags:        []string{"Meetings"},
+		Responses: map[string]*huma.Response{
+			"200": meetingResponse,
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "User is not a participant"),
+			"404": problemResponse(api, "Meeting not found"),
+			"409": problemResponse(api, "Meeting can't be declined in its state"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, meetingHandler.DeclineMeeting)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "CompleteMeeting",
+		Method:      http.MethodPost,
+		Path:        "/meetings/{id}/complete",
+		Summary:     "complete meeting",
+		Description: "Confirm that the meeting was held or report participants who didn't come. " +
+			"The meeting counts in matching history only when every participant confirmed it. " +
+			"A participant is a no-show once every other participant reported them, those who confirmed the meeting can't be reported.",
+		Tags: []string{"Meetings"},
+		Responses: map[string]*huma.Response{
+			"200": meetingResponse,
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "User is not a participant"),
+			"404": problemResponse(api, "Meeting not found"),
+			"409": problemResponse(api, "Meeting can't be completed in its state or participant confirmed it"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, meetingHandler.CompleteMeeting)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ScheduleMeeting",
+		Method:      http.MethodPost,
+		Path:        "/meetings/{id}/schedule",
+		Summary:     "schedule meeting",
+		Description: "Pick the time of accepted meeting from its proposed slots, " +
+			"any future time may be picked when participants have no common free time.",
+		Tags: []string{"Meetings"},
+		Responses: map[string]*huma.Response{
+			"200": meetingResponse,
+			"400": problemResponse(api, "Invalid request or time not proposed"),
+			"403": problemResponse(api, "User is not a participant"),
+			"404": problemResponse(api, "Meeting not found"),
+			"409": problemResponse(api, "Meeting can't be scheduled in its state"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, meetingHandler.ScheduleMeeting)
+}
@@ -0,0 +1,76 @@
+package route
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/mentorship"
+	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/danielgtaylor/huma/v2"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+func setupMentorshipRoutes(api huma.API, pg *database.Postgres) {
+	mentorshipOnce, spaceOnce, auditOnce := sync.Once{}, sync.Once{}, sync.Once{}
+	mentorshipUseCase := usecase.NewAuditedMentorshipUseCase(
+		usecase.NewMentorshipUseCase(
+			repository.NewMentorshipRepository(&mentorshipOnce, pg),
+			repository.NewSpaceRepository(&spaceOnce, pg),
+		),
+		repository.NewAuditRepository(&auditOnce, pg),
+	)
+
+	mentorshipHandler := mentorship.NewMentorshipHandler(mentorshipUseCase)
+
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	mentorshipSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.Mentorship{}))
+	mentorshipsSchema := huma.SchemaFromType(registry, reflect.TypeOf(&mentorship.MentorshipsResponse{}))
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetMentorships",
+		Method:      http.MethodGet,
+		Path:        "/spaces/{id}/mentorships",
+		Summary:     "space mentorships",
+		Description: "Get active mentorships of the space, ended ones too if asked.",
+		Tags:        []string{"Mentorships"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Mentorships response",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: mentorshipsSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, mentorshipHandler.GetMentorships)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "EndMentorship",
+		Method:      http.MethodPost,
+		Path:        "/mentorships/{id}/end",
+		Summary:     "end mentorship",
+		Description: "End mentorship on behalf of its mentor, mentee or space admin, the mentee gets a new mentor in the next round.",
+		Tags:        []string{"Mentorships"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Mentorship response",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: mentorshipSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Mentorship not found"),
+			"409": problemResponse(api, "Mentorship already ended"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, mentorshipHandler.EndMentorship)
+}
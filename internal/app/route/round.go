@@ -0,0 +1,173 @@
+package route
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/round"
+	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/danielgtaylor/huma/v2"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+func setupRoundRoutes(api huma.API, pg *database.Postgres) {
+	roundOnce, spaceOnce, mentorshipOnce, auditOnce := sync.Once{}, sync.Once{}, sync.Once{}, sync.Once{}
+	roundUseCase := usecase.NewAuditedRoundUseCase(
+		usecase.NewRoundUseCase(
+			repository.NewRoundRepository(&roundOnce, pg),
+			repository.NewSpaceRepository(&spaceOnce, pg),
+			repository.NewMentorshipRepository(&mentorshipOnce, pg),
+		),
+		repository.NewAuditRepository(&auditOnce, pg),
+	)
+
+	roundHandler := round.NewRoundHandler(roundUseCase)
+
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	roundSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.Round{}))
+	previewSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.RoundPreview{}))
+	replaySchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.RoundReplay{}))
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "CreateRound",
+		Method:        http.MethodPost,
+		Path:          "/spaces/{id}/rounds",
+		Summary:       "run matching round",
+		Description:   "Match active members of the space into proposed meetings using current space settings. In mentoring mode mentees get mentors and every mentorship meets.",
+		Tags:          []string{"Rounds"},
+		DefaultStatus: http.StatusCreated,
+		Responses: map[string]*huma.Response{
+			"201": {
+				Description: "Round created",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: roundSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, roundHandler.CreateRound)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetRound",
+		Method:      http.MethodGet,
+		Path:        "/rounds/{id}",
+		Summary:     "round by id",
+		Description: "Get matching round with its meetings.",
+		Tags:        []string{"Rounds"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Round response",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: roundSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "Round not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, roundHandler.GetRound)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "PreviewRound",
+		Method:      http.MethodPost,
+		Path:        "/spaces/{id}/rounds/preview",
+		Summary:     "preview matching round",
+		Description: "Match the round as it would be run now without sending anything out. The preview lists planned meetings, why members are left out and match quality, and can be published within 24 hours.",
+		Tags:        []string{"Rounds"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Round preview",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: previewSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, roundHandler.PreviewRound)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "OverrideRoundPreview",
+		Method:      http.MethodPost,
+		Path:        "/spaces/{id}/rounds/preview/{previewId}/overrides",
+		Summary:     "override round preview",
+		Description: "Pin a pair so they meet, forbid a pair or swap two members between their meetings. The rest of the round is matched again around all overrides of the preview, each is kept with the admin who made it.",
+		Tags:        []string{"Rounds"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Overridden round preview",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: previewSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Preview not found or expired"),
+			"409": problemResponse(api, "Override conflicts or preview is stale"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, roundHandler.OverridePreview)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "PublishRoundPreview",
+		Method:        http.MethodPost,
+		Path:          "/spaces/{id}/rounds/preview/{previewId}/publish",
+		Summary:       "publish round preview",
+		Description:   "Create the round exactly as previewed. Fails if space settings changed or planned participants are no longer active since the preview.",
+		Tags:          []string{"Rounds"},
+		DefaultStatus: http.StatusCreated,
+		Responses: map[string]*huma.Response{
+			"201": {
+				Description: "Round created",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: roundSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Preview not found or expired"),
+			"409": problemResponse(api, "Preview is stale"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, roundHandler.PublishPreview)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ReplayRound",
+		Method:      http.MethodGet,
+		Path:        "/rounds/{id}/replay",
+		Summary:     "replay round",
+		Description: "Match the round again from its recorded inputs and random seed and diff the result with meetings the round matched, so pairing decisions can be audited.",
+		Tags:        []string{"Rounds"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "Round replay",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: replaySchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Round not found or can't be replayed"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, roundHandler.ReplayRound)
+}
@@ -1,13 +1,25 @@
 package route
 
 import (
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/middleware"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
 	"github.com/Slava02/Involvio/pkg/database"
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humafiber"
 	"github.com/gofiber/fiber/v2"
+	"reflect"
 )
 
-func SetupRoutes(router *fiber.App, pg *database.Postgres) {
+// Middleware is huma middleware that writes responses itself, so it needs the API.
+type Middleware interface {
+	Middleware(api huma.API) func(huma.Context, func(huma.Context))
+}
+
+// SetupRoutes registers API operations. Middlewares run in the given order
+// after the ones every operation needs.
+func SetupRoutes(router *fiber.App, pg *database.Postgres, middlewares ...Middleware) {
+	problem.Install()
+
 	openapiConfig := huma.DefaultConfig("Involvio", "1.0.0")
 	openapiConfig.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
 		"auth": {
@@ -21,8 +33,44 @@ func SetupRoutes(router *fiber.App, pg *database.Postgres) {
 	}
 
 	api := humafiber.New(router, openapiConfig)
+	api.UseMiddleware(middleware.Trace, middleware.RequestContext, middleware.Metrics)
+	for _, m := range middlewares {
+		api.UseMiddleware(m.Middleware(api))
+	}
 
 	setupUserRoutes(api, pg)
 	setupSpaceRoutes(api, pg)
 	setupEventRoutes(api, pg)
+	setupRoundRoutes(api, pg)
+	setupMeetingRoutes(api, pg)
+	setupMentorshipRoutes(api, pg)
+	setupAuditRoutes(api, pg)
+}
+
+// problemResponse documents an error response, all of them are problem.Problem.
+func problemResponse(api huma.API, description string) *huma.Response {
+	schema := api.OpenAPI().Components.Schemas.Schema(reflect.TypeOf(problem.Problem{}), true, "")
+
+	return &huma.Response{
+		Description: description,
+		Content: map[string]*huma.MediaType{
+			"application/problem+json": {
+				Schema: schema,
+			},
+		},
+	}
+}
+
+// mergePatchBody documents merge patch request body of type body. It is listed
+// under application/json too, huma validates bodies against that schema only.
+func mergePatchBody(api huma.API, body any) *huma.RequestBody {
+	schema := api.OpenAPI().Components.Schemas.Schema(reflect.TypeOf(body), true, "")
+
+	return &huma.RequestBody{
+		Required: true,
+		Content: map[string]*huma.MediaType{
+			"application/merge-patch+json": {Schema: schema},
+			"application/json":             {Schema: schema},
+		},
+	}
 }
@@ -12,15 +12,33 @@ import (
 	"sync"
 )
 
+// maxImportBytes is fiber's default body limit, well above a CSV of usecase import row limit.
+const maxImportBytes = 4 << 20
+
 //nolint:funlen
 func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
-	o := sync.Once{}
-	spaceUseCase := usecase.NewSpaceUseCase(repository.NewSpaceRepository(&o, pg))
+	spaceOnce, roundOnce, meetingOnce, auditOnce := sync.Once{}, sync.Once{}, sync.Once{}, sync.Once{}
+	spaceRepo := repository.NewSpaceRepository(&spaceOnce, pg)
+	spaceUseCase := usecase.NewAuditedSpaceUseCase(
+		usecase.NewSpaceUseCase(
+			spaceRepo,
+			usecase.NewRematcher(
+				repository.NewRoundRepository(&roundOnce, pg),
+				repository.NewMeetingRepository(&meetingOnce, pg),
+				spaceRepo,
+			),
+		),
+		repository.NewAuditRepository(&auditOnce, pg),
+	)
 
 	spaceHandler := space.NewSpaceHandler(spaceUseCase)
 
 	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
 	spaceSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.Space{}))
+	joinSchema := huma.SchemaFromType(registry, reflect.TypeOf(&space.JoinSpaceResponse{}))
+	membersSchema := huma.SchemaFromType(registry, reflect.TypeOf(&space.MembersResponse{}))
+	importSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.MemberImport{}))
+	settingsSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.SpaceSettingsVersion{}))
 
 	huma.Register(api, huma.Operation{
 		OperationID:   "CreateSpace",
@@ -46,33 +64,8 @@ func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, spaceHandler.CreateSpace)
 
@@ -92,46 +85,9 @@ func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "ISpaceUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, spaceHandler.GetSpace)
 
@@ -148,32 +104,8 @@ func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
 				Description: "ISpaceUC deleted",
 				Content:     map[string]*huma.MediaType{},
 			},
-			"404": {
-				Description: "ISpaceUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, spaceHandler.DeleteSpace)
 
@@ -193,48 +125,38 @@ func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IUserUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"412": problemResponse(api, "Object was modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.UpdateSpace)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "PatchSpace",
+		Method:      http.MethodPatch,
+		Path:        "/spaces/{id}",
+		Summary:     "patch space",
+		Description: "Change space name, description or tags with JSON merge patch (RFC 7396). An object in tags sets or, with null, removes single tags by name.",
+		Tags:        []string{"Spaces"},
+		RequestBody: mergePatchBody(api, space.SpacePatch{}),
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "ISpaceUC patched",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
+						Schema: spaceSchema,
 					},
 				},
 			},
+			"400": problemResponse(api, "Invalid request or patch"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"412": problemResponse(api, "Object was modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
 		},
-	}, spaceHandler.UpdateSpace)
+	}, spaceHandler.PatchSpace)
 
 	huma.Register(api, huma.Operation{
 		OperationID:   "JoinSpace",
@@ -246,8 +168,12 @@ func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
 		DefaultStatus: http.StatusCreated,
 		Responses: map[string]*huma.Response{
 			"201": {
-				Description: "joined ISpaceUC",
-				Content:     map[string]*huma.MediaType{},
+				Description: "joined ISpaceUC, membership is pending if space requires approval",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: joinSchema,
+					},
+				},
 				Headers: map[string]*huma.Param{
 					"Location": {
 						Description: "URL of the space that user joined",
@@ -256,33 +182,249 @@ func setupSpaceRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
+			"400": problemResponse(api, "Invalid request"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.JoinSpace)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetSpaceSettings",
+		Method:      http.MethodGet,
+		Path:        "/spaces/{id}/settings",
+		Summary:     "space settings",
+		Description: "Get current space settings or a given version of them.",
+		Tags:        []string{"Spaces"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "ISpaceUC settings",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
+						Schema: settingsSchema,
 					},
 				},
 			},
-			"500": {
-				Description: "Internal server error",
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "ISpaceUC or settings version not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.GetSettings)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "UpdateSpaceSettings",
+		Method:      http.MethodPut,
+		Path:        "/spaces/{id}/settings",
+		Summary:     "update space settings",
+		Description: "Save space settings as a new version, previous versions are kept for rounds run with them.",
+		Tags:        []string{"Spaces"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "ISpaceUC settings updated",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
+						Schema: settingsSchema,
 					},
 				},
 			},
+			"400": problemResponse(api, "Invalid request or settings"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"412": problemResponse(api, "Settings were modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
 		},
-	}, spaceHandler.JoinSpace)
+	}, spaceHandler.UpdateSettings)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "GetSpaceMembers",
+		Method:      http.MethodGet,
+		Path:        "/spaces/{id}/members",
+		Summary:     "space members",
+		Description: "List space members with their role and form tags.",
+		Tags:        []string{"Spaces"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "ISpaceUC members",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: membersSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.GetMembers)
+
+	huma.Register(api, huma.Operation{
+		OperationID:  "ImportSpaceMembers",
+		Method:       http.MethodPost,
+		Path:         "/spaces/{id}/members/import",
+		Summary:      "import members",
+		Description:  "Add members from CSV with a header row. Rows with id add existing users, rows without id create users. Dry run reports errors of every row, commit adds all rows in one transaction or nothing if any row is invalid.",
+		Tags:         []string{"Spaces"},
+		MaxBodyBytes: maxImportBytes,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "import report",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: importSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request, mapping or CSV header"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"409": problemResponse(api, "Member was added concurrently"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.ImportMembers)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "ExportSpaceMembers",
+		Method:      http.MethodGet,
+		Path:        "/spaces/{id}/members/export",
+		Summary:     "export members",
+		Description: "Stream space members as CSV in the format import reads.",
+		Tags:        []string{"Spaces"},
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "members CSV",
+				Content: map[string]*huma.MediaType{
+					"text/csv": {
+						Schema: &huma.Schema{Type: "string"},
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.ExportMembers)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "KickSpaceMember",
+		Method:        http.MethodDelete,
+		Path:          "/spaces/{id}/members/{userId}",
+		Summary:       "kick member",
+		Description:   "Remove member from space, member may join again.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusNoContent,
+		Responses: map[string]*huma.Response{
+			"204": {
+				Description: "member kicked",
+				Content:     map[string]*huma.MediaType{},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.KickMember)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "BanSpaceMember",
+		Method:        http.MethodPost,
+		Path:          "/spaces/{id}/members/{userId}/ban",
+		Summary:       "ban member",
+		Description:   "Ban member in space with a reason, banned user can't join again.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusNoContent,
+		Responses: map[string]*huma.Response{
+			"204": {
+				Description: "member banned",
+				Content:     map[string]*huma.MediaType{},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.BanMember)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "SetMemberMatchRole",
+		Method:        http.MethodPut,
+		Path:          "/spaces/{id}/members/{userId}/match-role",
+		Summary:       "set member match role",
+		Description:   "Set mentor or mentee role of the member for mentoring mode, mentors may get their own capacity.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusNoContent,
+		Responses: map[string]*huma.Response{
+			"204": {
+				Description: "match role set",
+				Content:     map[string]*huma.MediaType{},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.SetMatchRole)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "ApproveSpaceMember",
+		Method:        http.MethodPost,
+		Path:          "/spaces/{id}/members/{userId}/approve",
+		Summary:       "approve member",
+		Description:   "Approve pending join request.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusNoContent,
+		Responses: map[string]*huma.Response{
+			"204": {
+				Description: "member approved",
+				Content:     map[string]*huma.MediaType{},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "ISpaceUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.ApproveMember)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "RestoreSpace",
+		Method:        http.MethodPost,
+		Path:          "/spaces/{id}/restore",
+		Summary:       "restore space",
+		Description:   "Restore deleted space with memberships and events deleted together with it.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusOK,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "ISpaceUC restored",
+				Content: map[string]*huma.MediaType{
+					"application/json": {
+						Schema: spaceSchema,
+					},
+				},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Deleted space not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.RestoreSpace)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "RestoreSpaceMember",
+		Method:        http.MethodPost,
+		Path:          "/spaces/{id}/members/{userId}/restore",
+		Summary:       "restore member",
+		Description:   "Restore membership removed by kick or by leaving the space.",
+		Tags:          []string{"Spaces"},
+		DefaultStatus: http.StatusNoContent,
+		Responses: map[string]*huma.Response{
+			"204": {
+				Description: "member restored",
+				Content:     map[string]*huma.MediaType{},
+			},
+			"400": problemResponse(api, "Invalid request"),
+			"403": problemResponse(api, "Not allowed"),
+			"404": problemResponse(api, "Deleted member not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, spaceHandler.RestoreMember)
 }
@@ -15,8 +15,11 @@ import (
 //nolint:funlen
 func setupUserRoutes(api huma.API, pg *database.Postgres) {
 	// Initialize use cases
-	o := sync.Once{}
-	userUseCase := usecase.NewUserUseCase(repository.NewUserRepository(&o, pg))
+	userOnce, auditOnce := sync.Once{}, sync.Once{}
+	userUseCase := usecase.NewAuditedUserUseCase(
+		usecase.NewUserUseCase(repository.NewUserRepository(&userOnce, pg)),
+		repository.NewAuditRepository(&auditOnce, pg),
+	)
 
 	// Initialize handlers
 	userHandler := user.NewUserHandler(userUseCase)
@@ -26,6 +29,7 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 	userSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.User{}))
 	formSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.Form{}))
 	userWithFormsSchema := huma.SchemaFromType(registry, reflect.TypeOf(&user.UserWithFormsResponse{}))
+	userExportSchema := huma.SchemaFromType(registry, reflect.TypeOf(&entity.UserExport{}))
 
 	huma.Register(api, huma.Operation{
 		OperationID:   "CreateUser",
@@ -51,33 +55,8 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, userHandler.CreateUser)
 
@@ -97,46 +76,9 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IUserUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, userHandler.GetUserWithForms)
 
@@ -156,48 +98,79 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"412": problemResponse(api, "Object was modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, userHandler.UpdateUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "PatchUser",
+		Method:      http.MethodPatch,
+		Path:        "/users/{id}",
+		Summary:     "patch user",
+		Description: "Change user profile fields with JSON merge patch (RFC 7396), null clears a field.",
+		Tags:        []string{"Users"},
+		RequestBody: mergePatchBody(api, user.UserPatch{}),
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "IUserUC patched",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
+						Schema: userSchema,
 					},
 				},
 			},
-			"404": {
-				Description: "IUserUC not found",
+			"400": problemResponse(api, "Invalid request or patch"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"412": problemResponse(api, "Object was modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, userHandler.PatchUser)
+
+	// must be registered before GET /users/{userId}/{spaceId}, fiber matches routes in order
+	huma.Register(api, huma.Operation{
+		OperationID:   "ExportUser",
+		Method:        http.MethodGet,
+		Path:          "/users/{id}/export",
+		Summary:       "export user data",
+		Description:   "Return a JSON archive of everything stored about the user.",
+		Tags:          []string{"Users"},
+		DefaultStatus: http.StatusOK,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "IUserUC data archive",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
+						Schema: userExportSchema,
 					},
 				},
 			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
+			"404": problemResponse(api, "IUserUC not found"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, userHandler.ExportUser)
+
+	huma.Register(api, huma.Operation{
+		OperationID:   "EraseUser",
+		Method:        http.MethodDelete,
+		Path:          "/users/{id}",
+		Summary:       "erase user",
+		Description:   "Permanently remove the user, their memberships and event participation.",
+		Tags:          []string{"Users"},
+		DefaultStatus: http.StatusNoContent,
+		Responses: map[string]*huma.Response{
+			"204": {
+				Description: "IUserUC erased",
+				Content:     map[string]*huma.MediaType{},
 			},
+			"404": problemResponse(api, "IUserUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
-	}, userHandler.UpdateUser)
+	}, userHandler.EraseUser)
 
 	huma.Register(api, huma.Operation{
 		OperationID:   "DeleteUserForm",
@@ -212,32 +185,8 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 				Description: "IUserUC deleted",
 				Content:     map[string]*huma.MediaType{},
 			},
-			"404": {
-				Description: "IUserUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"404": problemResponse(api, "IUserUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, userHandler.DeleteUser)
 
@@ -257,46 +206,9 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IUserUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"500": problemResponse(api, "Internal server error"),
 		},
 	}, userHandler.GetForm)
 
@@ -316,46 +228,36 @@ func setupUserRoutes(api huma.API, pg *database.Postgres) {
 					},
 				},
 			},
-			"400": {
-				Description: "Invalid request",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"message": {Type: "string"},
-								"field":   {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"404": {
-				Description: "IUserUC not found",
-				Content: map[string]*huma.MediaType{
-					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
+			"400": problemResponse(api, "Invalid request"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"412": problemResponse(api, "Object was modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
+		},
+	}, userHandler.UpdateForm)
+
+	huma.Register(api, huma.Operation{
+		OperationID: "PatchUserForm",
+		Method:      http.MethodPatch,
+		Path:        "/users/{userId}/{spaceId}",
+		Summary:     "patch user form in space",
+		Description: "Change form tags with JSON merge patch (RFC 7396). An object in user_tags or pair_tags sets or, with null, removes single tags by name.",
+		Tags:        []string{"Users"},
+		RequestBody: mergePatchBody(api, user.FormPatch{}),
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "IUserUC response",
 				Content: map[string]*huma.MediaType{
 					"application/json": {
-						Schema: &huma.Schema{
-							Type: "object",
-							Properties: map[string]*huma.Schema{
-								"error": {Type: "string"},
-							},
-						},
+						Schema: userWithFormsSchema,
 					},
 				},
 			},
+			"400": problemResponse(api, "Invalid request or patch"),
+			"404": problemResponse(api, "IUserUC not found"),
+			"412": problemResponse(api, "Object was modified since the ETag from If-Match"),
+			"428": problemResponse(api, "If-Match is required"),
+			"500": problemResponse(api, "Internal server error"),
 		},
-	}, userHandler.UpdateForm)
+	}, userHandler.PatchForm)
 }
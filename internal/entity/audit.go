@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+type AuditTarget string
+
+const (
+	AuditTargetSpace      AuditTarget = "space"
+	AuditTargetMember     AuditTarget = "member"
+	AuditTargetForm       AuditTarget = "form"
+	AuditTargetEvent      AuditTarget = "event"
+	AuditTargetRound      AuditTarget = "round"
+	AuditTargetMeeting    AuditTarget = "meeting"
+	AuditTargetMentorship AuditTarget = "mentorship"
+)
+
+// AuditEntry is a record of a single mutating call.
+type AuditEntry struct {
+	ID         int64                  `doc:"Entry ID" json:"id" example:"1"`
+	SpaceID    int                    `doc:"Space the action belongs to" json:"space_id" example:"1234"`
+	ActorID    *int                   `doc:"User who performed the action, empty if unknown" json:"actor_id,omitempty" example:"1234"`
+	Action     string                 `doc:"Usecase that was called" json:"action" example:"UpdateSpace"`
+	TargetType AuditTarget            `doc:"Kind of changed object" json:"target_type" enum:"space,member,form,event,round,meeting,mentorship" example:"space"`
+	TargetID   int                    `doc:"ID of changed object" json:"target_id" example:"1234"`
+	Diff       map[string]FieldChange `doc:"Changed fields" json:"diff"`
+	RequestID  string                 `doc:"ID of the HTTP request" json:"request_id,omitempty" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+	CreatedAt  time.Time              `doc:"When action was performed" json:"created_at"`
+}
+
+// FieldChange holds old and new value of a field, nil when field was absent.
+type FieldChange struct {
+	Old any `doc:"Value before the action" json:"old"`
+	New any `doc:"Value after the action" json:"new"`
+}
+
+// MemberAudit is what the audit log keeps of a membership. The log is
+// append-only, so tags, availability and ban reason of members stay out of it.
+type MemberAudit struct {
+	UserID    int          `json:"user_id"`
+	Role      MemberRole   `json:"role"`
+	Status    MemberStatus `json:"status,omitempty"`
+	MatchRole string       `json:"match_role,omitempty"`
+	Version   int          `json:"version,omitempty"`
+}
+
+// MemberAuditOf keeps what the audit log may record of the member.
+func MemberAuditOf(m *Member) MemberAudit {
+	audit := MemberAudit{Role: m.Role, Status: m.Status, MatchRole: m.MatchRole}
+	if m.User != nil {
+		audit.UserID = m.User.ID
+	}
+	return audit
+}
+
+// FormAuditOf keeps what the audit log may record of the user's form.
+func FormAuditOf(userID int, f *Form) MemberAudit {
+	return MemberAudit{UserID: userID, Role: MemberRoleOf(f.Admin, f.Creator), Version: f.Version}
+}
+
+// AuditFilter selects audit entries of a space.
+type AuditFilter struct {
+	SpaceID    int
+	ActorID    int
+	Action     string
+	TargetType AuditTarget
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// Diff compares JSON representations of before and after field by field.
+// Either of them may be nil, e.g. on creation or deletion.
+func Diff(before, after any) (map[string]FieldChange, error) {
+	old, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]FieldChange)
+
+	for k, v := range old {
+		if nv, ok := cur[k]; !ok || !reflect.DeepEqual(v, nv) {
+			diff[k] = FieldChange{Old: v, New: nv}
+		}
+	}
+
+	for k, v := range cur {
+		if _, ok := old[k]; !ok {
+			diff[k] = FieldChange{New: v}
+		}
+	}
+
+	return diff, nil
+}
+
+func toFields(v any) (map[string]any, error) {
+	fields := make(map[string]any)
+
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := &Space{ID: 1, Name: "MAI", Description: "university", Timezone: "UTC"}
+	after := &Space{ID: 1, Name: "MAI", Description: "best university", Timezone: "UTC"}
+
+	diff, err := Diff(before, after)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]FieldChange{
+		"description": {Old: "university", New: "best university"},
+	}, diff)
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	space := &Space{ID: 1, Name: "MAI"}
+
+	created, err := Diff(nil, space)
+	require.NoError(t, err)
+	assert.Equal(t, FieldChange{New: "MAI"}, created["name"])
+
+	var none *Space
+	deleted, err := Diff(space, none)
+	require.NoError(t, err)
+	assert.Equal(t, FieldChange{Old: "MAI"}, deleted["name"])
+}
+
+func TestMemberAuditDiff(t *testing.T) {
+	before := &Member{User: &User{ID: 7, FirstName: "Ann"}, Role: RoleMember, Status: StatusActive,
+		UserTags: Tags{{"city": "Kazan"}}, Availability: &Availability{}}
+	after := *before
+	after.Status, after.BanReason = StatusBanned, "spam"
+
+	diff, err := Diff(MemberAuditOf(before), MemberAuditOf(&after))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FieldChange{"status": {Old: "active", New: "banned"}}, diff)
+
+	state, err := Diff(nil, FormAuditOf(7, &Form{SpaceID: 1, Admin: true, UserTags: Tags{{"city": "Kazan"}}, Version: 3}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FieldChange{"user_id": {New: float64(7)}, "role": {New: "admin"}, "version": {New: float64(3)}}, state)
+}
@@ -0,0 +1,242 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/Slava02/Involvio/internal/errs"
+	"sort"
+	"time"
+)
+
+const (
+	// MeetingDuration is the shortest common free time a slot is proposed for.
+	MeetingDuration = 30 * time.Minute
+	// AvailabilityHorizon is how far ahead meeting slots are proposed.
+	AvailabilityHorizon = 7 * 24 * time.Hour
+	// ProposedSlots is the number of meeting slots proposed for a meeting.
+	ProposedSlots = 3
+	// MaxAvailabilityRanges bounds ranges in a weekly availability.
+	MaxAvailabilityRanges = 50
+)
+
+var ErrInvalidAvailability = errs.New(errs.Invalid, "availability.invalid", "invalid availability")
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// TimeRange is a range of local time on a weekday, "24:00" ends it at midnight.
+type TimeRange struct {
+	Weekday string `doc:"Day of week" json:"weekday" enum:"monday,tuesday,wednesday,thursday,friday,saturday,sunday" example:"monday"`
+	Start   string `doc:"Local start time" json:"start" pattern:"^([01][0-9]|2[0-3]):[0-5][0-9]$" example:"18:00"`
+	End     string `doc:"Local end time" json:"end" pattern:"^(([01][0-9]|2[0-3]):[0-5][0-9]|24:00)$" example:"20:30"`
+}
+
+// Availability is a weekly pattern of when a member can meet, in their timezone.
+type Availability struct {
+	Timezone string      `doc:"IANA timezone of the ranges" json:"timezone" example:"Europe/Moscow"`
+	Ranges   []TimeRange `doc:"Weekly time ranges" json:"ranges" maxItems:"50"`
+}
+
+// Interval is a concrete period of time.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Validate returns ErrInvalidAvailability or ErrInvalidTimezone wrapped with the reason.
+func (a *Availability) Validate() error {
+	if _, err := LoadLocation(a.Timezone); err != nil {
+		return err
+	}
+
+	if len(a.Ranges) > MaxAvailabilityRanges {
+		return fmt.Errorf("%w: at most %d ranges", ErrInvalidAvailability, MaxAvailabilityRanges)
+	}
+
+	for _, r := range a.Ranges {
+		if _, ok := weekdays[r.Weekday]; !ok {
+			return fmt.Errorf("%w: unknown weekday %q", ErrInvalidAvailability, r.Weekday)
+		}
+
+		start, err := clock(r.Start)
+		if err != nil {
+			return err
+		}
+		end, err := clock(r.End)
+		if err != nil {
+			return err
+		}
+
+		if start >= end {
+			return fmt.Errorf("%w: range %s-%s ends before it starts", ErrInvalidAvailability, r.Start, r.End)
+		}
+	}
+
+	return nil
+}
+
+// Intervals returns when the member is available within [from, to), sorted
+// and merged. Ranges follow the member's timezone, daylight saving included.
+func (a *Availability) Intervals(from, to time.Time) []Interval {
+	loc, err := LoadLocation(a.Timezone)
+	if err != nil {
+		return nil
+	}
+
+	intervals := make([]Interval, 0)
+
+	first := from.In(loc)
+	last := to.In(loc)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); !day.After(last); day = day.AddDate(0, 0, 1) {
+		for _, r := range a.Ranges {
+			if weekdays[r.Weekday] != day.Weekday() {
+				continue
+			}
+
+			start, err := clock(r.Start)
+			if err != nil {
+				continue
+			}
+			end, err := clock(r.End)
+			if err != nil {
+				continue
+			}
+
+			i := Interval{
+				Start: time.Date(day.Year(), day.Month(), day.Day(), 0, start, 0, 0, loc),
+				End:   time.Date(day.Year(), day.Month(), day.Day(), 0, end, 0, 0, loc),
+			}
+			if i.Start.Before(from) {
+				i.Start = from
+			}
+			if i.End.After(to) {
+				i.End = to
+			}
+
+			if i.Start.Before(i.End) {
+				intervals = append(intervals, i)
+			}
+		}
+	}
+
+	return merge(intervals)
+}
+
+// ProposeSlots returns up to n times within AvailabilityHorizon after from
+// when everyone is available for at least MeetingDuration, longer common
+// free time first. Members without availability don't restrict the slots,
+// nothing is proposed if nobody has it.
+func ProposeSlots(availability []*Availability, from time.Time, n int) []time.Time {
+	to := from.Add(AvailabilityHorizon)
+
+	var common []Interval
+	known := false
+
+	for _, a := range availability {
+		if a == nil {
+			continue
+		}
+
+		intervals := a.Intervals(from, to)
+		if !known {
+			common, known = intervals, true
+			continue
+		}
+		common = intersect(common, intervals)
+	}
+
+	long := make([]Interval, 0, len(common))
+	for _, i := range common {
+		if i.End.Sub(i.Start) >= MeetingDuration {
+			long = append(long, i)
+		}
+	}
+
+	sort.SliceStable(long, func(i, j int) bool {
+		return long[i].End.Sub(long[i].Start) > long[j].End.Sub(long[j].Start)
+	})
+
+	slots := make([]time.Time, 0, n)
+	for _, i := range long {
+		if len(slots) == n {
+			break
+		}
+		slots = append(slots, i.Start.UTC())
+	}
+
+	return slots
+}
+
+// Overlaps reports whether intervals a and b have common time for a meeting.
+func Overlaps(a, b []Interval) bool {
+	for _, i := range intersect(a, b) {
+		if i.End.Sub(i.Start) >= MeetingDuration {
+			return true
+		}
+	}
+	return false
+}
+
+// clock parses "hh:mm" into minutes since midnight.
+func clock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%2d:%2d", &h, &m); err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("%w: malformed time %q", ErrInvalidAvailability, s)
+	}
+
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%w: malformed time %q", ErrInvalidAvailability, s)
+	}
+
+	return h*60 + m, nil
+}
+
+func merge(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	merged := make([]Interval, 0, len(intervals))
+	for _, i := range intervals {
+		if n := len(merged); n > 0 && !i.Start.After(merged[n-1].End) {
+			if i.End.After(merged[n-1].End) {
+				merged[n-1].End = i.End
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+
+	return merged
+}
+
+// intersect returns common parts of two sorted lists of disjoint intervals.
+func intersect(a, b []Interval) []Interval {
+	common := make([]Interval, 0)
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+
+		if start.Before(end) {
+			common = append(common, Interval{Start: start, End: end})
+		}
+
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return common
+}
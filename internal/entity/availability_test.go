@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailabilityValidate(t *testing.T) {
+	valid := &Availability{Timezone: "Europe/Moscow", Ranges: []TimeRange{{"monday", "18:00", "24:00"}}}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name string
+		a    *Availability
+		want error
+	}{
+		{"unknown timezone", &Availability{Timezone: "Mars/Olympus"}, ErrInvalidTimezone},
+		{"unknown weekday", &Availability{Ranges: []TimeRange{{"someday", "10:00", "11:00"}}}, ErrInvalidAvailability},
+		{"malformed time", &Availability{Ranges: []TimeRange{{"monday", "9:00", "11:00"}}}, ErrInvalidAvailability},
+		{"after midnight", &Availability{Ranges: []TimeRange{{"monday", "10:00", "24:30"}}}, ErrInvalidAvailability},
+		{"empty range", &Availability{Ranges: []TimeRange{{"monday", "11:00", "11:00"}}}, ErrInvalidAvailability},
+		{"reversed range", &Availability{Ranges: []TimeRange{{"monday", "22:00", "02:00"}}}, ErrInvalidAvailability},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.a.Validate(), tt.want)
+		})
+	}
+}
+
+func TestProposeSlots(t *testing.T) {
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour, min int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+
+	moscow := &Availability{Timezone: "Europe/Moscow", Ranges: []TimeRange{
+		{"monday", "18:00", "21:00"},
+		{"wednesday", "09:00", "10:00"},
+		{"friday", "12:00", "14:00"},
+	}}
+	utc := &Availability{Timezone: "UTC", Ranges: []TimeRange{
+		{"monday", "16:00", "20:00"},
+		{"wednesday", "06:15", "06:40"},
+		{"friday", "10:00", "11:00"},
+	}}
+	sunday := &Availability{Timezone: "UTC", Ranges: []TimeRange{{"sunday", "10:00", "12:00"}}}
+
+	assert.Equal(t, []time.Time{at(0, 15, 0), at(4, 9, 0), at(2, 6, 0)}, ProposeSlots([]*Availability{moscow}, monday, 3))
+	assert.Equal(t, []time.Time{at(0, 16, 0), at(4, 10, 0)}, ProposeSlots([]*Availability{moscow, nil, utc}, monday, 3))
+	assert.Equal(t, []time.Time{at(0, 16, 0)}, ProposeSlots([]*Availability{moscow, utc}, monday, 1))
+	assert.Equal(t, []time.Time{at(7, 16, 0), at(4, 10, 0), at(0, 17, 30)}, ProposeSlots([]*Availability{moscow, utc}, at(0, 17, 30), 3))
+	assert.Empty(t, ProposeSlots([]*Availability{moscow, sunday}, monday, 3))
+	assert.Empty(t, ProposeSlots([]*Availability{nil, nil}, monday, 3))
+
+	to := monday.Add(AvailabilityHorizon)
+	assert.True(t, Overlaps(moscow.Intervals(monday, to), utc.Intervals(monday, to)))
+	assert.False(t, Overlaps(moscow.Intervals(monday, to), sunday.Intervals(monday, to)))
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// MaxConstraints bounds constraint rules of a space.
+const MaxConstraints = 20
+
+type ConstraintKind string
+
+const (
+	// ConstraintNeverEqual never matches members with equal values of the tag.
+	ConstraintNeverEqual ConstraintKind = "never_equal"
+	// ConstraintPreferDifferent makes members with equal values of the tag a
+	// worse match.
+	ConstraintPreferDifferent ConstraintKind = "prefer_different"
+	// ConstraintNeverID never matches a member with the one whose ID is the
+	// value of the tag, e.g. with their manager.
+	ConstraintNeverID ConstraintKind = "never_id"
+)
+
+// ConstraintRule keeps members apart by one of their user tags, e.g. so that
+// colleagues from one department meet outside of it.
+type ConstraintRule struct {
+	Kind ConstraintKind `doc:"never_equal and never_id are never broken, prefer_different is avoided where possible" json:"kind" enum:"never_equal,prefer_different,never_id" example:"never_equal"`
+	Tag  string         `doc:"User tag the rule compares" json:"tag" minLength:"1" maxLength:"100" example:"department"`
+}
+
+// Hard reports whether the rule must never be broken.
+func (r ConstraintRule) Hard() bool {
+	return r.Kind != ConstraintPreferDifferent
+}
+
+// Validate returns ErrInvalidSettings wrapped with the reason.
+func (r ConstraintRule) Validate() error {
+	switch r.Kind {
+	case ConstraintNeverEqual, ConstraintPreferDifferent, ConstraintNeverID:
+	default:
+		return fmt.Errorf("%w: constraint kind %v", ErrInvalidSettings, r.Kind)
+	}
+
+	if r.Tag == "" || len(r.Tag) > 100 {
+		return fmt.Errorf("%w: constraint tag %q", ErrInvalidSettings, r.Tag)
+	}
+
+	return nil
+}
+
+// Breaks reports whether members a and b meeting breaks the rule. Members
+// without the tag never do.
+func (r ConstraintRule) Breaks(a, b *Member) bool {
+	if r.Kind == ConstraintNeverID {
+		return r.points(a, b) || r.points(b, a)
+	}
+
+	x, ok := a.UserTags.Get(r.Tag)
+	if !ok {
+		return false
+	}
+	y, ok := b.UserTags.Get(r.Tag)
+
+	return ok && reflect.DeepEqual(x, y)
+}
+
+// points reports whether the tag of a is the ID of b.
+func (r ConstraintRule) points(a, b *Member) bool {
+	value, ok := a.UserTags.Get(r.Tag)
+	if !ok {
+		return false
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v == float64(b.User.ID)
+	case string:
+		return v == strconv.Itoa(b.User.ID)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintRuleBreaks(t *testing.T) {
+	member := func(id int, tags Tags) *Member {
+		return &Member{User: &User{ID: id}, UserTags: tags}
+	}
+
+	sales := member(1, Tags{{"department": "sales"}, {"manager": float64(3)}})
+	alsoSales := member(2, Tags{{"department": "sales"}, {"manager": "3"}})
+	boss := member(3, Tags{{"department": "board"}})
+	newcomer := member(4, nil)
+
+	equal := ConstraintRule{Kind: ConstraintNeverEqual, Tag: "department"}
+	assert.True(t, equal.Breaks(sales, alsoSales))
+	assert.False(t, equal.Breaks(sales, boss))
+	assert.False(t, equal.Breaks(sales, newcomer))
+	assert.True(t, equal.Hard())
+
+	manager := ConstraintRule{Kind: ConstraintNeverID, Tag: "manager"}
+	assert.True(t, manager.Breaks(sales, boss))
+	assert.True(t, manager.Breaks(boss, alsoSales))
+	assert.False(t, manager.Breaks(sales, alsoSales))
+
+	assert.False(t, ConstraintRule{Kind: ConstraintPreferDifferent, Tag: "city"}.Hard())
+}
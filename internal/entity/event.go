@@ -10,5 +10,20 @@ type Event struct {
 	Description string    `json:"description"`
 	BeginDate   time.Time `json:"begin_date"`
 	EndDate     time.Time `json:"end_date"`
+	Timezone    string    `json:"timezone" example:"Europe/Moscow"`
 	Tags        Tags      `json:"tags"`
 }
+
+// Localize renders event dates in the event's timezone, so they are
+// serialized with the offset that was in effect at that moment.
+func (e *Event) Localize() error {
+	loc, err := LoadLocation(e.Timezone)
+	if err != nil {
+		return err
+	}
+
+	e.BeginDate = e.BeginDate.In(loc)
+	e.EndDate = e.EndDate.In(loc)
+
+	return nil
+}
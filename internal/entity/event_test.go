@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLocalizeAcrossDST(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezone  string
+		begin     time.Time
+		end       time.Time
+		wantBegin string
+		wantEnd   string
+	}{
+		{
+			// Europe/Berlin switches from CET to CEST at 01:00 UTC on 2024-03-31
+			name:      "spring forward",
+			timezone:  "Europe/Berlin",
+			begin:     time.Date(2024, 3, 31, 0, 30, 0, 0, time.UTC),
+			end:       time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC),
+			wantBegin: "2024-03-31T01:30:00+01:00",
+			wantEnd:   "2024-03-31T03:30:00+02:00",
+		},
+		{
+			// America/New_York switches from EDT to EST at 06:00 UTC on 2024-11-03
+			name:      "fall back",
+			timezone:  "America/New_York",
+			begin:     time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+			end:       time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC),
+			wantBegin: "2024-11-03T01:30:00-04:00",
+			wantEnd:   "2024-11-03T01:30:00-05:00",
+		},
+		{
+			name:      "no DST",
+			timezone:  "Europe/Moscow",
+			begin:     time.Date(2024, 3, 31, 0, 30, 0, 0, time.UTC),
+			end:       time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC),
+			wantBegin: "2024-03-31T03:30:00+03:00",
+			wantEnd:   "2024-11-03T09:30:00+03:00",
+		},
+		{
+			name:      "default timezone",
+			timezone:  "",
+			begin:     time.Date(2024, 3, 31, 3, 30, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			end:       time.Date(2024, 3, 31, 4, 30, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			wantBegin: "2024-03-31T00:30:00Z",
+			wantEnd:   "2024-03-31T01:30:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &Event{Timezone: tt.timezone, BeginDate: tt.begin, EndDate: tt.end}
+
+			require.NoError(t, event.Localize())
+
+			assert.Equal(t, tt.wantBegin, event.BeginDate.Format(time.RFC3339))
+			assert.Equal(t, tt.wantEnd, event.EndDate.Format(time.RFC3339))
+			// the instant itself must never move
+			assert.True(t, tt.begin.Equal(event.BeginDate))
+			assert.True(t, tt.end.Equal(event.EndDate))
+		})
+	}
+}
+
+func TestLoadLocationInvalid(t *testing.T) {
+	for _, tz := range []string{"Local", "Mars/Olympus", "+03:00"} {
+		_, err := LoadLocation(tz)
+		assert.ErrorIs(t, err, ErrInvalidTimezone, tz)
+	}
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// UserExport is everything stored about a user, returned on data access request.
+type UserExport struct {
+	User        *User                   `doc:"User profile" json:"user"`
+	Memberships []*Membership           `doc:"Spaces user belongs or belonged to" json:"memberships"`
+	Forms       []*Form                 `doc:"User forms in active spaces" json:"forms"`
+	Events      []*Participation        `doc:"Events user participates or participated in" json:"events"`
+	Meetings    []*MeetingParticipation `doc:"Meetings user was matched in" json:"meetings"`
+	ExportedAt  time.Time               `doc:"When archive was created" json:"exported_at"`
+}
+
+// Membership is a user_space row as seen by the user.
+type Membership struct {
+	SpaceID   int          `doc:"Space ID" json:"space_id" example:"1234"`
+	SpaceName string       `doc:"Space name" json:"space_name" example:"MAI"`
+	Role      MemberRole   `doc:"Member role in space" json:"role" enum:"creator,admin,member" example:"member"`
+	Status    MemberStatus `doc:"Membership status" json:"status" enum:"active,pending,banned" example:"active"`
+	BanReason string       `doc:"Why member was banned" json:"ban_reason,omitempty" example:"spam"`
+	JoinedAt  time.Time    `doc:"When user joined space" json:"joined_at"`
+	DeletedAt *time.Time   `doc:"When membership was deleted, kept until retention purge" json:"deleted_at,omitempty"`
+}
+
+// Participation is a user_event row as seen by the user.
+type Participation struct {
+	EventID   int        `doc:"Event ID" json:"event_id" example:"1234"`
+	SpaceID   int        `doc:"Space ID" json:"space_id" example:"1234"`
+	Name      string     `doc:"Event name" json:"name" example:"fun event"`
+	BeginDate time.Time  `doc:"Event start date and time" json:"begin_date"`
+	EndDate   time.Time  `doc:"Event end date and time" json:"end_date"`
+	DeletedAt *time.Time `doc:"When participation was deleted, kept until retention purge" json:"deleted_at,omitempty"`
+}
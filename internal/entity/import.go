@@ -0,0 +1,19 @@
+package entity
+
+// MemberImport is a report of a members CSV import. Nothing is written unless
+// Committed is true, and it never is while Errors is not empty.
+type MemberImport struct {
+	DryRun    bool          `doc:"Rows were only validated" json:"dry_run"`
+	Committed bool          `doc:"Members were written to space" json:"committed"`
+	Rows      int           `doc:"Data rows in file" json:"rows" example:"120"`
+	Created   int           `doc:"Users the import creates" json:"created" example:"100"`
+	Joined    int           `doc:"Members the import adds" json:"joined" example:"120"`
+	Errors    []ImportError `doc:"Validation errors of rows" json:"errors"`
+}
+
+// ImportError is a validation error of a single CSV row.
+type ImportError struct {
+	Row     int    `doc:"Row number in file, header is row 1" json:"row" example:"2"`
+	Column  string `doc:"CSV column, if the error is about one" json:"column,omitempty" example:"first_name"`
+	Message string `doc:"What is wrong" json:"message" example:"first_name is required for new users"`
+}
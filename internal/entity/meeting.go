@@ -0,0 +1,377 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/Slava02/Involvio/internal/errs"
+	"slices"
+	"time"
+)
+
+type MeetingState string
+
+const (
+	MeetingProposed  MeetingState = "proposed"
+	MeetingAccepted  MeetingState = "accepted"
+	MeetingDeclined  MeetingState = "declined"
+	MeetingScheduled MeetingState = "scheduled"
+	MeetingHeld      MeetingState = "held"
+	MeetingNoShow    MeetingState = "no_show"
+	MeetingCancelled MeetingState = "cancelled"
+)
+
+// meetingTransitions lists states a meeting may move to, states without
+// entry are final.
+var meetingTransitions = map[MeetingState][]MeetingState{
+	MeetingProposed:  {MeetingAccepted, MeetingDeclined, MeetingCancelled},
+	MeetingAccepted:  {MeetingScheduled, MeetingDeclined, MeetingHeld, MeetingNoShow, MeetingCancelled},
+	MeetingScheduled: {MeetingDeclined, MeetingHeld, MeetingNoShow, MeetingCancelled},
+}
+
+// CanBecome reports whether a meeting in state s may move to state to.
+func (s MeetingState) CanBecome(to MeetingState) bool {
+	for _, next := range meetingTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Final reports whether the meeting can't change anymore.
+func (s MeetingState) Final() bool {
+	return len(meetingTransitions[s]) == 0
+}
+
+type MeetingResponse string
+
+const (
+	ResponsePending  MeetingResponse = "pending"
+	ResponseAccepted MeetingResponse = "accepted"
+	ResponseDeclined MeetingResponse = "declined"
+)
+
+var (
+	ErrNotParticipant    = errs.New(errs.Forbidden, "meeting.not_participant", "user is not a participant of the meeting")
+	ErrInvalidTransition = errs.New(errs.Conflict, "meeting.invalid_transition", "meeting can't do this in its current state")
+	ErrAlreadyResponded  = errs.New(errs.Conflict, "meeting.already_responded", "participant has already responded")
+	ErrSlotNotProposed   = errs.New(errs.Invalid, "meeting.slot_not_proposed", "time is not one of proposed slots")
+	ErrSlotInPast        = errs.New(errs.Invalid, "meeting.slot_in_past", "meeting time has already passed")
+	ErrConfirmedHeld     = errs.New(errs.Conflict, "meeting.confirmed_held", "participant has confirmed the meeting was held")
+)
+
+// Participant is a member's side of a meeting. Responses, held confirmations
+// and no-shows are kept per member, so reliability of members can be reported.
+type Participant struct {
+	UserID      int             `doc:"User ID" json:"user_id" example:"1234"`
+	Response    MeetingResponse `doc:"Answer to the proposal" json:"response" enum:"pending,accepted,declined" example:"accepted"`
+	Held        bool            `doc:"Participant confirmed the meeting was held" json:"held" example:"false"`
+	NoShow      bool            `doc:"Participant didn't come to the meeting" json:"no_show" example:"false"`
+	RespondedAt *time.Time      `doc:"When participant accepted or declined" json:"responded_at,omitempty"`
+	Joined      bool            `doc:"Participant joined the meeting from the rematch pool" json:"joined" example:"false"`
+	ReportedBy  []int           `doc:"Participants who reported this one didn't come" json:"reported_by,omitempty"`
+}
+
+// Meeting is a group of space members matched in a round.
+type Meeting struct {
+	ID           int            `doc:"Meeting ID" json:"id" example:"1234"`
+	RoundID      int            `doc:"Round the meeting was matched in" json:"round_id" example:"12"`
+	SpaceID      int            `doc:"Space ID" json:"space_id" example:"1234"`
+	State        MeetingState   `doc:"Meeting state" json:"state" enum:"proposed,accepted,declined,scheduled,held,no_show,cancelled" example:"proposed"`
+	Participants []*Participant `doc:"Matched members" json:"participants"`
+	// ProposedSlots are empty when participants have no common free time.
+	ProposedSlots []time.Time `doc:"Times everyone is available, best first" json:"proposed_slots"`
+	ScheduledAt   *time.Time  `doc:"Agreed meeting time" json:"scheduled_at,omitempty"`
+	CreatedAt     time.Time   `doc:"When meeting was matched" json:"created_at"`
+	UpdatedAt     time.Time   `doc:"When meeting last changed" json:"updated_at"`
+	Version       int         `doc:"Version, incremented on every change" json:"version" example:"1"`
+}
+
+// Participant returns the participant with the given user ID or ErrNotParticipant.
+func (m *Meeting) Participant(userID int) (*Participant, error) {
+	for _, p := range m.Participants {
+		if p.UserID == userID {
+			return p, nil
+		}
+	}
+	return nil, ErrNotParticipant
+}
+
+// Accept records the participant's acceptance, the meeting is accepted once
+// every participant accepted it. A participant who joined an accepted or
+// scheduled meeting accepts it as it is.
+func (m *Meeting) Accept(userID int, now time.Time) error {
+	p, err := m.Participant(userID)
+	if err != nil {
+		return err
+	}
+
+	if m.State != MeetingProposed && (m.State.Final() || p.Response != ResponsePending) {
+		return m.invalid(MeetingAccepted)
+	}
+	if p.Response != ResponsePending {
+		return ErrAlreadyResponded
+	}
+
+	p.Response, p.RespondedAt = ResponseAccepted, &now
+	if m.State != MeetingProposed {
+		return nil
+	}
+
+	for _, other := range m.Participants {
+		if other.Response != ResponseAccepted {
+			return nil
+		}
+	}
+
+	return m.moveTo(MeetingAccepted)
+}
+
+// Decline records the participant's decline, a single decline declines the
+// meeting. A participant who joined an accepted or scheduled meeting only
+// leaves it, the others meet as agreed.
+func (m *Meeting) Decline(userID int, now time.Time) error {
+	p, err := m.Participant(userID)
+	if err != nil {
+		return err
+	}
+
+	if p.Joined && (m.State == MeetingAccepted || m.State == MeetingScheduled) {
+		if p.Response == ResponseDeclined {
+			return ErrAlreadyResponded
+		}
+
+		p.Response, p.RespondedAt = ResponseDeclined, &now
+		return nil
+	}
+
+	if err = m.moveTo(MeetingDeclined); err != nil {
+		return err
+	}
+
+	p.Response, p.RespondedAt = ResponseDeclined, &now
+
+	return nil
+}
+
+// ConfirmHeld records that the participant met the others, the meeting is
+// held, and counts in history, only once every participant confirmed it.
+func (m *Meeting) ConfirmHeld(userID int) error {
+	p, err := m.attending(userID)
+	if err != nil {
+		return err
+	}
+
+	if !m.State.CanBecome(MeetingHeld) {
+		return m.invalid(MeetingHeld)
+	}
+
+	p.Held = true
+
+	for _, other := range m.Participants {
+		if !other.Held && other.Response != ResponseDeclined {
+			return nil
+		}
+	}
+
+	return m.moveTo(MeetingHeld)
+}
+
+// ReportNoShow records the participant's claim that absent participants,
+// every other one if empty, didn't come. A participant is a no-show, and the
+// meeting becomes no_show, only once every other participant reported them.
+// Participants who confirmed the meeting was held can't be reported.
+func (m *Meeting) ReportNoShow(userID int, absent []int) error {
+	if _, err := m.attending(userID); err != nil {
+		return err
+	}
+
+	if !m.State.CanBecome(MeetingNoShow) {
+		return m.invalid(MeetingNoShow)
+	}
+
+	if len(absent) == 0 {
+		for _, id := range m.Remaining() {
+			if id != userID {
+				absent = append(absent, id)
+			}
+		}
+	}
+
+	missing := make([]*Participant, 0, len(absent))
+	for _, id := range absent {
+		if id == userID {
+			return fmt.Errorf("%w: reporter can't be absent", ErrInvalidTransition)
+		}
+
+		p, err := m.attending(id)
+		if err != nil {
+			return err
+		}
+		if p.Held {
+			return fmt.Errorf("%w: user %d", ErrConfirmedHeld, id)
+		}
+		missing = append(missing, p)
+	}
+
+	for _, p := range missing {
+		if !slices.Contains(p.ReportedBy, userID) {
+			p.ReportedBy = append(p.ReportedBy, userID)
+		}
+	}
+
+	noShows := make([]*Participant, 0, len(missing))
+	for _, p := range m.Participants {
+		if m.reportedByAll(p) {
+			noShows = append(noShows, p)
+		}
+	}
+
+	if len(noShows) == 0 {
+		return nil
+	}
+
+	for _, p := range noShows {
+		p.NoShow = true
+	}
+
+	return m.moveTo(MeetingNoShow)
+}
+
+// reportedByAll reports whether every other participant reported p didn't come.
+func (m *Meeting) reportedByAll(p *Participant) bool {
+	if len(p.ReportedBy) == 0 || p.Response == ResponseDeclined {
+		return false
+	}
+
+	for _, id := range m.Remaining() {
+		if id != p.UserID && !slices.Contains(p.ReportedBy, id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Cancel cancels the meeting, e.g. when a participant was suspended.
+func (m *Meeting) Cancel() error {
+	return m.moveTo(MeetingCancelled)
+}
+
+// Join adds a member left without a partner to the meeting.
+func (m *Meeting) Join(userID int) error {
+	if m.State.Final() {
+		return fmt.Errorf("%w: %s meeting can't be joined", ErrInvalidTransition, m.State)
+	}
+
+	if _, err := m.Participant(userID); err == nil {
+		return fmt.Errorf("%w: user %d already participates", ErrInvalidTransition, userID)
+	}
+
+	m.Participants = append(m.Participants, &Participant{UserID: userID, Response: ResponsePending, Joined: true})
+
+	return nil
+}
+
+// Remaining returns participants who didn't decline the meeting.
+func (m *Meeting) Remaining() []int {
+	ids := make([]int, 0, len(m.Participants))
+	for _, p := range m.Participants {
+		if p.Response != ResponseDeclined {
+			ids = append(ids, p.UserID)
+		}
+	}
+	return ids
+}
+
+// Schedule sets the meeting time picked by the participant from proposed
+// slots, any future time may be picked if none were proposed.
+func (m *Meeting) Schedule(userID int, at, now time.Time) error {
+	if _, err := m.attending(userID); err != nil {
+		return err
+	}
+
+	if !at.After(now) {
+		return ErrSlotInPast
+	}
+
+	if len(m.ProposedSlots) > 0 {
+		proposed := false
+		for _, slot := range m.ProposedSlots {
+			proposed = proposed || slot.Equal(at)
+		}
+
+		if !proposed {
+			return ErrSlotNotProposed
+		}
+	}
+
+	if err := m.moveTo(MeetingScheduled); err != nil {
+		return err
+	}
+
+	at = at.UTC()
+	m.ScheduledAt = &at
+
+	return nil
+}
+
+// attending returns the participant unless they left the meeting they joined.
+func (m *Meeting) attending(userID int) (*Participant, error) {
+	p, err := m.Participant(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Joined && p.Response == ResponseDeclined {
+		return nil, fmt.Errorf("%w: user %d left the meeting", ErrNotParticipant, userID)
+	}
+
+	return p, nil
+}
+
+func (m *Meeting) moveTo(state MeetingState) error {
+	if !m.State.CanBecome(state) {
+		return m.invalid(state)
+	}
+
+	m.State = state
+
+	return nil
+}
+
+func (m *Meeting) invalid(to MeetingState) error {
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, m.State, to)
+}
+
+// Round is a single run of matching in a space.
+type Round struct {
+	ID              int        `doc:"Round ID" json:"id" example:"12"`
+	SpaceID         int        `doc:"Space ID" json:"space_id" example:"1234"`
+	SettingsVersion int        `doc:"Version of space settings the round was run with" json:"settings_version" example:"3"`
+	Seed            int64      `doc:"Random seed the round was matched with, 0 for rounds before seeds were recorded" json:"seed" example:"4242"`
+	Strategy        string     `doc:"Matching strategy of the round" json:"strategy,omitempty" example:"default"`
+	StrategyVersion int        `doc:"Version of the strategy the round was matched with" json:"strategy_version,omitempty" example:"1"`
+	Meetings        []*Meeting `doc:"Meetings matched in the round" json:"meetings"`
+	// Snapshot is saved with a new round and read only for replays.
+	Snapshot *RoundSnapshot `json:"-"`
+	// Unmatched are known only right after matching, they are not stored.
+	Unmatched []int `doc:"Members nobody could be matched with, only returned on creation" json:"unmatched,omitempty"`
+	// NoOverlap are members whose availability has no common time with any other member's.
+	NoOverlap []int `doc:"Members with no common free time with anyone, only returned on creation" json:"no_overlap,omitempty"`
+	// RematchPool are members who lost their partner and weren't matched again yet.
+	RematchPool []int     `doc:"Members waiting for a new partner" json:"rematch_pool"`
+	CreatedAt   time.Time `doc:"When round was run" json:"created_at"`
+}
+
+// MeetingParticipation is a meeting as seen by one of its participants.
+type MeetingParticipation struct {
+	MeetingID   int             `doc:"Meeting ID" json:"meeting_id" example:"1234"`
+	RoundID     int             `doc:"Round ID" json:"round_id" example:"12"`
+	SpaceID     int             `doc:"Space ID" json:"space_id" example:"1234"`
+	State       MeetingState    `doc:"Meeting state" json:"state" example:"held"`
+	Response    MeetingResponse `doc:"Answer to the proposal" json:"response" example:"accepted"`
+	Held        bool            `doc:"Confirmed the meeting was held" json:"held"`
+	NoShow      bool            `doc:"Didn't come to the meeting" json:"no_show"`
+	ScheduledAt *time.Time      `doc:"Agreed meeting time" json:"scheduled_at,omitempty"`
+	CreatedAt   time.Time       `doc:"When meeting was matched" json:"created_at"`
+}
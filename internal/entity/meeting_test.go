@@ -0,0 +1,177 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMeeting(users ...int) *Meeting {
+	m := &Meeting{State: MeetingProposed}
+	for _, id := range users {
+		m.Participants = append(m.Participants, &Participant{UserID: id, Response: ResponsePending})
+	}
+	return m
+}
+
+func TestMeetingAccept(t *testing.T) {
+	now := time.Now()
+	m := newMeeting(1, 2)
+
+	assert.ErrorIs(t, m.Accept(3, now), ErrNotParticipant)
+
+	assert.NoError(t, m.Accept(1, now))
+	assert.Equal(t, MeetingProposed, m.State)
+	assert.ErrorIs(t, m.Accept(1, now), ErrAlreadyResponded)
+
+	assert.NoError(t, m.Accept(2, now))
+	assert.Equal(t, MeetingAccepted, m.State)
+
+	assert.ErrorIs(t, m.Accept(2, now), ErrInvalidTransition)
+}
+
+func TestMeetingDecline(t *testing.T) {
+	now := time.Now()
+	m := newMeeting(1, 2)
+
+	assert.NoError(t, m.Accept(1, now))
+	assert.NoError(t, m.Decline(2, now))
+	assert.Equal(t, MeetingDeclined, m.State)
+	assert.Equal(t, ResponseDeclined, m.Participants[1].Response)
+
+	assert.True(t, m.State.Final())
+	assert.ErrorIs(t, m.Decline(1, now), ErrInvalidTransition)
+	assert.ErrorIs(t, m.ConfirmHeld(1), ErrInvalidTransition)
+}
+
+func TestMeetingConfirmHeld(t *testing.T) {
+	now := time.Now()
+	m := newMeeting(1, 2, 3)
+
+	assert.ErrorIs(t, m.ConfirmHeld(1), ErrInvalidTransition)
+
+	for _, id := range []int{1, 2, 3} {
+		assert.NoError(t, m.Accept(id, now))
+	}
+
+	assert.NoError(t, m.ConfirmHeld(1))
+	assert.NoError(t, m.ConfirmHeld(2))
+	assert.Equal(t, MeetingAccepted, m.State)
+
+	assert.NoError(t, m.ConfirmHeld(3))
+	assert.Equal(t, MeetingHeld, m.State)
+}
+
+func TestMeetingReportNoShow(t *testing.T) {
+	now := time.Now()
+	m := newMeeting(1, 2, 3)
+
+	assert.ErrorIs(t, m.ReportNoShow(1, nil), ErrInvalidTransition)
+
+	for _, id := range []int{1, 2, 3} {
+		assert.NoError(t, m.Accept(id, now))
+	}
+
+	assert.ErrorIs(t, m.ReportNoShow(1, []int{1}), ErrInvalidTransition)
+	assert.ErrorIs(t, m.ReportNoShow(1, []int{4}), ErrNotParticipant)
+	assert.Equal(t, MeetingAccepted, m.State)
+
+	// a single claim isn't final while others haven't reported
+	assert.NoError(t, m.ReportNoShow(1, []int{3}))
+	assert.NoError(t, m.ReportNoShow(1, []int{3}))
+	assert.Equal(t, MeetingAccepted, m.State)
+	assert.Equal(t, []int{1}, m.Participants[2].ReportedBy)
+	assert.False(t, m.Participants[2].NoShow)
+
+	assert.NoError(t, m.ConfirmHeld(2))
+	assert.ErrorIs(t, m.ReportNoShow(1, nil), ErrConfirmedHeld)
+	assert.ErrorIs(t, m.ReportNoShow(3, []int{2}), ErrConfirmedHeld)
+	assert.Empty(t, m.Participants[1].ReportedBy)
+
+	assert.NoError(t, m.ReportNoShow(2, []int{3}))
+	assert.Equal(t, MeetingNoShow, m.State)
+	assert.False(t, m.Participants[0].NoShow)
+	assert.False(t, m.Participants[1].NoShow)
+	assert.True(t, m.Participants[2].NoShow)
+
+	// the only other participant of a pair decides alone
+	pair := newMeeting(1, 2)
+	assert.NoError(t, pair.Accept(1, now))
+	assert.NoError(t, pair.Accept(2, now))
+	assert.NoError(t, pair.ReportNoShow(2, nil))
+	assert.Equal(t, MeetingNoShow, pair.State)
+	assert.True(t, pair.Participants[0].NoShow)
+	assert.False(t, pair.Participants[1].NoShow)
+}
+
+func TestMeetingSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	slot := now.Add(4 * time.Hour)
+
+	m := newMeeting(1, 2)
+	m.ProposedSlots = []time.Time{slot}
+
+	assert.ErrorIs(t, m.Schedule(1, slot, now), ErrInvalidTransition)
+
+	assert.NoError(t, m.Accept(1, now))
+	assert.NoError(t, m.Accept(2, now))
+
+	assert.ErrorIs(t, m.Schedule(3, slot, now), ErrNotParticipant)
+	assert.ErrorIs(t, m.Schedule(1, slot.Add(time.Hour), now), ErrSlotNotProposed)
+	assert.ErrorIs(t, m.Schedule(1, slot, slot), ErrSlotInPast)
+
+	assert.NoError(t, m.Schedule(2, slot.In(time.FixedZone("MSK", 3*60*60)), now))
+	assert.Equal(t, MeetingScheduled, m.State)
+	assert.Equal(t, slot, *m.ScheduledAt)
+
+	free := newMeeting(1, 2)
+	assert.NoError(t, free.Accept(1, now))
+	assert.NoError(t, free.Accept(2, now))
+	assert.NoError(t, free.Schedule(1, slot.Add(time.Hour), now))
+}
+
+func TestMeetingJoin(t *testing.T) {
+	now := time.Now()
+	m := newMeeting(1, 2)
+
+	assert.NoError(t, m.Accept(1, now))
+	assert.NoError(t, m.Accept(2, now))
+
+	assert.ErrorIs(t, m.Join(2), ErrInvalidTransition)
+	assert.NoError(t, m.Join(3))
+	assert.Equal(t, MeetingAccepted, m.State)
+
+	assert.NoError(t, m.Accept(3, now))
+	assert.Equal(t, MeetingAccepted, m.State)
+	assert.ErrorIs(t, m.Accept(3, now), ErrInvalidTransition)
+
+	// a joiner who declines leaves the meeting the others agreed on
+	assert.NoError(t, m.Decline(3, now))
+	assert.Equal(t, MeetingAccepted, m.State)
+	assert.Equal(t, []int{1, 2}, m.Remaining())
+	assert.ErrorIs(t, m.Decline(3, now), ErrAlreadyResponded)
+	assert.ErrorIs(t, m.ConfirmHeld(3), ErrNotParticipant)
+
+	assert.NoError(t, m.Join(4))
+	assert.NoError(t, m.Schedule(1, now.Add(time.Hour), now))
+	assert.NoError(t, m.Decline(4, now))
+	assert.Equal(t, MeetingScheduled, m.State)
+	assert.Equal(t, []int{1, 2}, m.Remaining())
+
+	assert.NoError(t, m.ConfirmHeld(1))
+	assert.NoError(t, m.ConfirmHeld(2))
+	assert.Equal(t, MeetingHeld, m.State)
+	assert.ErrorIs(t, m.Join(5), ErrInvalidTransition)
+
+	// a member declining declines the meeting with its joiners
+	declined := newMeeting(1, 2)
+	assert.NoError(t, declined.Join(3))
+	assert.NoError(t, declined.Decline(1, now))
+	assert.Equal(t, MeetingDeclined, declined.State)
+	assert.Equal(t, []int{2, 3}, declined.Remaining())
+
+	cancelled := newMeeting(1, 2)
+	assert.NoError(t, cancelled.Cancel())
+	assert.ErrorIs(t, cancelled.Cancel(), ErrInvalidTransition)
+}
@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+type MemberRole string
+
+const (
+	RoleCreator MemberRole = "creator"
+	RoleAdmin   MemberRole = "admin"
+	RoleMember  MemberRole = "member"
+)
+
+type MemberStatus string
+
+const (
+	StatusActive  MemberStatus = "active"
+	StatusPending MemberStatus = "pending"
+	StatusBanned  MemberStatus = "banned"
+)
+
+// Member is a user as seen from inside a space.
+type Member struct {
+	User         *User         `doc:"Member profile" json:"user"`
+	Role         MemberRole    `doc:"Member role in space" json:"role" enum:"creator,admin,member" example:"member"`
+	Status       MemberStatus  `doc:"Membership status" json:"status" enum:"active,pending,banned" example:"active"`
+	BanReason    string        `doc:"Why member was banned" json:"ban_reason,omitempty" example:"spam"`
+	UserTags     Tags          `doc:"User's tags" json:"user_tags"`
+	PairTags     Tags          `doc:"User's preference tags" json:"pair_tags"`
+	Availability *Availability `doc:"Weekly availability for meetings" json:"availability,omitempty"`
+	MatchRole    string        `doc:"Role in mentoring, one of the space settings roles" json:"match_role,omitempty" example:"mentor"`
+	Capacity     int           `doc:"Mentees a mentor has at once, space default if 0" json:"capacity,omitempty" example:"2"`
+	JoinedAt     time.Time     `doc:"When user joined space" json:"joined_at"`
+}
+
+// MemberRoleOf maps user_space flags to a role.
+func MemberRoleOf(admin, creator bool) MemberRole {
+	switch {
+	case creator:
+		return RoleCreator
+	case admin:
+		return RoleAdmin
+	default:
+		return RoleMember
+	}
+}
+
+// IsAdmin reports whether member can manage other members.
+func (m *Member) IsAdmin() bool {
+	return m.Status == StatusActive && (m.Role == RoleAdmin || m.Role == RoleCreator)
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"github.com/Slava02/Involvio/internal/errs"
+	"time"
+)
+
+var (
+	ErrMentorshipEnded = errs.New(errs.Conflict, "mentorship.ended", "mentorship has already ended")
+	ErrInvalidRole     = errs.New(errs.Invalid, "member.invalid_match_role", "invalid match role")
+)
+
+// Mentorship is a lasting pair of a mentor and a mentee in a mentoring space.
+// They meet in every round until it's ended.
+type Mentorship struct {
+	ID        int        `doc:"Mentorship ID" json:"id" example:"12"`
+	SpaceID   int        `doc:"Space ID" json:"space_id" example:"1234"`
+	MentorID  int        `doc:"Mentor user ID" json:"mentor_id" example:"1234"`
+	MenteeID  int        `doc:"Mentee user ID" json:"mentee_id" example:"4321"`
+	StartedAt time.Time  `doc:"When mentee was assigned to mentor" json:"started_at"`
+	EndedAt   *time.Time `doc:"When mentorship was ended" json:"ended_at,omitempty"`
+}
+
+// Active reports whether the mentorship hasn't ended.
+func (m *Mentorship) Active() bool {
+	return m.EndedAt == nil
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"github.com/Slava02/Involvio/internal/errs"
+	"time"
+)
+
+// MaxOverrides bounds overrides of a round preview.
+const MaxOverrides = 50
+
+var (
+	ErrInvalidOverride  = errs.New(errs.Invalid, "override.invalid", "override needs a valid kind and two different members")
+	ErrInvalidSwap      = errs.New(errs.Invalid, "override.invalid_swap", "swapped members must be in different meetings of the preview")
+	ErrOverrideMember   = errs.New(errs.Invalid, "override.not_member", "overridden members must be active members of the space")
+	ErrOverrideConflict = errs.New(errs.Conflict, "override.conflict", "override conflicts with other overrides, constraint rules or group size")
+	ErrOverrideMode     = errs.New(errs.Invalid, "override.mentoring", "overrides are not supported in mentoring mode")
+	ErrTooManyOverrides = errs.New(errs.Invalid, "override.too_many", "too many overrides of the preview")
+)
+
+type OverrideKind string
+
+const (
+	// OverridePin makes two members meet.
+	OverridePin OverrideKind = "pin"
+	// OverrideForbid keeps two members apart.
+	OverrideForbid OverrideKind = "forbid"
+	// OverrideSwap exchanges two members between their meetings.
+	OverrideSwap OverrideKind = "swap"
+)
+
+// Override is an admin's edit of a round preview. Matching of the rest of
+// the round is redone around all overrides of the preview.
+type Override struct {
+	Kind    OverrideKind `doc:"pin makes members meet, forbid keeps them apart, swap exchanges them between their meetings" json:"kind" enum:"pin,forbid,swap" example:"pin"`
+	UserIDs []int        `doc:"Two members the override is about" json:"user_ids" minItems:"2" maxItems:"2"`
+	// Groups of a swap are fixed when it's made, later matching keeps them.
+	Groups    [][]int   `doc:"Meetings the swap formed, they are kept together" json:"groups,omitempty" required:"false"`
+	AdminID   int       `doc:"Admin who made the override" json:"admin_id" example:"1234"`
+	CreatedAt time.Time `doc:"When the override was made" json:"created_at"`
+}
+
+// Validate returns ErrInvalidOverride if the override is malformed.
+func (o Override) Validate() error {
+	switch o.Kind {
+	case OverridePin, OverrideForbid, OverrideSwap:
+	default:
+		return ErrInvalidOverride
+	}
+
+	if len(o.UserIDs) != 2 || o.UserIDs[0] <= 0 || o.UserIDs[1] <= 0 || o.UserIDs[0] == o.UserIDs[1] {
+		return ErrInvalidOverride
+	}
+
+	return nil
+}
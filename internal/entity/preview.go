@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"github.com/Slava02/Involvio/internal/errs"
+	"time"
+)
+
+// PreviewTTL is how long a round preview can be published.
+const PreviewTTL = 24 * time.Hour
+
+var ErrPreviewStale = errs.New(errs.Conflict, "preview.stale", "space settings or members changed since the preview, preview again")
+
+type ExclusionReason string
+
+const (
+	ExcludedPending   ExclusionReason = "pending"
+	ExcludedBanned    ExclusionReason = "banned"
+	ExcludedNoRole    ExclusionReason = "no_role"
+	ExcludedNoOverlap ExclusionReason = "no_overlap"
+	ExcludedNoPartner ExclusionReason = "no_partner"
+)
+
+// StaleReason is why a preview can't be published as it is.
+type StaleReason string
+
+// StaleMemberErased previews lost a member erased from the service.
+const StaleMemberErased StaleReason = "member_erased"
+
+// Exclusion is a member left out of a round and why.
+type Exclusion struct {
+	UserID int             `doc:"User ID" json:"user_id" example:"1234"`
+	Reason ExclusionReason `doc:"pending and banned members aren't matched, no_role members have no mentoring role, no_overlap members have no common free time with anyone, no_partner members had no compatible partner left" json:"reason" enum:"pending,banned,no_role,no_overlap,no_partner" example:"no_partner"`
+}
+
+// MatchMetrics describe how good the matching of a round is.
+type MatchMetrics struct {
+	Members      int     `doc:"Active members matched from" json:"members" example:"120"`
+	Matched      int     `doc:"Members who got a meeting" json:"matched" example:"118"`
+	Meetings     int     `doc:"Meetings matched" json:"meetings" example:"59"`
+	MatchRate    float64 `doc:"Share of members who got a meeting" json:"match_rate" example:"0.98"`
+	AverageSize  float64 `doc:"Average meeting size" json:"average_size" example:"2"`
+	Repeats      int     `doc:"Pairs who met within the repeat window and meet again" json:"repeats" example:"0"`
+	SoftBroken   int     `doc:"Pairs breaking soft constraint rules" json:"soft_broken" example:"3"`
+	WithoutSlots int     `doc:"Meetings without common free time of participants" json:"without_slots" example:"4"`
+}
+
+// PlannedMeeting is a meeting of a preview, it's not sent out yet.
+type PlannedMeeting struct {
+	Participants  []int       `doc:"Matched members" json:"participants"`
+	ProposedSlots []time.Time `doc:"Times everyone is available, best first" json:"proposed_slots"`
+}
+
+// RoundPreview is a matched round nobody was told about yet. Admins may
+// override it before publishing, which creates exactly this round, together
+// with mentorships it starts and ends.
+type RoundPreview struct {
+	ID              string            `doc:"Preview ID" json:"id" example:"6f1c2a4e-8d1b-4f7a-9a53-0c8b7e2d9f10"`
+	SpaceID         int               `doc:"Space ID" json:"space_id" example:"1234"`
+	AdminID         int               `doc:"Admin who previewed the round" json:"admin_id" example:"1234"`
+	SettingsVersion int               `doc:"Version of space settings the round was matched with" json:"settings_version" example:"3"`
+	Meetings        []*PlannedMeeting `doc:"Meetings the round would propose" json:"meetings"`
+	Unmatched       []Exclusion       `doc:"Members left out and why" json:"unmatched"`
+	NoOverlap       []int             `doc:"Members with no common free time with anyone" json:"no_overlap"`
+	Metrics         MatchMetrics      `doc:"Matching quality" json:"metrics"`
+	Overrides       []Override        `doc:"Admin edits the round is matched around" json:"overrides"`
+	Seed            int64             `doc:"Random seed the round was matched with" json:"seed" example:"4242"`
+	Strategy        string            `doc:"Matching strategy of the round" json:"strategy" example:"default"`
+	StrategyVersion int               `doc:"Version of the strategy" json:"strategy_version" example:"1"`
+	Snapshot        *RoundSnapshot    `doc:"Inputs the round was matched from" json:"snapshot"`
+	Version         int               `doc:"Preview version, incremented by every override" json:"version" example:"1"`
+	Stale           StaleReason       `doc:"Why the preview can't be published as it is, member_erased if a member was erased since, an override or a new preview matches it again" json:"stale,omitempty" enum:"member_erased" required:"false"`
+	// Mentorships change only in mentoring mode.
+	EndedMentorships   []int         `doc:"Mentorships publishing ends" json:"ended_mentorships"`
+	StartedMentorships []*Mentorship `doc:"Mentorships publishing starts" json:"started_mentorships"`
+	CreatedAt          time.Time     `doc:"When round was previewed" json:"created_at"`
+	ExpiresAt          time.Time     `doc:"Until when the preview can be published" json:"expires_at"`
+}
+
+// Erase removes the user from the preview and marks it stale. Members left
+// alone in their meetings are unmatched. It reports whether the user was in
+// the preview.
+func (p *RoundPreview) Erase(userID int) bool {
+	erased := false
+
+	meetings := make([]*PlannedMeeting, 0, len(p.Meetings))
+	for _, meeting := range p.Meetings {
+		rest, ok := without(meeting.Participants, userID)
+		if !ok {
+			meetings = append(meetings, meeting)
+			continue
+		}
+
+		erased = true
+		if len(rest) >= MinGroupSize {
+			meeting.Participants = rest
+			meetings = append(meetings, meeting)
+			continue
+		}
+
+		for _, id := range rest {
+			p.Unmatched = append(p.Unmatched, Exclusion{UserID: id, Reason: ExcludedNoPartner})
+		}
+	}
+	p.Meetings = meetings
+
+	unmatched := make([]Exclusion, 0, len(p.Unmatched))
+	for _, e := range p.Unmatched {
+		if e.UserID == userID {
+			erased = true
+			continue
+		}
+		unmatched = append(unmatched, e)
+	}
+	p.Unmatched = unmatched
+
+	var ok bool
+	if p.NoOverlap, ok = without(p.NoOverlap, userID); ok {
+		erased = true
+	}
+	if p.Overrides, ok = eraseOverrides(p.Overrides, userID); ok {
+		erased = true
+	}
+
+	started := make([]*Mentorship, 0, len(p.StartedMentorships))
+	for _, m := range p.StartedMentorships {
+		if m.MentorID == userID || m.MenteeID == userID {
+			erased = true
+			continue
+		}
+		started = append(started, m)
+	}
+	p.StartedMentorships = started
+
+	if p.Snapshot != nil && p.Snapshot.Erase(userID) {
+		erased = true
+	}
+
+	if erased {
+		p.Stale = StaleMemberErased
+	}
+
+	return erased
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundPreviewErase(t *testing.T) {
+	preview := &RoundPreview{
+		Meetings:  []*PlannedMeeting{{Participants: []int{1, 2}}, {Participants: []int{3, 4, 5}}},
+		Unmatched: []Exclusion{{UserID: 6, Reason: ExcludedNoOverlap}},
+		NoOverlap: []int{6},
+		Overrides: []Override{{Kind: OverridePin, UserIDs: []int{1, 2}}, {Kind: OverrideForbid, UserIDs: []int{3, 6}}},
+		Snapshot:  &RoundSnapshot{Members: []SnapshotMember{{UserID: 1}, {UserID: 2}}},
+	}
+
+	assert.False(t, preview.Erase(7))
+	assert.Empty(t, preview.Stale)
+
+	assert.True(t, preview.Erase(1))
+	assert.Equal(t, StaleMemberErased, preview.Stale)
+	assert.Equal(t, []*PlannedMeeting{{Participants: []int{3, 4, 5}}}, preview.Meetings)
+	assert.Equal(t, []Exclusion{{UserID: 6, Reason: ExcludedNoOverlap}, {UserID: 2, Reason: ExcludedNoPartner}}, preview.Unmatched)
+	assert.Equal(t, []Override{{Kind: OverrideForbid, UserIDs: []int{3, 6}}}, preview.Overrides)
+	assert.Equal(t, []SnapshotMember{{UserID: 2}}, preview.Snapshot.Members)
+
+	assert.True(t, preview.Erase(4))
+	assert.Equal(t, []int{3, 5}, preview.Meetings[0].Participants)
+}
@@ -0,0 +1,215 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/Slava02/Involvio/internal/errs"
+	"golang.org/x/text/language"
+	"time"
+)
+
+type Cadence string
+
+const (
+	CadenceWeekly   Cadence = "weekly"
+	CadenceBiweekly Cadence = "biweekly"
+	CadenceMonthly  Cadence = "monthly"
+)
+
+type MatchMode string
+
+const (
+	// MatchPairs matches pairs, or trios for odd members, who never met.
+	MatchPairs MatchMode = "pairs"
+	// MatchGroups partitions members into groups with the fewest repeat pairs.
+	MatchGroups MatchMode = "groups"
+	// MatchMentoring pairs mentees with mentors for good, every round meets them again.
+	MatchMentoring MatchMode = "mentoring"
+)
+
+type JoinMode string
+
+const (
+	JoinOpen     JoinMode = "open"
+	JoinApproval JoinMode = "approval"
+)
+
+const (
+	MinGroupSize    = 2
+	MaxGroupSize    = 3
+	MaxGroupBound   = 12
+	MaxRoleLength   = 30
+	MaxCapacity     = 20
+	MaxStrategyName = 50
+	MaxRepeatWindow = 52
+	MaxRating       = 5
+	MaxRematchHours = 7 * 24
+)
+
+// DefaultStrategy matches avoiding repeats and breaking soft constraint rules
+// as rarely as possible.
+const DefaultStrategy = "default"
+
+var ErrInvalidSettings = errs.New(errs.Invalid, "settings.invalid", "space settings are invalid")
+
+// SpaceSettings configure matching and membership of a space.
+type SpaceSettings struct {
+	Cadence        Cadence          `doc:"How often rounds are run" json:"cadence" enum:"weekly,biweekly,monthly" example:"weekly"`
+	Mode           MatchMode        `doc:"Whether rounds match pairs, groups or mentors with mentees" json:"mode" enum:"pairs,groups,mentoring" example:"pairs"`
+	GroupSize      int              `doc:"Members in a meeting, in pairs mode 2 for pairs or 3 for trios, in groups mode the target group size" json:"group_size" minimum:"2" maximum:"12" example:"2"`
+	GroupMin       int              `doc:"Smallest group in groups mode" json:"group_min" minimum:"2" maximum:"12" example:"3"`
+	GroupMax       int              `doc:"Largest group in groups mode" json:"group_max" minimum:"2" maximum:"12" example:"6"`
+	MentorRole     string           `doc:"Match role of mentors in mentoring mode" json:"mentor_role" minLength:"1" maxLength:"30" example:"alumni"`
+	MenteeRole     string           `doc:"Match role of mentees in mentoring mode" json:"mentee_role" minLength:"1" maxLength:"30" example:"student"`
+	MentorCapacity int              `doc:"Mentees a mentor has at once unless the member sets otherwise" json:"mentor_capacity" minimum:"1" maximum:"20" example:"3"`
+	Constraints    []ConstraintRule `doc:"Rules keeping members apart by their user tags" json:"constraints" required:"false" maxItems:"20"`
+	Strategy       string           `doc:"Matching strategy of pairs and groups modes: default, random, tag_weighted, diversity or mentor_bipartite" json:"strategy" required:"false" minLength:"1" maxLength:"50" example:"default"`
+	StrategyParams map[string]any   `doc:"Parameters of the strategy, validated against its schema" json:"strategy_params" required:"false"`
+	RepeatWindow   int              `doc:"Past rounds whose meetings are not repeated" json:"repeat_window" minimum:"0" maximum:"52" example:"4"`
+	MinRating      float64          `doc:"Members rated below are not matched, 0 disables" json:"min_rating" minimum:"0" maximum:"5" example:"0"`
+	RematchHours   int              `doc:"Hours after a round starts during which members left without a partner are matched again, 0 disables" json:"rematch_hours" minimum:"0" maximum:"168" example:"48"`
+	JoinMode       JoinMode         `doc:"Whether joining is open or requires admin approval" json:"join_mode" enum:"open,approval" example:"open"`
+	Language       string           `doc:"Default language, BCP 47 tag" json:"language" example:"en"`
+	Timezone       string           `doc:"IANA timezone of the space" json:"timezone" example:"Europe/Moscow"`
+}
+
+// DefaultSpaceSettings are settings of a new space.
+func DefaultSpaceSettings() SpaceSettings {
+	return SpaceSettings{
+		Cadence:        CadenceWeekly,
+		Mode:           MatchPairs,
+		GroupSize:      MinGroupSize,
+		GroupMin:       3,
+		GroupMax:       6,
+		MentorRole:     "mentor",
+		MenteeRole:     "mentee",
+		MentorCapacity: 3,
+		Constraints:    []ConstraintRule{},
+		Strategy:       DefaultStrategy,
+		StrategyParams: map[string]any{},
+		RepeatWindow:   4,
+		MinRating:      0,
+		RematchHours:   48,
+		JoinMode:       JoinOpen,
+		Language:       "en",
+		Timezone:       DefaultTimezone,
+	}
+}
+
+// Validate returns ErrInvalidSettings or ErrInvalidTimezone wrapped with the
+// first invalid field.
+func (s SpaceSettings) Validate() error {
+	invalid := func(field string, value any) error {
+		return fmt.Errorf("%w: %s %v", ErrInvalidSettings, field, value)
+	}
+
+	switch s.Cadence {
+	case CadenceWeekly, CadenceBiweekly, CadenceMonthly:
+	default:
+		return invalid("cadence", s.Cadence)
+	}
+
+	if s.GroupMin < MinGroupSize || s.GroupMin > MaxGroupBound {
+		return invalid("group_min", s.GroupMin)
+	}
+
+	if s.GroupMax < s.GroupMin || s.GroupMax > MaxGroupBound {
+		return invalid("group_max", s.GroupMax)
+	}
+
+	switch s.Mode {
+	case MatchPairs:
+		if s.GroupSize < MinGroupSize || s.GroupSize > MaxGroupSize {
+			return invalid("group_size", s.GroupSize)
+		}
+	case MatchGroups:
+		if s.GroupSize < s.GroupMin || s.GroupSize > s.GroupMax {
+			return invalid("group_size", s.GroupSize)
+		}
+	case MatchMentoring:
+	default:
+		return invalid("mode", s.Mode)
+	}
+
+	if s.RepeatWindow < 0 || s.RepeatWindow > MaxRepeatWindow {
+		return invalid("repeat_window", s.RepeatWindow)
+	}
+
+	if s.MinRating < 0 || s.MinRating > MaxRating {
+		return invalid("min_rating", s.MinRating)
+	}
+
+	if s.MentorRole == "" || len(s.MentorRole) > MaxRoleLength {
+		return invalid("mentor_role", s.MentorRole)
+	}
+
+	if s.MenteeRole == "" || len(s.MenteeRole) > MaxRoleLength || s.MenteeRole == s.MentorRole {
+		return invalid("mentee_role", s.MenteeRole)
+	}
+
+	if s.MentorCapacity < 1 || s.MentorCapacity > MaxCapacity {
+		return invalid("mentor_capacity", s.MentorCapacity)
+	}
+
+	if len(s.Constraints) > MaxConstraints {
+		return invalid("constraints", len(s.Constraints))
+	}
+
+	for _, rule := range s.Constraints {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Strategy == "" || len(s.Strategy) > MaxStrategyName {
+		return invalid("strategy", s.Strategy)
+	}
+
+	if s.RematchHours < 0 || s.RematchHours > MaxRematchHours {
+		return invalid("rematch_hours", s.RematchHours)
+	}
+
+	switch s.JoinMode {
+	case JoinOpen, JoinApproval:
+	default:
+		return invalid("join_mode", s.JoinMode)
+	}
+
+	if _, err := language.Parse(s.Language); err != nil {
+		return invalid("language", s.Language)
+	}
+
+	if _, err := LoadLocation(s.Timezone); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SpaceSettingsVersion is a stored version of space settings. Settings are
+// never changed in place, so a round can refer to the version it was run with.
+type SpaceSettingsVersion struct {
+	SpaceID int `doc:"Space ID" json:"space_id" example:"1234"`
+	Version int `doc:"Settings version, sent as ETag" json:"version" example:"1"`
+	SpaceSettings
+	CreatedAt time.Time `doc:"When this version was saved" json:"created_at"`
+}
+
+// GroupBounds returns the target, smallest and largest meeting size. Pairs
+// mode meets in pairs, a trio takes a member who would be left alone, a
+// mentor meets each mentee alone.
+func (s SpaceSettings) GroupBounds() (size, minSize, maxSize int) {
+	switch s.Mode {
+	case MatchGroups:
+		return s.GroupSize, s.GroupMin, s.GroupMax
+	case MatchMentoring:
+		return MinGroupSize, MinGroupSize, MinGroupSize
+	default:
+		return s.GroupSize, MinGroupSize, MaxGroupSize
+	}
+}
+
+// RematchDeadline is when members left without a partner in a round started
+// at start stop being matched again.
+func (s SpaceSettings) RematchDeadline(start time.Time) time.Time {
+	return start.Add(time.Duration(s.RematchHours) * time.Hour)
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceSettingsValidate(t *testing.T) {
+	assert.NoError(t, DefaultSpaceSettings().Validate())
+
+	tests := []struct {
+		name   string
+		change func(s *SpaceSettings)
+		want   error
+	}{
+		{"unknown cadence", func(s *SpaceSettings) { s.Cadence = "daily" }, ErrInvalidSettings},
+		{"single person groups", func(s *SpaceSettings) { s.GroupSize = 1 }, ErrInvalidSettings},
+		{"pairs of four", func(s *SpaceSettings) { s.GroupSize = 4 }, ErrInvalidSettings},
+		{"unknown mode", func(s *SpaceSettings) { s.Mode = "teams" }, ErrInvalidSettings},
+		{"group below bounds", func(s *SpaceSettings) { s.Mode, s.GroupSize = MatchGroups, 2 }, ErrInvalidSettings},
+		{"reversed bounds", func(s *SpaceSettings) { s.Mode, s.GroupSize, s.GroupMax = MatchGroups, 3, 2 }, ErrInvalidSettings},
+		{"empty mentor role", func(s *SpaceSettings) { s.MentorRole = "" }, ErrInvalidSettings},
+		{"same roles", func(s *SpaceSettings) { s.MenteeRole = s.MentorRole }, ErrInvalidSettings},
+		{"mentor without capacity", func(s *SpaceSettings) { s.MentorCapacity = 0 }, ErrInvalidSettings},
+		{"unknown constraint", func(s *SpaceSettings) { s.Constraints = []ConstraintRule{{Kind: "never", Tag: "city"}} }, ErrInvalidSettings},
+		{"constraint without tag", func(s *SpaceSettings) { s.Constraints = []ConstraintRule{{Kind: ConstraintNeverEqual}} }, ErrInvalidSettings},
+		{"huge groups", func(s *SpaceSettings) { s.Mode, s.GroupSize, s.GroupMax = MatchGroups, 6, 13 }, ErrInvalidSettings},
+		{"no strategy", func(s *SpaceSettings) { s.Strategy = "" }, ErrInvalidSettings},
+		{"negative repeat window", func(s *SpaceSettings) { s.RepeatWindow = -1 }, ErrInvalidSettings},
+		{"negative rating", func(s *SpaceSettings) { s.MinRating = -1 }, ErrInvalidSettings},
+		{"rating above scale", func(s *SpaceSettings) { s.MinRating = 5.5 }, ErrInvalidSettings},
+		{"rematch after a week", func(s *SpaceSettings) { s.RematchHours = 169 }, ErrInvalidSettings},
+		{"unknown join mode", func(s *SpaceSettings) { s.JoinMode = "invite" }, ErrInvalidSettings},
+		{"empty language", func(s *SpaceSettings) { s.Language = "" }, ErrInvalidSettings},
+		{"malformed language", func(s *SpaceSettings) { s.Language = "english language" }, ErrInvalidSettings},
+		{"unknown timezone", func(s *SpaceSettings) { s.Timezone = "Mars/Olympus" }, ErrInvalidTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DefaultSpaceSettings()
+			tt.change(&s)
+			assert.ErrorIs(t, s.Validate(), tt.want)
+		})
+	}
+
+	s := DefaultSpaceSettings()
+	s.GroupSize, s.Language, s.Timezone, s.JoinMode = 3, "ru-RU", "Europe/Moscow", JoinApproval
+	assert.NoError(t, s.Validate())
+
+	assert.Zero(t, DefaultSpaceSettings().MinRating, "min rating is disabled by default")
+	s.MinRating = MaxRating
+	assert.NoError(t, s.Validate())
+
+	s.Mode, s.GroupSize, s.GroupMin, s.GroupMax = MatchGroups, 4, 3, 6
+	assert.NoError(t, s.Validate())
+}
@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/Slava02/Involvio/internal/errs"
+	"sort"
+	"time"
+)
+
+// MaxSeed bounds round seeds, so they survive JSON numbers.
+const MaxSeed = 1 << 53
+
+var ErrSnapshotNotFound = errs.New(errs.NotFound, "round.no_snapshot", "round was run before inputs were recorded, it can't be replayed")
+
+// SnapshotMember is what matching knows about a member.
+type SnapshotMember struct {
+	UserID       int           `doc:"User ID" json:"user_id" example:"1234"`
+	UserTags     Tags          `doc:"User's tags" json:"user_tags"`
+	PairTags     Tags          `doc:"User's preference tags" json:"pair_tags"`
+	Availability *Availability `doc:"Weekly availability for meetings" json:"availability,omitempty"`
+	MatchRole    string        `doc:"Role in mentoring" json:"match_role,omitempty" example:"mentor"`
+	Capacity     int           `doc:"Mentees a mentor has at once, space default if 0" json:"capacity,omitempty" example:"2"`
+}
+
+// SnapshotMemberOf keeps what matching needs of the member.
+func SnapshotMemberOf(m *Member) SnapshotMember {
+	return SnapshotMember{
+		UserID:       m.User.ID,
+		UserTags:     m.UserTags,
+		PairTags:     m.PairTags,
+		Availability: m.Availability,
+		MatchRole:    m.MatchRole,
+		Capacity:     m.Capacity,
+	}
+}
+
+// Member returns the member matching works with.
+func (m SnapshotMember) Member() *Member {
+	return &Member{
+		User:         &User{ID: m.UserID},
+		Status:       StatusActive,
+		UserTags:     m.UserTags,
+		PairTags:     m.PairTags,
+		Availability: m.Availability,
+		MatchRole:    m.MatchRole,
+		Capacity:     m.Capacity,
+	}
+}
+
+// RoundSnapshot is everything a round was matched from. Matching the same
+// snapshot with the same seed gives the same round.
+type RoundSnapshot struct {
+	Settings    SpaceSettingsVersion `doc:"Space settings the round was matched with, constraint rules included" json:"settings"`
+	Members     []SnapshotMember     `doc:"Eligible members in the order they were matched" json:"members"`
+	Pending     []int                `doc:"Members pending approval" json:"pending"`
+	Banned      []int                `doc:"Banned members" json:"banned"`
+	Held        [][]int              `doc:"Meetings held within the repeat window" json:"held"`
+	Mentorships []*Mentorship        `doc:"Active mentorships in mentoring mode" json:"mentorships"`
+	Overrides   []Override           `doc:"Admin overrides of the preview" json:"overrides"`
+	At          time.Time            `doc:"When the round was matched, slots are proposed after it" json:"at"`
+	// Erased members are removed, matching the rest may differ from the round.
+	Redacted bool `doc:"Whether members erased since the round were removed from the snapshot" json:"redacted,omitempty"`
+}
+
+// Erase removes the user from the snapshot, together with meetings held and
+// overrides naming them, and marks the snapshot redacted. It reports whether
+// the user was in the snapshot.
+func (s *RoundSnapshot) Erase(userID int) bool {
+	erased := false
+
+	members := make([]SnapshotMember, 0, len(s.Members))
+	for _, m := range s.Members {
+		if m.UserID == userID {
+			erased = true
+			continue
+		}
+		members = append(members, m)
+	}
+	s.Members = members
+
+	var ok bool
+	if s.Pending, ok = without(s.Pending, userID); ok {
+		erased = true
+	}
+	if s.Banned, ok = without(s.Banned, userID); ok {
+		erased = true
+	}
+	if s.Held, ok = EraseFromGroups(s.Held, userID, MinGroupSize); ok {
+		erased = true
+	}
+
+	mentorships := make([]*Mentorship, 0, len(s.Mentorships))
+	for _, m := range s.Mentorships {
+		if m.MentorID == userID || m.MenteeID == userID {
+			erased = true
+			continue
+		}
+		mentorships = append(mentorships, m)
+	}
+	s.Mentorships = mentorships
+
+	overrides, ok := eraseOverrides(s.Overrides, userID)
+	if ok {
+		erased = true
+	}
+	s.Overrides = overrides
+
+	if erased {
+		s.Redacted = true
+	}
+
+	return erased
+}
+
+// EraseFromGroups removes the user from groups and drops groups left smaller
+// than minSize. It reports whether the user was in any group.
+func EraseFromGroups(groups [][]int, userID, minSize int) ([][]int, bool) {
+	erased := false
+
+	kept := make([][]int, 0, len(groups))
+	for _, group := range groups {
+		rest, ok := without(group, userID)
+		if ok {
+			erased = true
+		}
+		if len(rest) >= minSize {
+			kept = append(kept, rest)
+		}
+	}
+
+	return kept, erased
+}
+
+// eraseOverrides drops overrides naming the user or keeping them in a meeting.
+func eraseOverrides(overrides []Override, userID int) ([]Override, bool) {
+	erased := false
+
+	kept := make([]Override, 0, len(overrides))
+	for _, o := range overrides {
+		_, named := without(o.UserIDs, userID)
+		_, grouped := EraseFromGroups(o.Groups, userID, 0)
+		if named || grouped {
+			erased = true
+			continue
+		}
+		kept = append(kept, o)
+	}
+
+	return kept, erased
+}
+
+// without returns ids except id and reports whether id was there.
+func without(ids []int, id int) ([]int, bool) {
+	found := false
+
+	rest := make([]int, 0, len(ids))
+	for _, v := range ids {
+		if v == id {
+			found = true
+			continue
+		}
+		rest = append(rest, v)
+	}
+
+	return rest, found
+}
+
+// RoundReplay is a past round matched again from its snapshot and seed.
+type RoundReplay struct {
+	RoundID         int            `doc:"Round ID" json:"round_id" example:"12"`
+	Seed            int64          `doc:"Random seed of the round" json:"seed" example:"4242"`
+	Strategy        string         `doc:"Matching strategy of the round" json:"strategy" example:"default"`
+	StrategyVersion int            `doc:"Strategy version the round was matched with" json:"strategy_version" example:"1"`
+	CurrentVersion  int            `doc:"Strategy version the replay was matched with, results may differ if it changed" json:"current_version" example:"1"`
+	Snapshot        *RoundSnapshot `doc:"Inputs of the round" json:"snapshot"`
+	Original        [][]int        `doc:"Meetings the round matched" json:"original"`
+	Replayed        [][]int        `doc:"Meetings the replay matched" json:"replayed"`
+	Missing         [][]int        `doc:"Meetings of the round the replay didn't match" json:"missing"`
+	Extra           [][]int        `doc:"Meetings of the replay the round didn't match" json:"extra"`
+	Reproduced      bool           `doc:"Whether the replay matched exactly the round's meetings" json:"reproduced"`
+	Redacted        bool           `doc:"Whether members erased since the round are missing, the replay is incomplete then" json:"redacted"`
+}
+
+// DiffGroups returns groups of original missing in replayed and groups of
+// replayed missing in original, order of groups and their members is ignored.
+func DiffGroups(original, replayed [][]int) (missing, extra [][]int) {
+	key := func(group []int) string {
+		sorted := append([]int(nil), group...)
+		sort.Ints(sorted)
+		return fmt.Sprint(sorted)
+	}
+
+	count := make(map[string]int, len(replayed))
+	for _, group := range replayed {
+		count[key(group)]++
+	}
+
+	missing = make([][]int, 0)
+	for _, group := range original {
+		k := key(group)
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		missing = append(missing, group)
+	}
+
+	count = make(map[string]int, len(original))
+	for _, group := range original {
+		count[key(group)]++
+	}
+
+	extra = make([][]int, 0)
+	for _, group := range replayed {
+		k := key(group)
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		extra = append(extra, group)
+	}
+
+	return missing, extra
+}
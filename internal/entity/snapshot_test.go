@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffGroups(t *testing.T) {
+	missing, extra := DiffGroups([][]int{{1, 2}, {3, 4}, {5, 6, 7}}, [][]int{{4, 3}, {7, 5, 6}, {1, 2}})
+	assert.Empty(t, missing)
+	assert.Empty(t, extra)
+
+	missing, extra = DiffGroups([][]int{{1, 2}, {3, 4}}, [][]int{{1, 3}, {2, 4}, {3, 4}})
+	assert.Equal(t, [][]int{{1, 2}}, missing)
+	assert.Equal(t, [][]int{{1, 3}, {2, 4}}, extra)
+}
+
+func TestRoundSnapshotErase(t *testing.T) {
+	snapshot := &RoundSnapshot{
+		Members:     []SnapshotMember{{UserID: 1}, {UserID: 2}, {UserID: 3}},
+		Pending:     []int{4},
+		Banned:      []int{},
+		Held:        [][]int{{1, 2}, {2, 3, 5}},
+		Mentorships: []*Mentorship{{MentorID: 2, MenteeID: 3}, {MentorID: 5, MenteeID: 1}},
+		Overrides: []Override{
+			{Kind: OverridePin, UserIDs: []int{1, 3}},
+			{Kind: OverrideSwap, UserIDs: []int{3, 5}, Groups: [][]int{{3, 2}, {5, 1}}},
+		},
+	}
+
+	assert.False(t, snapshot.Erase(6))
+	assert.False(t, snapshot.Redacted)
+
+	assert.True(t, snapshot.Erase(2))
+	assert.True(t, snapshot.Redacted)
+	assert.Equal(t, []SnapshotMember{{UserID: 1}, {UserID: 3}}, snapshot.Members)
+	assert.Equal(t, []int{4}, snapshot.Pending)
+	assert.Equal(t, [][]int{{3, 5}}, snapshot.Held)
+	assert.Equal(t, []*Mentorship{{MentorID: 5, MenteeID: 1}}, snapshot.Mentorships)
+	assert.Equal(t, []Override{{Kind: OverridePin, UserIDs: []int{1, 3}}}, snapshot.Overrides)
+}
@@ -5,5 +5,10 @@ type Space struct {
 	ID          int    `json:"id"       example:"1234"`
 	Name        string `json:"name"       example:"mai"`
 	Description string `json:"description"       example:"university space"`
-	Tags        Tags   `json:"tags"`
+	Timezone    string `json:"timezone" example:"Europe/Moscow"`
+	// JoinApproval makes JoinSpace create a pending request instead of a membership.
+	JoinApproval bool `json:"join_approval" example:"false"`
+	Tags         Tags `json:"tags"`
+	// Version increments on every update, it is sent as ETag.
+	Version int `json:"version" example:"1"`
 }
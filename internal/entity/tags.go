@@ -4,8 +4,12 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"github.com/Slava02/Involvio/pkg/mergepatch"
+	"reflect"
+	"sort"
 )
 
+// Tags are objects keyed by tag name, e.g. [{"department": "sales"}, {"city": "Moscow"}].
 type Tags []map[string]interface{}
 
 func (a *Tags) Value() (driver.Value, error) {
@@ -13,14 +17,95 @@ func (a *Tags) Value() (driver.Value, error) {
 }
 
 func (a *Tags) Scan(value interface{}) error {
-	if value == nil {
+	switch v := value.(type) {
+	case nil:
 		return nil
+	case string:
+		return json.Unmarshal([]byte(v), &a)
+	case []byte:
+		return json.Unmarshal(v, &a)
+	default:
+		return errors.New("type assertion to []byte failed")
 	}
+}
 
-	b, ok := value.(string)
-	if !ok {
-		return errors.New("type assertion to []byte failed")
+// Patch merges patch keyed by tag name, so a single tag can be changed without
+// resending the rest. Tag values are merge patched, null removes the tag, tags
+// missing in a are appended in the order of names.
+func (a Tags) Patch(patch map[string]interface{}) Tags {
+	patched := make(Tags, 0, len(a)+len(patch))
+	seen := make(map[string]bool, len(patch))
+
+	for _, tag := range a {
+		t := make(map[string]interface{}, len(tag))
+		for name, value := range tag {
+			t[name] = value
+			if p, ok := patch[name]; ok {
+				seen[name] = true
+				if p == nil {
+					delete(t, name)
+				} else {
+					t[name] = mergepatch.Merge(value, p)
+				}
+			}
+		}
+		if len(t) > 0 {
+			patched = append(patched, t)
+		}
+	}
+
+	added := make([]string, 0, len(patch))
+	for name, value := range patch {
+		if !seen[name] && value != nil {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+
+	for _, name := range added {
+		patched = append(patched, map[string]interface{}{name: mergepatch.Merge(nil, patch[name])})
+	}
+
+	return patched
+}
+
+// Satisfies reports whether a meets preferences pair, e.g. pair tags of a
+// member looking for a partner with tags a. Every tag named in pair must be
+// in a with an equal value, or with one of the values if the preference is an
+// array. Empty preferences are met by anyone.
+func (a Tags) Satisfies(pair Tags) bool {
+	for _, tag := range pair {
+		for name, want := range tag {
+			got, ok := a.Get(name)
+			if !ok {
+				return false
+			}
+
+			options, isList := want.([]interface{})
+			if !isList {
+				options = []interface{}{want}
+			}
+
+			found := false
+			for _, option := range options {
+				found = found || reflect.DeepEqual(option, got)
+			}
+
+			if !found {
+				return false
+			}
+		}
 	}
 
-	return json.Unmarshal([]byte(b), &a)
+	return true
+}
+
+// Get returns the value of the named tag.
+func (a Tags) Get(name string) (interface{}, bool) {
+	for _, tag := range a {
+		if value, ok := tag[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
 }
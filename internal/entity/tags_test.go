@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagsPatch(t *testing.T) {
+	tests := []struct {
+		name, tags, patch, want string
+	}{
+		{"change one", `[{"city":"Moscow"},{"department":"sales"}]`, `{"city":"Kazan"}`, `[{"city":"Kazan"},{"department":"sales"}]`},
+		{"remove one", `[{"city":"Moscow"},{"department":"sales"}]`, `{"city":null}`, `[{"department":"sales"}]`},
+		{"add sorted", `[{"city":"Moscow"}]`, `{"lang":"ru","hobby":["chess"]}`, `[{"city":"Moscow"},{"hobby":["chess"]},{"lang":"ru"}]`},
+		{"remove missing", `[{"city":"Moscow"}]`, `{"lang":null}`, `[{"city":"Moscow"}]`},
+		{"merge object value", `[{"langs":{"ru":"native","en":"b2"}}]`, `{"langs":{"en":"c1","de":"a1","ru":null}}`, `[{"langs":{"en":"c1","de":"a1"}}]`},
+		{"empty tags", `null`, `{"city":"Moscow"}`, `[{"city":"Moscow"}]`},
+		{"multi key tag", `[{"city":"Moscow","metro":"Arbat"}]`, `{"metro":null}`, `[{"city":"Moscow"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags Tags
+			var patch map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.tags), &tags))
+			assert.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+
+			got, err := json.Marshal(tags.Patch(patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTagsPatchKeepsOriginal(t *testing.T) {
+	tags := Tags{{"city": "Moscow"}, {"langs": map[string]interface{}{"ru": "native"}}}
+
+	tags.Patch(map[string]interface{}{"city": nil, "langs": map[string]interface{}{"ru": nil}})
+
+	assert.Equal(t, Tags{{"city": "Moscow"}, {"langs": map[string]interface{}{"ru": "native"}}}, tags)
+}
+
+func TestTagsSatisfies(t *testing.T) {
+	tests := []struct {
+		name, tags, pair string
+		want             bool
+	}{
+		{"no preferences", `[{"city":"Moscow"}]`, `null`, true},
+		{"equal value", `[{"city":"Moscow"},{"lang":"ru"}]`, `[{"city":"Moscow"}]`, true},
+		{"other value", `[{"city":"Kazan"}]`, `[{"city":"Moscow"}]`, false},
+		{"missing tag", `[{"lang":"ru"}]`, `[{"city":"Moscow"}]`, false},
+		{"one of values", `[{"city":"Kazan"}]`, `[{"city":["Moscow","Kazan"]}]`, true},
+		{"list value", `[{"hobby":["chess"]}]`, `[{"hobby":[["chess"]]}]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags, pair Tags
+			assert.NoError(t, json.Unmarshal([]byte(tt.tags), &tags))
+			assert.NoError(t, json.Unmarshal([]byte(tt.pair), &pair))
+
+			assert.Equal(t, tt.want, tags.Satisfies(pair))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/Slava02/Involvio/internal/errs"
+	"time"
+)
+
+// DefaultTimezone is used for spaces and events created without a timezone.
+const DefaultTimezone = "UTC"
+
+var ErrInvalidTimezone = errs.New(errs.Invalid, "timezone.invalid", "invalid timezone")
+
+// LoadLocation resolves an IANA timezone name. Empty name means DefaultTimezone,
+// "Local" is rejected because it depends on the server the code runs on.
+func LoadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		tz = DefaultTimezone
+	}
+
+	if tz == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, tz)
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, tz)
+	}
+
+	return loc, nil
+}
@@ -12,13 +12,16 @@ type User struct {
 	UserName  string    `doc:"Username" json:"user_name"       example:"s1av4"`
 	PhotoURL  string    `doc:"Photo URL" json:"photo_url" example:"https://photo"`
 	AuthDate  time.Time `doc:"Authorization date" json:"auth_date"       example:"25.09.2002 12:00"`
+	Version   int       `doc:"Version, sent as ETag" json:"version" example:"1"`
 }
 
 type Form struct {
 	//UserID   int  `doc:"User ID" json:"user_id"       example:"1234"`
-	SpaceID  int  `doc:"Space Id" json:"space_id"       example:"1234"`
-	Admin    bool `doc:"If user is space admin" json:"admin" example:"true"`
-	Creator  bool `doc:"If user is space creator" json:"creator" example:"true"`
-	UserTags Tags `doc:"User's tags" json:"user_tags"`
-	PairTags Tags `doc:"User's preference tags" json:"pair_tags"`
+	SpaceID      int           `doc:"Space Id" json:"space_id"       example:"1234"`
+	Admin        bool          `doc:"If user is space admin" json:"admin" example:"true"`
+	Creator      bool          `doc:"If user is space creator" json:"creator" example:"true"`
+	UserTags     Tags          `doc:"User's tags" json:"user_tags"`
+	PairTags     Tags          `doc:"User's preference tags" json:"pair_tags"`
+	Availability *Availability `doc:"Weekly availability for meetings" json:"availability,omitempty"`
+	Version      int           `doc:"Version, sent as ETag" json:"version" example:"1"`
 }
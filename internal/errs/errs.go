@@ -0,0 +1,51 @@
+// Package errs defines domain errors with stable machine-readable codes.
+//
+// Layers return these errors, possibly wrapped with context; handlers find
+// them with errors.As and turn them into problem responses. Message and code
+// are safe to show to clients, wrapping context is only logged.
+package errs
+
+import "errors"
+
+// Kind says what kind of failure happened, handlers map it to HTTP status.
+type Kind int
+
+const (
+	Internal Kind = iota
+	Invalid
+	NotFound
+	Conflict
+	Forbidden
+	// Precondition means request was made against outdated state.
+	Precondition
+)
+
+// Error is a domain error. Errors are compared by identity, so declare them
+// once as package level variables.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// As returns domain error from err chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// Errors shared by all repositories.
+var (
+	ErrReferenceNotFound = New(NotFound, "reference.not_found", "referenced object not found")
+	ErrInvalidData       = New(Invalid, "data.invalid", "data violates constraints")
+	ErrVersionMismatch   = New(Precondition, "version.mismatch", "object was modified, fetch it again")
+)
@@ -0,0 +1,23 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAs(t *testing.T) {
+	errNotFound := New(NotFound, "space.not_found", "space not found")
+	wrapped := fmt.Errorf("Usecase:GetSpace: %w", fmt.Errorf("Repo:GetSpace: %w", errNotFound))
+
+	e, ok := As(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, "space.not_found", e.Code)
+	assert.Equal(t, "space not found", e.Message)
+	assert.ErrorIs(t, wrapped, errNotFound)
+
+	_, ok = As(errors.New("connection refused"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+type IAuditUseCase interface {
+	GetAuditLog(ctx context.Context, cmd commands.AuditLogCommand) ([]*entity.AuditEntry, int, error)
+}
+
+var _ IAuditUseCase = (*usecase.AuditUseCase)(nil)
+
+const tracerName = "audit handler"
+
+type AuditHandler struct {
+	auditUC IAuditUseCase
+}
+
+func NewAuditHandler(uc IAuditUseCase) *AuditHandler {
+	return &AuditHandler{auditUC: uc}
+}
+
+func (ah *AuditHandler) GetAuditLog(ctx context.Context, req *AuditLogRequest) (*AuditLogResponse, error) {
+	const op = "Handler:GetAuditLog"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.Int("admin id", req.AdminId),
+	)
+	log.Debug(op)
+
+	cmd := commands.AuditLogCommand{
+		SpaceID:    req.ID,
+		AdminID:    req.AdminId,
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		Since:      req.Since,
+		Until:      req.Until,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}
+
+	entries, total, err := ah.auditUC.GetAuditLog(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't get audit log", err)
+	}
+
+	resp := ToAuditLogOutputFromEntity(entries, total, req.Limit, req.Offset)
+
+	return resp, nil
+}
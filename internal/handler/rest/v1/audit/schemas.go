@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"time"
+)
+
+// Converters
+func ToAuditLogOutputFromEntity(entries []*entity.AuditEntry, total, limit, offset int) *AuditLogResponse {
+	resp := &AuditLogResponse{}
+	resp.Body.Entries = entries
+	resp.Body.Total = total
+	resp.Body.Limit = limit
+	resp.Body.Offset = offset
+
+	return resp
+}
+
+type (
+	AuditLogRequest struct {
+		ID         int                `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		AdminId    int                `query:"adminId" required:"true" example:"123" doc:"ID of space admin reading the log"`
+		ActorID    int                `query:"actorId" example:"123" doc:"Filter by user who performed the action"`
+		Action     string             `query:"action" example:"UpdateSpace" doc:"Filter by usecase name"`
+		TargetType entity.AuditTarget `query:"targetType" enum:"space,member,form,event,round,meeting,mentorship" doc:"Filter by kind of changed object"`
+		Since      time.Time          `query:"since" doc:"Only entries created at or after this time"`
+		Until      time.Time          `query:"until" doc:"Only entries created before this time"`
+		Limit      int                `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"Page size"`
+		Offset     int                `query:"offset" minimum:"0" default:"0" doc:"Page offset"`
+	}
+
+	AuditLogResponse struct {
+		Body struct {
+			Entries []*entity.AuditEntry `json:"entries" doc:"Audit entries page, newest first"`
+			Total   int                  `json:"total" example:"42" doc:"Total number of entries matching the filter"`
+			Limit   int                  `json:"limit" example:"20" doc:"Page size"`
+			Offset  int                  `json:"offset" example:"0" doc:"Page offset"`
+		}
+	}
+)
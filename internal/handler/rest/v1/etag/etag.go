@@ -0,0 +1,38 @@
+// Package etag converts object versions to ETag and If-Match header values.
+package etag
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Slava02/Involvio/internal/errs"
+)
+
+// ErrInvalid is returned for If-Match that isn't a version ETag.
+var ErrInvalid = errs.New(errs.Invalid, "etag.invalid", `If-Match must be "*" or an ETag returned by the API`)
+
+// Format returns strong ETag of version.
+func Format(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// Parse returns version from If-Match header. Empty header and "*" match any
+// version and give zero.
+func Parse(ifMatch string) (int, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		return 0, ErrInvalid
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, ErrInvalid
+	}
+
+	return version, nil
+}
@@ -0,0 +1,33 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		header  string
+		version int
+		err     error
+	}{
+		{header: "", version: 0},
+		{header: "*", version: 0},
+		{header: Format(3), version: 3},
+		{header: ` "12" `, version: 12},
+		{header: "3", err: ErrInvalid},
+		{header: `W/"3"`, err: ErrInvalid},
+		{header: `"0"`, err: ErrInvalid},
+		{header: `"abc"`, err: ErrInvalid},
+		{header: `"1", "2"`, err: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			version, err := Parse(tt.header)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
@@ -2,12 +2,10 @@ package event
 
 import (
 	"context"
-	"errors"
 	"github.com/Slava02/Involvio/internal/entity"
-	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
 	"github.com/Slava02/Involvio/internal/usecase"
 	"github.com/Slava02/Involvio/internal/usecase/commands"
-	"github.com/danielgtaylor/huma/v2"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"log/slog"
@@ -18,9 +16,11 @@ type IEventUseCase interface {
 	GetEvent(ctx context.Context, cmd commands.EventByIdCommand) (*entity.Event, error)
 	JoinEvent(ctx context.Context, cmd commands.JoinEventCommand) error
 	DeleteEvent(ctx context.Context, cmd commands.EventByIdCommand) error
+	RestoreEvent(ctx context.Context, cmd commands.RestoreEventCommand) (*entity.Event, error)
 }
 
 var _ IEventUseCase = (*usecase.EventUseCase)(nil)
+var _ IEventUseCase = (*usecase.AuditedEventUseCase)(nil)
 
 const tracerName = "event handler"
 
@@ -36,7 +36,7 @@ func (eh *EventHandler) CreateEvent(ctx context.Context, req *CreateEventRequest
 	const op = "Handler:CreateEvent"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -53,16 +53,13 @@ func (eh *EventHandler) CreateEvent(ctx context.Context, req *CreateEventRequest
 		Description: b.EventInfo.Description,
 		BeginDate:   b.EventInfo.BeginDate,
 		EndDate:     b.EventInfo.EndDate,
+		Timezone:    b.EventInfo.Timezone,
 		Tags:        b.EventInfo.Tags,
 	}
 
 	event, err := eh.eventUC.CreateEvent(ctx, cmd)
 	if err != nil {
-		switch {
-		default:
-			log.Error("couldn't join event: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't join event", err)
 	}
 
 	resp := ToEventOutputFromEntity(event)
@@ -74,7 +71,7 @@ func (eh *EventHandler) GetEvent(ctx context.Context, req *EventByIdRequest) (*E
 	const op = "Handler:JoinEvent"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -89,14 +86,7 @@ func (eh *EventHandler) GetEvent(ctx context.Context, req *EventByIdRequest) (*E
 
 	event, err := eh.eventUC.GetEvent(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrEventNotFound):
-			log.Info("couldn't get event: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't get event: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't get event", err)
 	}
 
 	resp := ToEventOutputFromEntity(event)
@@ -108,7 +98,7 @@ func (eh *EventHandler) JoinEvent(ctx context.Context, req *JoinEventRequest) (*
 	const op = "Handler:JoinEvent"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -125,17 +115,7 @@ func (eh *EventHandler) JoinEvent(ctx context.Context, req *JoinEventRequest) (*
 
 	err := eh.eventUC.JoinEvent(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrEventAlreadyExists):
-			log.Info("couldn't join event: ", err.Error())
-			return nil, huma.Error400BadRequest("user in event already exists")
-		case errors.Is(err, repository.ErrEventNotFound):
-			log.Info("couldn't join event: ", err.Error())
-			return nil, huma.Error404NotFound("space not found")
-		default:
-			log.Error("couldn't join event: ", err.Error())
-			return nil, huma.Error500InternalServerError("internal service error")
-		}
+		return nil, problem.From(log, "couldn't join event", err)
 	}
 
 	return &struct{}{}, nil
@@ -145,7 +125,7 @@ func (eh *EventHandler) DeleteEvent(ctx context.Context, req *EventByIdRequest)
 	const op = "Handler:DeleteEvent"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -160,15 +140,36 @@ func (eh *EventHandler) DeleteEvent(ctx context.Context, req *EventByIdRequest)
 
 	err := eh.eventUC.DeleteEvent(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrEventNotFound):
-			log.Info("couldn't delete event: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't delete event: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't delete event", err)
 	}
 
 	return &struct{}{}, nil
 }
+
+func (eh *EventHandler) RestoreEvent(ctx context.Context, req *RestoreEventRequest) (*EventResponse, error) {
+	const op = "Handler:RestoreEvent"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("event id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.RestoreEventCommand{
+		ID:      req.ID,
+		AdminID: req.Body.AdminId,
+	}
+
+	event, err := eh.eventUC.RestoreEvent(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't restore event", err)
+	}
+
+	resp := ToEventOutputFromEntity(event)
+
+	return resp, nil
+}
@@ -20,8 +20,9 @@ type (
 			EventInfo struct {
 				Name        string      `json:"name" example:"fun event" doc:"Event name"`
 				Description string      `json:"description" example:"enormously fun event" doc:"Event description"`
-				BeginDate   time.Time   `json:"beginDate" example:"2007-03-01T13:00:00" doc:"Event start date and time"`
-				EndDate     time.Time   `json:"endDate" example:"2007-03-01T13:00:00" doc:"Event end date and time"`
+				BeginDate   time.Time   `json:"beginDate" example:"2007-03-01T13:00:00+03:00" doc:"Event start date and time with offset"`
+				EndDate     time.Time   `json:"endDate" example:"2007-03-01T15:00:00+03:00" doc:"Event end date and time with offset"`
+				Timezone    string      `json:"timezone,omitempty" example:"Europe/Moscow" doc:"IANA timezone of the event, space timezone if omitted"`
 				Tags        entity.Tags `json:"tags" doc:"Tags for this event"`
 			}
 		}
@@ -38,6 +39,13 @@ type (
 		}
 	}
 
+	RestoreEventRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"event id"`
+		Body struct {
+			AdminId int `json:"adminId" example:"123" doc:"ID of space admin performing the action"`
+		}
+	}
+
 	EventResponse struct {
 		Body struct {
 			entity.Event
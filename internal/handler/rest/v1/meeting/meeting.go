@@ -0,0 +1,155 @@
+package meeting
+
+import (
+	"context"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+type IMeetingUseCase interface {
+	GetMeeting(ctx context.Context, cmd commands.MeetingByIdCommand) (*entity.Meeting, error)
+	AcceptMeeting(ctx context.Context, cmd commands.MeetingActionCommand) (*entity.Meeting, error)
+	DeclineMeeting(ctx context.Context, cmd commands.MeetingActionCommand) (*entity.Meeting, error)
+	CompleteMeeting(ctx context.Context, cmd commands.CompleteMeetingCommand) (*entity.Meeting, error)
+	ScheduleMeeting(ctx context.Context, cmd commands.ScheduleMeetingCommand) (*entity.Meeting, error)
+}
+
+var _ IMeetingUseCase = (*usecase.MeetingUseCase)(nil)
+var _ IMeetingUseCase = (*usecase.AuditedMeetingUseCase)(nil)
+
+const tracerName = "meeting handler"
+
+type MeetingHandler struct {
+	meetingUC IMeetingUseCase
+}
+
+func NewMeetingHandler(uc IMeetingUseCase) *MeetingHandler {
+	return &MeetingHandler{meetingUC: uc}
+}
+
+func (mh *MeetingHandler) GetMeeting(ctx context.Context, req *MeetingByIdRequest) (*MeetingResponse, error) {
+	const op = "Handler:GetMeeting"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", req.ID),
+	)
+	log.Debug(op)
+
+	meeting, err := mh.meetingUC.GetMeeting(ctx, commands.MeetingByIdCommand{ID: req.ID})
+	if err != nil {
+		return nil, problem.From(log, "couldn't get meeting", err)
+	}
+
+	return ToMeetingOutputFromEntity(meeting), nil
+}
+
+func (mh *MeetingHandler) AcceptMeeting(ctx context.Context, req *MeetingActionRequest) (*MeetingResponse, error) {
+	const op = "Handler:AcceptMeeting"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", req.ID),
+		slog.Int("user id", req.Body.UserId),
+	)
+	log.Debug(op)
+
+	meeting, err := mh.meetingUC.AcceptMeeting(ctx, commands.MeetingActionCommand{MeetingID: req.ID, UserID: req.Body.UserId})
+	if err != nil {
+		return nil, problem.From(log, "couldn't accept meeting", err)
+	}
+
+	return ToMeetingOutputFromEntity(meeting), nil
+}
+
+func (mh *MeetingHandler) DeclineMeeting(ctx context.Context, req *MeetingActionRequest) (*MeetingResponse, error) {
+	const op = "Handler:DeclineMeeting"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", req.ID),
+		slog.Int("user id", req.Body.UserId),
+	)
+	log.Debug(op)
+
+	meeting, err := mh.meetingUC.DeclineMeeting(ctx, commands.MeetingActionCommand{MeetingID: req.ID, UserID: req.Body.UserId})
+	if err != nil {
+		return nil, problem.From(log, "couldn't decline meeting", err)
+	}
+
+	return ToMeetingOutputFromEntity(meeting), nil
+}
+
+func (mh *MeetingHandler) CompleteMeeting(ctx context.Context, req *CompleteMeetingRequest) (*MeetingResponse, error) {
+	const op = "Handler:CompleteMeeting"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", req.ID),
+		slog.Int("user id", req.Body.UserId),
+	)
+	log.Debug(op)
+
+	cmd := commands.CompleteMeetingCommand{
+		MeetingID: req.ID,
+		UserID:    req.Body.UserId,
+		NoShow:    req.Body.Outcome == string(entity.MeetingNoShow),
+		Absent:    req.Body.AbsentIds,
+	}
+
+	meeting, err := mh.meetingUC.CompleteMeeting(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't complete meeting", err)
+	}
+
+	return ToMeetingOutputFromEntity(meeting), nil
+}
+
+func (mh *MeetingHandler) ScheduleMeeting(ctx context.Context, req *ScheduleMeetingRequest) (*MeetingResponse, error) {
+	const op = "Handler:ScheduleMeeting"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", req.ID),
+		slog.Int("user id", req.Body.UserId),
+	)
+	log.Debug(op)
+
+	cmd := commands.ScheduleMeetingCommand{
+		MeetingID: req.ID,
+		UserID:    req.Body.UserId,
+		At:        req.Body.At,
+	}
+
+	meeting, err := mh.meetingUC.ScheduleMeeting(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't schedule meeting", err)
+	}
+
+	return ToMeetingOutputFromEntity(meeting), nil
+}
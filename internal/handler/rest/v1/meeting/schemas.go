@@ -0,0 +1,45 @@
+package meeting
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"time"
+)
+
+// Converters
+func ToMeetingOutputFromEntity(meeting *entity.Meeting) *MeetingResponse {
+	return &MeetingResponse{Body: meeting}
+}
+
+type (
+	MeetingByIdRequest struct {
+		ID int `path:"id" maxLength:"30" example:"1" doc:"meeting id"`
+	}
+
+	MeetingActionRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"meeting id"`
+		Body struct {
+			UserId int `json:"userId" example:"123" doc:"ID of participant"`
+		}
+	}
+
+	CompleteMeetingRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"meeting id"`
+		Body struct {
+			UserId    int    `json:"userId" example:"123" doc:"ID of participant reporting the outcome"`
+			Outcome   string `json:"outcome" enum:"held,no_show" example:"held" doc:"held confirms the meeting took place, no_show reports absent participants, who are no-shows once every other participant reported them"`
+			AbsentIds []int  `json:"absentIds,omitempty" required:"false" doc:"Participants who didn't come, every other participant if omitted"`
+		}
+	}
+
+	ScheduleMeetingRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"meeting id"`
+		Body struct {
+			UserId int       `json:"userId" example:"123" doc:"ID of participant"`
+			At     time.Time `json:"at" example:"2026-10-21T15:00:00Z" doc:"Meeting time, one of proposed slots if there are any"`
+		}
+	}
+
+	MeetingResponse struct {
+		Body *entity.Meeting
+	}
+)
@@ -0,0 +1,73 @@
+package mentorship
+
+import (
+	"context"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+type IMentorshipUseCase interface {
+	GetMentorships(ctx context.Context, cmd commands.MentorshipsCommand) ([]*entity.Mentorship, error)
+	EndMentorship(ctx context.Context, cmd commands.EndMentorshipCommand) (*entity.Mentorship, error)
+}
+
+var _ IMentorshipUseCase = (*usecase.MentorshipUseCase)(nil)
+var _ IMentorshipUseCase = (*usecase.AuditedMentorshipUseCase)(nil)
+
+const tracerName = "mentorship handler"
+
+type MentorshipHandler struct {
+	mentorshipUC IMentorshipUseCase
+}
+
+func NewMentorshipHandler(uc IMentorshipUseCase) *MentorshipHandler {
+	return &MentorshipHandler{mentorshipUC: uc}
+}
+
+func (mh *MentorshipHandler) GetMentorships(ctx context.Context, req *MentorshipsRequest) (*MentorshipsResponse, error) {
+	const op = "Handler:GetMentorships"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	mentorships, err := mh.mentorshipUC.GetMentorships(ctx, commands.MentorshipsCommand{SpaceID: req.ID, WithEnded: req.Ended})
+	if err != nil {
+		return nil, problem.From(log, "couldn't get mentorships", err)
+	}
+
+	return ToMentorshipsOutputFromEntity(mentorships), nil
+}
+
+func (mh *MentorshipHandler) EndMentorship(ctx context.Context, req *EndMentorshipRequest) (*MentorshipResponse, error) {
+	const op = "Handler:EndMentorship"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("mentorship id", req.ID),
+		slog.Int("user id", req.Body.UserId),
+	)
+	log.Debug(op)
+
+	mentorship, err := mh.mentorshipUC.EndMentorship(ctx, commands.EndMentorshipCommand{ID: req.ID, UserID: req.Body.UserId})
+	if err != nil {
+		return nil, problem.From(log, "couldn't end mentorship", err)
+	}
+
+	return ToMentorshipOutputFromEntity(mentorship), nil
+}
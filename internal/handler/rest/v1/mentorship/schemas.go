@@ -0,0 +1,38 @@
+package mentorship
+
+import "github.com/Slava02/Involvio/internal/entity"
+
+// Converters
+func ToMentorshipOutputFromEntity(mentorship *entity.Mentorship) *MentorshipResponse {
+	return &MentorshipResponse{Body: mentorship}
+}
+
+func ToMentorshipsOutputFromEntity(mentorships []*entity.Mentorship) *MentorshipsResponse {
+	resp := &MentorshipsResponse{}
+	resp.Body.Mentorships = mentorships
+	return resp
+}
+
+type (
+	MentorshipsRequest struct {
+		ID    int  `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		Ended bool `query:"ended" doc:"Include ended mentorships"`
+	}
+
+	EndMentorshipRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"mentorship id"`
+		Body struct {
+			UserId int `json:"userId" example:"123" doc:"ID of mentor, mentee or space admin"`
+		}
+	}
+
+	MentorshipResponse struct {
+		Body *entity.Mentorship
+	}
+
+	MentorshipsResponse struct {
+		Body struct {
+			Mentorships []*entity.Mentorship `json:"mentorships" doc:"Mentorships of the space, oldest first"`
+		}
+	}
+)
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Slava02/Involvio/pkg/idempotency"
+	"github.com/Slava02/Involvio/pkg/reqctx"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const (
+	// HeaderIdempotencyKey lets clients retry create requests safely.
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// HeaderIdempotentReplayed marks responses replayed from the store.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+)
+
+// replayedHeaders are the only headers stored with response.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Idempotency replays stored response to retried requests with the same Idempotency-Key.
+// Keys are scoped by operation and acting user, or by IP of anonymous clients.
+type Idempotency struct {
+	store      idempotency.Store
+	ttl        time.Duration
+	operations map[string]bool
+}
+
+func NewIdempotency(store idempotency.Store, ttl time.Duration, operations ...string) *Idempotency {
+	ops := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		ops[op] = true
+	}
+
+	return &Idempotency{store: store, ttl: ttl, operations: ops}
+}
+
+// Middleware must follow RequestContext, it scopes keys by the actor.
+func (i *Idempotency) Middleware(api huma.API) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		operation := ctx.Operation().OperationID
+
+		header := ctx.Header(HeaderIdempotencyKey)
+		if header == "" || !i.operations[operation] {
+			next(ctx)
+			return
+		}
+
+		if len(header) > maxIdempotencyKeyLen {
+			_ = huma.WriteErr(api, ctx, http.StatusBadRequest, "idempotency key is longer than "+strconv.Itoa(maxIdempotencyKeyLen))
+			return
+		}
+
+		log := slog.With(
+			slog.String("operation", operation),
+			slog.String("idempotency key", header),
+		)
+
+		body, err := io.ReadAll(ctx.BodyReader())
+		if err != nil {
+			_ = huma.WriteErr(api, ctx, http.StatusBadRequest, "couldn't read body")
+			return
+		}
+
+		// anonymous keys are scoped by IP, so clients can't replay each other's responses
+		key := operation + ":ip:" + clientIP(ctx) + ":" + header
+		if userID, ok := reqctx.Actor(ctx.Context()); ok {
+			key = operation + ":user:" + strconv.Itoa(userID) + ":" + header
+		}
+		fingerprint := idempotency.Fingerprint(ctx.Method(), ctx.URL().Path, body)
+
+		rec, claimed, err := i.store.Claim(ctx.Context(), key, fingerprint, i.ttl)
+		if err != nil {
+			log.Error("couldn't claim idempotency key", slog.String("error", err.Error()))
+			_ = huma.WriteErr(api, ctx, http.StatusInternalServerError, "internal service error")
+			return
+		}
+
+		if !claimed {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				_ = huma.WriteErr(api, ctx, http.StatusUnprocessableEntity, "idempotency key is used with another request")
+			case rec.Response == nil:
+				_ = huma.WriteErr(api, ctx, http.StatusConflict, "request with this idempotency key is in progress")
+			default:
+				replay(ctx, rec.Response)
+			}
+			return
+		}
+
+		c := &capture{humaContext: ctx, body: body, header: http.Header{}}
+		next(c)
+
+		// failed requests may succeed on retry
+		if c.Status() >= http.StatusInternalServerError {
+			if err := i.store.Release(ctx.Context(), key); err != nil {
+				log.Error("couldn't release idempotency key", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		resp := idempotency.Response{Status: c.Status(), Header: c.header, Body: c.out.Bytes()}
+		if err := i.store.Complete(ctx.Context(), key, resp); err != nil {
+			log.Error("couldn't store idempotent response", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func replay(ctx huma.Context, resp *idempotency.Response) {
+	for name, values := range resp.Header {
+		for i, v := range values {
+			if i == 0 {
+				ctx.SetHeader(name, v)
+			} else {
+				ctx.AppendHeader(name, v)
+			}
+		}
+	}
+	ctx.SetHeader(HeaderIdempotentReplayed, "true")
+	ctx.SetStatus(resp.Status)
+	_, _ = ctx.BodyWriter().Write(resp.Body)
+}
+
+// humaContext is embedded under its own name, field Context would hide method Context.
+type humaContext = huma.Context
+
+// capture records response and serves body read by the middleware again.
+type capture struct {
+	humaContext
+	body   []byte
+	header http.Header
+	out    bytes.Buffer
+}
+
+func (c *capture) BodyReader() io.Reader {
+	return bytes.NewReader(c.body)
+}
+
+func (c *capture) SetHeader(name, value string) {
+	if replayed(name) {
+		c.header.Set(name, value)
+	}
+	c.humaContext.SetHeader(name, value)
+}
+
+func (c *capture) AppendHeader(name, value string) {
+	if replayed(name) {
+		c.header.Add(name, value)
+	}
+	c.humaContext.AppendHeader(name, value)
+}
+
+func (c *capture) BodyWriter() io.Writer {
+	return io.MultiWriter(c.humaContext.BodyWriter(), &c.out)
+}
+
+func replayed(name string) bool {
+	for _, h := range replayedHeaders {
+		if http.CanonicalHeaderKey(name) == h {
+			return true
+		}
+	}
+
+	return false
+}
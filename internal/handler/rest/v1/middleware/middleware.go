@@ -1,5 +1,67 @@
-package main
+// Package middleware contains huma middlewares shared by all handlers.
+package middleware
 
-func main() {
+import (
+	"strconv"
+	"time"
 
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"github.com/Slava02/Involvio/pkg/reqctx"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// HeaderUserID identifies the user performing the request.
+	HeaderUserID = "X-User-Id"
+
+	// requestIDLocal is where fiber requestid middleware stores generated id.
+	requestIDLocal = "requestid"
+)
+
+// RequestContext puts acting user and request id into handler context.
+func RequestContext(ctx huma.Context, next func(huma.Context)) {
+	c := ctx.Context()
+
+	if id, ok := c.Value(requestIDLocal).(string); ok {
+		c = reqctx.WithRequestID(c, id)
+	}
+
+	if userID, err := strconv.Atoi(ctx.Header(HeaderUserID)); err == nil {
+		c = reqctx.WithActor(c, userID)
+	}
+
+	next(huma.WithContext(ctx, c))
+}
+
+// Metrics observes latency and response status of every operation.
+func Metrics(ctx huma.Context, next func(huma.Context)) {
+	start := time.Now()
+
+	next(ctx)
+
+	operation := ctx.Operation().OperationID
+	metrics.RequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	metrics.Responses.WithLabelValues(operation, strconv.Itoa(ctx.Status())).Inc()
+}
+
+// spanLocal keeps span started by otelfiber, huma handlers don't see fiber user context.
+type spanLocal struct{}
+
+// StoreSpan must follow otelfiber middleware, it exposes the request span to Trace.
+func StoreSpan(c *fiber.Ctx) error {
+	c.Locals(spanLocal{}, trace.SpanFromContext(c.UserContext()))
+	return c.Next()
+}
+
+// Trace makes handler spans children of the request span stored by StoreSpan.
+func Trace(ctx huma.Context, next func(huma.Context)) {
+	span, ok := ctx.Context().Value(spanLocal{}).(trace.Span)
+	if !ok {
+		next(ctx)
+		return
+	}
+
+	next(huma.WithContext(ctx, trace.ContextWithSpan(ctx.Context(), span)))
 }
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const HeaderIfMatch = "If-Match"
+
+// RequireIfMatch rejects updates without If-Match, so clients can't overwrite
+// changes they haven't seen. Updates are unconditional when it isn't installed.
+type RequireIfMatch struct {
+	operations map[string]bool
+}
+
+func NewRequireIfMatch(operations ...string) *RequireIfMatch {
+	ops := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		ops[op] = true
+	}
+
+	return &RequireIfMatch{operations: ops}
+}
+
+func (r *RequireIfMatch) Middleware(api huma.API) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if r.operations[ctx.Operation().OperationID] && ctx.Header(HeaderIfMatch) == "" {
+			_ = huma.WriteErr(api, ctx, http.StatusPreconditionRequired, "If-Match header with ETag of the object is required")
+			return
+		}
+
+		next(ctx)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"github.com/Slava02/Involvio/pkg/ratelimit"
+	"github.com/Slava02/Involvio/pkg/reqctx"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// RateLimiter limits operations per IP and also per acting user if the request
+// names one. The actor comes from an unauthenticated header, so it never
+// replaces the IP limit. It is a huma middleware rather than a fiber one to
+// know the operation.
+type RateLimiter struct {
+	store  ratelimit.Store
+	limits ratelimit.Limits
+}
+
+func NewRateLimiter(store ratelimit.Store, limits ratelimit.Limits) *RateLimiter {
+	return &RateLimiter{store: store, limits: limits}
+}
+
+// Middleware must follow RequestContext, it charges the actor too.
+func (l *RateLimiter) Middleware(api huma.API) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		operation := ctx.Operation().OperationID
+
+		limit := l.limits.For(operation)
+		if limit.Unlimited() {
+			next(ctx)
+			return
+		}
+
+		for _, key := range keys(ctx, operation) {
+			allowed, retryAfter, err := l.store.Take(ctx.Context(), key, limit)
+			if err != nil {
+				// limiter outage must not take the API down with it
+				slog.Error("couldn't take rate limit token",
+					slog.String("operation", operation),
+					slog.String("error", err.Error()),
+				)
+				break
+			}
+
+			if !allowed {
+				metrics.RateLimited.WithLabelValues(operation).Inc()
+
+				ctx.SetHeader("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				_ = huma.WriteErr(api, ctx, http.StatusTooManyRequests, "rate limit exceeded, retry later")
+				return
+			}
+		}
+
+		next(ctx)
+	}
+}
+
+// keys returns buckets the request is charged to, the IP one always goes first.
+func keys(ctx huma.Context, operation string) []string {
+	keys := []string{operation + ":ip:" + clientIP(ctx)}
+	if userID, ok := reqctx.Actor(ctx.Context()); ok {
+		keys = append(keys, operation+":user:"+strconv.Itoa(userID))
+	}
+
+	return keys
+}
+
+func clientIP(ctx huma.Context) string {
+	host, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		return ctx.RemoteAddr()
+	}
+
+	return host
+}
@@ -0,0 +1,36 @@
+// Package patch describes JSON merge patch (RFC 7396) request bodies.
+package patch
+
+import "github.com/danielgtaylor/huma/v2"
+
+// Object is schema of a merge patch of an object with the given fields. Every
+// field is optional and null removes it, unknown fields are rejected.
+func Object(description string, fields map[string]*huma.Schema) *huma.Schema {
+	for _, s := range fields {
+		s.Nullable = true
+	}
+
+	return &huma.Schema{
+		Type:                 huma.TypeObject,
+		Description:          description,
+		Properties:           fields,
+		AdditionalProperties: false,
+	}
+}
+
+// String is schema of a string field.
+func String(description string) *huma.Schema {
+	return &huma.Schema{Type: huma.TypeString, Description: description}
+}
+
+// Tags is schema of entity.Tags field. An array replaces all tags, an object
+// sets tags by name and null in it removes a tag.
+func Tags(description string) *huma.Schema {
+	return &huma.Schema{
+		Description: description + ". Array replaces all tags, object patches single tags by name",
+		OneOf: []*huma.Schema{
+			{Type: huma.TypeArray, Items: &huma.Schema{Type: huma.TypeObject, AdditionalProperties: true}},
+			{Type: huma.TypeObject, AdditionalProperties: true},
+		},
+	}
+}
@@ -0,0 +1,92 @@
+// Package problem renders errors as RFC 7807 problem details with stable codes.
+package problem
+
+import (
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/danielgtaylor/huma/v2"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const codeInternal = "internal"
+
+// Problem is huma error model extended with machine-readable code.
+type Problem struct {
+	huma.ErrorModel
+	Code string `json:"code" example:"space.not_found" doc:"Stable machine-readable error code"`
+}
+
+// New -.
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		ErrorModel: huma.ErrorModel{
+			Title:  http.StatusText(status),
+			Status: status,
+			Detail: detail,
+		},
+		Code: code,
+	}
+}
+
+// From maps err to problem response. Domain errors keep their code and message,
+// anything else becomes opaque internal error. err itself is only logged.
+func From(log *slog.Logger, msg string, err error) error {
+	e, ok := errs.As(err)
+	if !ok || e.Kind == errs.Internal {
+		log.Error(msg, slog.String("error", err.Error()))
+		return New(http.StatusInternalServerError, codeInternal, "internal service error")
+	}
+
+	log.Info(msg, slog.String("error", err.Error()))
+
+	return New(status(e.Kind), e.Code, e.Message)
+}
+
+func status(kind errs.Kind) int {
+	switch kind {
+	case errs.Invalid:
+		return http.StatusBadRequest
+	case errs.NotFound:
+		return http.StatusNotFound
+	case errs.Conflict:
+		return http.StatusConflict
+	case errs.Forbidden:
+		return http.StatusForbidden
+	case errs.Precondition:
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Install replaces huma error constructor, so errors huma produces itself,
+// e.g. on request validation, are problems with code too.
+func Install() {
+	huma.NewError = newError
+}
+
+func newError(status int, msg string, errList ...error) huma.StatusError {
+	if status >= http.StatusInternalServerError {
+		return New(status, codeInternal, "internal service error")
+	}
+
+	p := New(status, statusCode(status), msg)
+	for _, err := range errList {
+		if err == nil {
+			continue
+		}
+		if d, ok := err.(huma.ErrorDetailer); ok {
+			p.Errors = append(p.Errors, d.ErrorDetail())
+		} else {
+			p.Errors = append(p.Errors, &huma.ErrorDetail{Message: err.Error()})
+		}
+	}
+
+	return p
+}
+
+// statusCode is code of errors without domain meaning, e.g. "unprocessable_entity".
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
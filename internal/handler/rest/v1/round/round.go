@@ -0,0 +1,186 @@
+package round
+
+import (
+	"context"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
+	"github.com/Slava02/Involvio/internal/usecase"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+type IRoundUseCase interface {
+	CreateRound(ctx context.Context, cmd commands.CreateRoundCommand) (*entity.Round, error)
+	GetRound(ctx context.Context, cmd commands.RoundByIdCommand) (*entity.Round, error)
+	ReplayRound(ctx context.Context, cmd commands.ReplayRoundCommand) (*entity.RoundReplay, error)
+	PreviewRound(ctx context.Context, cmd commands.CreateRoundCommand) (*entity.RoundPreview, error)
+	OverridePreview(ctx context.Context, cmd commands.OverridePreviewCommand) (*entity.RoundPreview, error)
+	PublishPreview(ctx context.Context, cmd commands.PublishPreviewCommand) (*entity.Round, error)
+}
+
+var _ IRoundUseCase = (*usecase.RoundUseCase)(nil)
+var _ IRoundUseCase = (*usecase.AuditedRoundUseCase)(nil)
+
+const tracerName = "round handler"
+
+type RoundHandler struct {
+	roundUC IRoundUseCase
+}
+
+func NewRoundHandler(uc IRoundUseCase) *RoundHandler {
+	return &RoundHandler{roundUC: uc}
+}
+
+func (rh *RoundHandler) CreateRound(ctx context.Context, req *CreateRoundRequest) (*RoundResponse, error) {
+	const op = "Handler:CreateRound"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.CreateRoundCommand{
+		SpaceID: req.ID,
+		AdminID: req.Body.AdminId,
+	}
+
+	round, err := rh.roundUC.CreateRound(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't create round", err)
+	}
+
+	return ToRoundOutputFromEntity(round), nil
+}
+
+func (rh *RoundHandler) GetRound(ctx context.Context, req *RoundByIdRequest) (*RoundResponse, error) {
+	const op = "Handler:GetRound"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", req.ID),
+	)
+	log.Debug(op)
+
+	round, err := rh.roundUC.GetRound(ctx, commands.RoundByIdCommand{ID: req.ID})
+	if err != nil {
+		return nil, problem.From(log, "couldn't get round", err)
+	}
+
+	return ToRoundOutputFromEntity(round), nil
+}
+
+func (rh *RoundHandler) PreviewRound(ctx context.Context, req *CreateRoundRequest) (*PreviewResponse, error) {
+	const op = "Handler:PreviewRound"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.CreateRoundCommand{
+		SpaceID: req.ID,
+		AdminID: req.Body.AdminId,
+	}
+
+	preview, err := rh.roundUC.PreviewRound(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't preview round", err)
+	}
+
+	return &PreviewResponse{Body: preview}, nil
+}
+
+func (rh *RoundHandler) OverridePreview(ctx context.Context, req *OverridePreviewRequest) (*PreviewResponse, error) {
+	const op = "Handler:OverridePreview"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.String("preview id", req.PreviewID),
+	)
+	log.Debug(op)
+
+	cmd := commands.OverridePreviewCommand{
+		SpaceID:   req.ID,
+		PreviewID: req.PreviewID,
+		AdminID:   req.Body.AdminId,
+		Kind:      req.Body.Kind,
+		UserIDs:   req.Body.UserIds,
+	}
+
+	preview, err := rh.roundUC.OverridePreview(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't override round preview", err)
+	}
+
+	return &PreviewResponse{Body: preview}, nil
+}
+
+func (rh *RoundHandler) PublishPreview(ctx context.Context, req *PublishPreviewRequest) (*RoundResponse, error) {
+	const op = "Handler:PublishPreview"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.String("preview id", req.PreviewID),
+	)
+	log.Debug(op)
+
+	cmd := commands.PublishPreviewCommand{
+		SpaceID:   req.ID,
+		PreviewID: req.PreviewID,
+		AdminID:   req.Body.AdminId,
+	}
+
+	round, err := rh.roundUC.PublishPreview(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't publish round preview", err)
+	}
+
+	return ToRoundOutputFromEntity(round), nil
+}
+
+func (rh *RoundHandler) ReplayRound(ctx context.Context, req *ReplayRoundRequest) (*ReplayResponse, error) {
+	const op = "Handler:ReplayRound"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", req.ID),
+	)
+	log.Debug(op)
+
+	replay, err := rh.roundUC.ReplayRound(ctx, commands.ReplayRoundCommand{RoundID: req.ID, AdminID: req.AdminId})
+	if err != nil {
+		return nil, problem.From(log, "couldn't replay round", err)
+	}
+
+	return &ReplayResponse{Body: replay}, nil
+}
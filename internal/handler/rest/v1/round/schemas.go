@@ -0,0 +1,56 @@
+package round
+
+import "github.com/Slava02/Involvio/internal/entity"
+
+// Converters
+func ToRoundOutputFromEntity(round *entity.Round) *RoundResponse {
+	return &RoundResponse{Body: round}
+}
+
+type (
+	CreateRoundRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		Body struct {
+			AdminId int `json:"adminId" example:"123" doc:"ID of space admin running the round"`
+		}
+	}
+
+	PublishPreviewRequest struct {
+		ID        int    `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		PreviewID string `path:"previewId" format:"uuid" example:"0b5f3c1e-7d2a-4c8e-9a61-2f4d8e7b1c90" doc:"round preview id"`
+		Body      struct {
+			AdminId int `json:"adminId" example:"123" doc:"ID of space admin publishing the round"`
+		}
+	}
+
+	OverridePreviewRequest struct {
+		ID        int    `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		PreviewID string `path:"previewId" format:"uuid" example:"0b5f3c1e-7d2a-4c8e-9a61-2f4d8e7b1c90" doc:"round preview id"`
+		Body      struct {
+			AdminId int                 `json:"adminId" example:"123" doc:"ID of space admin overriding the preview"`
+			Kind    entity.OverrideKind `json:"kind" enum:"pin,forbid,swap" example:"pin" doc:"pin makes members meet, forbid keeps them apart, swap exchanges them between their meetings"`
+			UserIds []int               `json:"userIds" minItems:"2" maxItems:"2" doc:"Two members the override is about"`
+		}
+	}
+
+	RoundByIdRequest struct {
+		ID int `path:"id" maxLength:"30" example:"12" doc:"round id"`
+	}
+
+	ReplayRoundRequest struct {
+		ID      int `path:"id" maxLength:"30" example:"12" doc:"round id"`
+		AdminId int `query:"adminId" required:"true" example:"123" doc:"ID of space admin replaying the round"`
+	}
+
+	RoundResponse struct {
+		Body *entity.Round
+	}
+
+	ReplayResponse struct {
+		Body *entity.RoundReplay
+	}
+
+	PreviewResponse struct {
+		Body *entity.RoundPreview
+	}
+)
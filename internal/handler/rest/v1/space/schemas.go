@@ -1,14 +1,48 @@
 package space
 
-import "github.com/Slava02/Involvio/internal/entity"
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/etag"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/patch"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// SpacePatch is RFC 7396 merge patch of a space.
+type SpacePatch map[string]any
+
+func (SpacePatch) Schema(huma.Registry) *huma.Schema {
+	return patch.Object("Merge patch of space", map[string]*huma.Schema{
+		"name":        patch.String("Space name"),
+		"description": patch.String("Space description"),
+		"tags":        patch.Tags("Tags options for this space"),
+	})
+}
 
 // Converters
 func ToSpaceOutputFromEntity(space *entity.Space) *SpaceResponse {
 	return &SpaceResponse{
+		ETag: etag.Format(space.Version),
 		Body: struct{ entity.Space }{*space},
 	}
 }
 
+func ToSettingsOutputFromEntity(settings *entity.SpaceSettingsVersion) *SettingsResponse {
+	return &SettingsResponse{
+		ETag: etag.Format(settings.Version),
+		Body: settings,
+	}
+}
+
+func ToMembersOutputFromEntity(members []*entity.Member, total, limit, offset int) *MembersResponse {
+	resp := &MembersResponse{}
+	resp.Body.Members = members
+	resp.Body.Total = total
+	resp.Body.Limit = limit
+	resp.Body.Offset = offset
+
+	return resp
+}
+
 type (
 	JoinSpaceRequest struct {
 		Body struct {
@@ -19,28 +53,134 @@ type (
 
 	CreateSpaceRequest struct {
 		Body struct {
-			UserId      int         `json:"userId" example:"123" doc:"User ID"`
-			Name        string      `json:"name" example:"MAI" doc:"Space Name"`
-			Description string      `json:"description" example:"university" doc:"Space description"`
-			Tags        entity.Tags `json:"tags" doc:"Tags options for this space"`
+			UserId       int         `json:"userId" example:"123" doc:"User ID"`
+			Name         string      `json:"name" example:"MAI" doc:"Space Name"`
+			Description  string      `json:"description" example:"university" doc:"Space description"`
+			Timezone     string      `json:"timezone,omitempty" example:"Europe/Moscow" doc:"IANA timezone of the space, UTC if omitted"`
+			JoinApproval bool        `json:"joinApproval,omitempty" example:"false" doc:"Joining requires admin approval"`
+			Tags         entity.Tags `json:"tags" doc:"Tags options for this space"`
 		}
 	}
 
 	UpdateSpaceRequest struct {
-		ID   int `path:"id" maxLength:"30" example:"1" doc:"space id"`
-		Body struct {
-			Name        string `json:"name" example:"MAI" doc:"Space Name"`
-			Description string `json:"description" example:"university" doc:"Space description"`
+		ID      int    `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the space the update is based on"`
+		Body    struct {
+			Name        string      `json:"name" example:"MAI" doc:"Space Name"`
+			Description string      `json:"description" example:"university" doc:"Space description"`
+			Tags        entity.Tags `json:"tags,omitempty" required:"false" doc:"Tags options for this space, kept if omitted"`
 		}
 	}
 
+	PatchSpaceRequest struct {
+		ID      int    `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the space the patch is based on"`
+		Body    SpacePatch
+	}
+
 	SpaceByIdRequest struct {
 		ID int `path:"id" maxLength:"30" example:"1" doc:"space id"`
 	}
 
+	RestoreSpaceRequest struct {
+		ID   int `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		Body struct {
+			AdminId int `json:"adminId" example:"123" doc:"ID of admin performing the action"`
+		}
+	}
+
 	SpaceResponse struct {
+		ETag string `header:"ETag" doc:"Space version"`
 		Body struct {
 			entity.Space
 		}
 	}
+
+	JoinSpaceResponse struct {
+		Body struct {
+			Status entity.MemberStatus `json:"status" example:"pending" doc:"Membership status, pending if space requires approval"`
+		}
+	}
+
+	MembersRequest struct {
+		ID     int                 `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		Status entity.MemberStatus `query:"status" enum:"active,pending,banned" doc:"Filter members by status"`
+		Limit  int                 `query:"limit" minimum:"1" maximum:"100" default:"20" doc:"Page size"`
+		Offset int                 `query:"offset" minimum:"0" default:"0" doc:"Page offset"`
+	}
+
+	MemberRequest struct {
+		ID     int `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		UserID int `path:"userId" maxLength:"30" example:"1" doc:"member user id"`
+		Body   struct {
+			AdminId int `json:"adminId" example:"123" doc:"ID of admin performing the action"`
+		}
+	}
+
+	BanMemberRequest struct {
+		ID     int `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		UserID int `path:"userId" maxLength:"30" example:"1" doc:"member user id"`
+		Body   struct {
+			AdminId int    `json:"adminId" example:"123" doc:"ID of admin performing the action"`
+			Reason  string `json:"reason" maxLength:"500" example:"spam" doc:"Ban reason"`
+		}
+	}
+
+	SetMatchRoleRequest struct {
+		ID     int `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		UserID int `path:"userId" maxLength:"30" example:"1" doc:"member user id"`
+		Body   struct {
+			AdminId  int    `json:"adminId" example:"123" doc:"ID of admin performing the action"`
+			Role     string `json:"role" maxLength:"30" example:"mentor" doc:"Mentor or mentee role from space settings, empty clears it"`
+			Capacity int    `json:"capacity,omitempty" minimum:"0" maximum:"20" example:"2" doc:"Mentees the mentor has at once, space default if 0"`
+		}
+	}
+
+	MembersResponse struct {
+		Body struct {
+			Members []*entity.Member `json:"members" doc:"Space members page"`
+			Total   int              `json:"total" example:"42" doc:"Total number of members matching the filter"`
+			Limit   int              `json:"limit" example:"20" doc:"Page size"`
+			Offset  int              `json:"offset" example:"0" doc:"Page offset"`
+		}
+	}
+
+	ImportMembersRequest struct {
+		ID      int      `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		AdminId int      `query:"adminId" required:"true" example:"123" doc:"ID of admin performing the import"`
+		Mode    string   `query:"mode" enum:"dry-run,commit" default:"dry-run" doc:"dry-run only validates rows, commit adds all members in one transaction if every row is valid"`
+		Columns []string `query:"columns" example:"first_name=Name,username=Telegram" doc:"field=header pairs mapping id, first_name, last_name, username and photo_url to CSV columns, a field defaults to the column of the same name"`
+		Tags    []string `query:"tags" example:"department,city" doc:"CSV columns imported as user tags named after the column"`
+		RawBody []byte   `contentType:"text/csv" doc:"CSV with a header row"`
+	}
+
+	ImportMembersResponse struct {
+		Body *entity.MemberImport
+	}
+
+	ExportMembersRequest struct {
+		ID      int                 `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		AdminId int                 `query:"adminId" required:"true" example:"123" doc:"ID of admin performing the export"`
+		Status  entity.MemberStatus `query:"status" enum:"active,pending,banned" doc:"Filter members by status"`
+		Tags    []string            `query:"tags" example:"department,city" doc:"User tags exported as columns"`
+	}
+
+	SettingsRequest struct {
+		ID      int `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		Version int `query:"version" minimum:"0" example:"2" doc:"Settings version a round was run with, current settings if omitted"`
+	}
+
+	UpdateSettingsRequest struct {
+		ID      int    `path:"id" maxLength:"30" example:"1" doc:"space id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the settings the update is based on"`
+		Body    struct {
+			AdminId int `json:"adminId" example:"123" doc:"ID of admin performing the action"`
+			entity.SpaceSettings
+		}
+	}
+
+	SettingsResponse struct {
+		ETag string `header:"ETag" doc:"Settings version"`
+		Body *entity.SpaceSettingsVersion
+	}
 )
@@ -2,26 +2,42 @@ package space
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"github.com/Slava02/Involvio/internal/entity"
-	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/etag"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
 	"github.com/Slava02/Involvio/internal/usecase"
 	"github.com/Slava02/Involvio/internal/usecase/commands"
 	"github.com/danielgtaylor/huma/v2"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"io"
 	"log/slog"
+	"strings"
 )
 
 type ISpaceUseCase interface {
 	CreateSpace(ctx context.Context, cmd commands.CreateSpaceCommand) (*entity.Space, error)
 	GetSpace(ctx context.Context, cmd commands.SpaceByIdCommand) (*entity.Space, error)
-	JoinSpace(ctx context.Context, cmd commands.JoinSpaceCommand) error
+	JoinSpace(ctx context.Context, cmd commands.JoinSpaceCommand) (entity.MemberStatus, error)
 	UpdateSpace(ctx context.Context, cmd commands.UpdateSpaceCommand) (*entity.Space, error)
+	PatchSpace(ctx context.Context, cmd commands.PatchSpaceCommand) (*entity.Space, error)
 	DeleteSpace(ctx context.Context, cmd commands.SpaceByIdCommand) error
+	GetMembers(ctx context.Context, cmd commands.MembersCommand) ([]*entity.Member, int, error)
+	KickMember(ctx context.Context, cmd commands.MemberCommand) error
+	BanMember(ctx context.Context, cmd commands.BanMemberCommand) error
+	ApproveMember(ctx context.Context, cmd commands.MemberCommand) error
+	SetMatchRole(ctx context.Context, cmd commands.SetMatchRoleCommand) error
+	RestoreSpace(ctx context.Context, cmd commands.RestoreSpaceCommand) (*entity.Space, error)
+	RestoreMember(ctx context.Context, cmd commands.MemberCommand) error
+	GetSettings(ctx context.Context, cmd commands.SettingsCommand) (*entity.SpaceSettingsVersion, error)
+	UpdateSettings(ctx context.Context, cmd commands.UpdateSettingsCommand) (*entity.SpaceSettingsVersion, error)
+	ImportMembers(ctx context.Context, cmd commands.ImportMembersCommand) (*entity.MemberImport, error)
+	ExportMembers(ctx context.Context, cmd commands.ExportMembersCommand) (func(w io.Writer) error, error)
 }
 
 var _ ISpaceUseCase = (*usecase.SpaceUseCase)(nil)
+var _ ISpaceUseCase = (*usecase.AuditedSpaceUseCase)(nil)
 
 const tracerName = "space handler"
 
@@ -37,7 +53,7 @@ func (sh *SpaceHandler) CreateSpace(ctx context.Context, req *CreateSpaceRequest
 	const op = "Handler:CreateSpace"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -46,19 +62,17 @@ func (sh *SpaceHandler) CreateSpace(ctx context.Context, req *CreateSpaceRequest
 	log.Debug(op)
 
 	cmd := commands.CreateSpaceCommand{
-		UserID:      req.Body.UserId,
-		Name:        req.Body.Name,
-		Description: req.Body.Description,
-		Tags:        req.Body.Tags,
+		UserID:       req.Body.UserId,
+		Name:         req.Body.Name,
+		Description:  req.Body.Description,
+		Timezone:     req.Body.Timezone,
+		JoinApproval: req.Body.JoinApproval,
+		Tags:         req.Body.Tags,
 	}
 
 	space, err := sh.spaceUC.CreateSpace(ctx, cmd)
 	if err != nil {
-		switch {
-		default:
-			log.Error("couldn't create space: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't create space", err)
 	}
 
 	resp := ToSpaceOutputFromEntity(space)
@@ -70,7 +84,7 @@ func (sh *SpaceHandler) GetSpace(ctx context.Context, req *SpaceByIdRequest) (*S
 	const op = "Handler:GetSpace"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -85,14 +99,7 @@ func (sh *SpaceHandler) GetSpace(ctx context.Context, req *SpaceByIdRequest) (*S
 
 	space, err := sh.spaceUC.GetSpace(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrEventNotFound):
-			log.Info("couldn't get space: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't get space: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't get space", err)
 	}
 
 	resp := ToSpaceOutputFromEntity(space)
@@ -100,11 +107,11 @@ func (sh *SpaceHandler) GetSpace(ctx context.Context, req *SpaceByIdRequest) (*S
 	return resp, nil
 }
 
-func (sh *SpaceHandler) JoinSpace(ctx context.Context, req *JoinSpaceRequest) (*struct{}, error) {
+func (sh *SpaceHandler) JoinSpace(ctx context.Context, req *JoinSpaceRequest) (*JoinSpaceResponse, error) {
 	const op = "Handler:JoinSpace"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -119,29 +126,22 @@ func (sh *SpaceHandler) JoinSpace(ctx context.Context, req *JoinSpaceRequest) (*
 		UserID:  req.Body.UserId,
 	}
 
-	err := sh.spaceUC.JoinSpace(ctx, cmd)
+	status, err := sh.spaceUC.JoinSpace(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrSpaceAlreadyExists):
-			log.Info("couldn't join space: ", err.Error())
-			return nil, huma.Error400BadRequest("user in space already exists")
-		case errors.Is(err, repository.ErrSpaceNotFound):
-			log.Info("couldn't join space: ", err.Error())
-			return nil, huma.Error404NotFound("space not found")
-		default:
-			log.Error("couldn't join space: ", err.Error())
-			return nil, huma.Error500InternalServerError("internal service error")
-		}
+		return nil, problem.From(log, "couldn't join space", err)
 	}
 
-	return &struct{}{}, nil
+	resp := &JoinSpaceResponse{}
+	resp.Body.Status = status
+
+	return resp, nil
 }
 
 func (sh *SpaceHandler) UpdateSpace(ctx context.Context, req *UpdateSpaceRequest) (*SpaceResponse, error) {
 	const op = "Handler:UpdateSpace"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -150,22 +150,56 @@ func (sh *SpaceHandler) UpdateSpace(ctx context.Context, req *UpdateSpaceRequest
 	)
 	log.Debug(op)
 
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
 	cmd := commands.UpdateSpaceCommand{
 		ID:          req.ID,
 		Name:        req.Body.Name,
 		Description: req.Body.Description,
+		Tags:        req.Body.Tags,
+		Version:     version,
 	}
 
 	space, err := sh.spaceUC.UpdateSpace(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrEventNotFound):
-			log.Info("couldn't get space: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't get space: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't get space", err)
+	}
+
+	resp := ToSpaceOutputFromEntity(space)
+
+	return resp, nil
+}
+
+func (sh *SpaceHandler) PatchSpace(ctx context.Context, req *PatchSpaceRequest) (*SpaceResponse, error) {
+	const op = "Handler:PatchSpace"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
+	cmd := commands.PatchSpaceCommand{
+		ID:      req.ID,
+		Patch:   req.Body,
+		Version: version,
+	}
+
+	space, err := sh.spaceUC.PatchSpace(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't patch space", err)
 	}
 
 	resp := ToSpaceOutputFromEntity(space)
@@ -177,7 +211,7 @@ func (sh *SpaceHandler) DeleteSpace(ctx context.Context, req *SpaceByIdRequest)
 	const op = "Handler:DeleteSpace"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -192,15 +226,347 @@ func (sh *SpaceHandler) DeleteSpace(ctx context.Context, req *SpaceByIdRequest)
 
 	err := sh.spaceUC.DeleteSpace(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrEventNotFound):
-			log.Info("couldn't get space: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't get space: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't delete space", err)
+	}
+
+	return &struct{}{}, nil
+}
+
+func (sh *SpaceHandler) RestoreSpace(ctx context.Context, req *RestoreSpaceRequest) (*SpaceResponse, error) {
+	const op = "Handler:RestoreSpace"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.RestoreSpaceCommand{
+		ID:      req.ID,
+		AdminID: req.Body.AdminId,
+	}
+
+	space, err := sh.spaceUC.RestoreSpace(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't restore space", err)
+	}
+
+	resp := ToSpaceOutputFromEntity(space)
+
+	return resp, nil
+}
+
+func (sh *SpaceHandler) GetMembers(ctx context.Context, req *MembersRequest) (*MembersResponse, error) {
+	const op = "Handler:GetMembers"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.MembersCommand{
+		SpaceID: req.ID,
+		Status:  req.Status,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	}
+
+	members, total, err := sh.spaceUC.GetMembers(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't get members", err)
+	}
+
+	resp := ToMembersOutputFromEntity(members, total, req.Limit, req.Offset)
+
+	return resp, nil
+}
+
+func (sh *SpaceHandler) KickMember(ctx context.Context, req *MemberRequest) (*struct{}, error) {
+	const op = "Handler:KickMember"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.Int("user id", req.UserID),
+	)
+	log.Debug(op)
+
+	cmd := commands.MemberCommand{
+		SpaceID: req.ID,
+		UserID:  req.UserID,
+		AdminID: req.Body.AdminId,
+	}
+
+	err := sh.spaceUC.KickMember(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't kick member", err)
+	}
+
+	return &struct{}{}, nil
+}
+
+func (sh *SpaceHandler) BanMember(ctx context.Context, req *BanMemberRequest) (*struct{}, error) {
+	const op = "Handler:BanMember"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.Int("user id", req.UserID),
+	)
+	log.Debug(op)
+
+	cmd := commands.BanMemberCommand{
+		SpaceID: req.ID,
+		UserID:  req.UserID,
+		AdminID: req.Body.AdminId,
+		Reason:  req.Body.Reason,
+	}
+
+	err := sh.spaceUC.BanMember(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't ban member", err)
+	}
+
+	return &struct{}{}, nil
+}
+
+func (sh *SpaceHandler) SetMatchRole(ctx context.Context, req *SetMatchRoleRequest) (*struct{}, error) {
+	const op = "Handler:SetMatchRole"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.Int("user id", req.UserID),
+	)
+	log.Debug(op)
+
+	cmd := commands.SetMatchRoleCommand{
+		SpaceID:  req.ID,
+		UserID:   req.UserID,
+		AdminID:  req.Body.AdminId,
+		Role:     req.Body.Role,
+		Capacity: req.Body.Capacity,
+	}
+
+	err := sh.spaceUC.SetMatchRole(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't set match role", err)
 	}
 
 	return &struct{}{}, nil
 }
+
+func (sh *SpaceHandler) ApproveMember(ctx context.Context, req *MemberRequest) (*struct{}, error) {
+	const op = "Handler:ApproveMember"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.Int("user id", req.UserID),
+	)
+	log.Debug(op)
+
+	cmd := commands.MemberCommand{
+		SpaceID: req.ID,
+		UserID:  req.UserID,
+		AdminID: req.Body.AdminId,
+	}
+
+	err := sh.spaceUC.ApproveMember(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't approve member", err)
+	}
+
+	return &struct{}{}, nil
+}
+
+func (sh *SpaceHandler) RestoreMember(ctx context.Context, req *MemberRequest) (*struct{}, error) {
+	const op = "Handler:RestoreMember"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+		slog.Int("user id", req.UserID),
+	)
+	log.Debug(op)
+
+	cmd := commands.MemberCommand{
+		SpaceID: req.ID,
+		UserID:  req.UserID,
+		AdminID: req.Body.AdminId,
+	}
+
+	err := sh.spaceUC.RestoreMember(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't restore member", err)
+	}
+
+	return &struct{}{}, nil
+}
+
+func (sh *SpaceHandler) ImportMembers(ctx context.Context, req *ImportMembersRequest) (*ImportMembersResponse, error) {
+	const op = "Handler:ImportMembers"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	columns := make(map[string]string, len(req.Columns))
+	for _, pair := range req.Columns {
+		field, header, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, problem.From(log, "couldn't import members", fmt.Errorf("%w: %q is not field=header", usecase.ErrImportMapping, pair))
+		}
+		columns[strings.TrimSpace(field)] = strings.TrimSpace(header)
+	}
+
+	cmd := commands.ImportMembersCommand{
+		SpaceID:    req.ID,
+		AdminID:    req.AdminId,
+		CSV:        req.RawBody,
+		Columns:    columns,
+		TagColumns: req.Tags,
+		DryRun:     req.Mode != "commit",
+	}
+
+	report, err := sh.spaceUC.ImportMembers(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't import members", err)
+	}
+
+	return &ImportMembersResponse{Body: report}, nil
+}
+
+func (sh *SpaceHandler) ExportMembers(ctx context.Context, req *ExportMembersRequest) (*huma.StreamResponse, error) {
+	const op = "Handler:ExportMembers"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.ExportMembersCommand{
+		SpaceID:    req.ID,
+		AdminID:    req.AdminId,
+		Status:     req.Status,
+		TagColumns: req.Tags,
+	}
+
+	write, err := sh.spaceUC.ExportMembers(ctx, cmd)
+	if err != nil {
+		span.End()
+		return nil, problem.From(log, "couldn't export members", err)
+	}
+
+	return &huma.StreamResponse{
+		Body: func(hctx huma.Context) {
+			defer span.End()
+
+			hctx.SetHeader("Content-Type", "text/csv; charset=utf-8")
+			hctx.SetHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="space-%d-members.csv"`, req.ID))
+
+			// status is already sent, a failure can only cut the file short
+			if err := write(hctx.BodyWriter()); err != nil {
+				log.Error("couldn't write members", slog.String("error", err.Error()))
+			}
+		},
+	}, nil
+}
+
+func (sh *SpaceHandler) GetSettings(ctx context.Context, req *SettingsRequest) (*SettingsResponse, error) {
+	const op = "Handler:GetSettings"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.SettingsCommand{
+		SpaceID: req.ID,
+		Version: req.Version,
+	}
+
+	settings, err := sh.spaceUC.GetSettings(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't get settings", err)
+	}
+
+	return ToSettingsOutputFromEntity(settings), nil
+}
+
+func (sh *SpaceHandler) UpdateSettings(ctx context.Context, req *UpdateSettingsRequest) (*SettingsResponse, error) {
+	const op = "Handler:UpdateSettings"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", req.ID),
+	)
+	log.Debug(op)
+
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
+	cmd := commands.UpdateSettingsCommand{
+		SpaceID:  req.ID,
+		AdminID:  req.Body.AdminId,
+		Settings: req.Body.SpaceSettings,
+		Version:  version,
+	}
+
+	settings, err := sh.spaceUC.UpdateSettings(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't update settings", err)
+	}
+
+	return ToSettingsOutputFromEntity(settings), nil
+}
@@ -2,25 +2,61 @@ package user
 
 import (
 	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/etag"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/patch"
+	"github.com/danielgtaylor/huma/v2"
 )
 
 // USER
 
+// UserPatch is RFC 7396 merge patch of user profile.
+type UserPatch map[string]any
+
+func (UserPatch) Schema(huma.Registry) *huma.Schema {
+	return patch.Object("Merge patch of user", map[string]*huma.Schema{
+		"first_name": patch.String("User first name"),
+		"last_name":  patch.String("User last name"),
+		"username":   patch.String("Username"),
+		"photo_url":  patch.String("User photo url"),
+	})
+}
+
+// FormPatch is RFC 7396 merge patch of user form in space.
+type FormPatch map[string]any
+
+func (FormPatch) Schema(huma.Registry) *huma.Schema {
+	return patch.Object("Merge patch of form", map[string]*huma.Schema{
+		"user_tags": patch.Tags("User's tags"),
+		"pair_tags": patch.Tags("User's preference tags"),
+		"availability": patch.Object("Weekly availability for meetings, ranges are replaced as a whole", map[string]*huma.Schema{
+			"timezone": patch.String("IANA timezone of the ranges"),
+			"ranges": {
+				Type:        huma.TypeArray,
+				Description: "Weekly time ranges, e.g. {\"weekday\": \"monday\", \"start\": \"18:00\", \"end\": \"20:30\"}",
+				Items:       &huma.Schema{Type: huma.TypeObject, AdditionalProperties: true},
+			},
+		}),
+	})
+}
+
 // Converters
 func ToUserOutputFromEntity(user *entity.User) *UserResponse {
 	return &UserResponse{
+		ETag: etag.Format(user.Version),
 		Body: struct{ *entity.User }{user},
 	}
 }
 
 func ToFormOutputFromEntity(form *entity.Form) *FormResponse {
 	return &FormResponse{
+		ETag: etag.Format(form.Version),
 		Body: struct{ *entity.Form }{form},
 	}
 }
 
 func ToUserWithFormsOutputFromEntity(user *entity.User, forms []*entity.Form) *UserWithFormsResponse {
 	return &UserWithFormsResponse{
+		ETag: etag.Format(user.Version),
 		Body: struct {
 			*entity.User
 			Forms []*entity.Form
@@ -28,6 +64,12 @@ func ToUserWithFormsOutputFromEntity(user *entity.User, forms []*entity.Form) *U
 	}
 }
 
+func ToUserExportOutputFromEntity(export *entity.UserExport) *UserExportResponse {
+	return &UserExportResponse{
+		Body: struct{ *entity.UserExport }{export},
+	}
+}
+
 // Schemas
 type (
 	UserByIdRequest struct {
@@ -49,8 +91,9 @@ type (
 	}
 
 	UpdateUserRequest struct {
-		ID   int `path:"id" maxLength:"30" example:"1" doc:"user id"`
-		Body struct {
+		ID      int    `path:"id" maxLength:"30" example:"1" doc:"user id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the user the update is based on"`
+		Body    struct {
 			FirstName string `json:"first_name" example:"ivan" doc:"User first name"`
 			LastName  string `json:"last_name" example:"ivanov" doc:"User last nam"`
 			Username  string `json:"username" example:"ivanko228" doc:"Username"`
@@ -58,34 +101,59 @@ type (
 		}
 	}
 
+	PatchUserRequest struct {
+		ID      int    `path:"id" maxLength:"30" example:"1" doc:"user id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the user the patch is based on"`
+		Body    UserPatch
+	}
+
+	PatchFormRequest struct {
+		UserID  int    `path:"userId" maxLength:"30" example:"1" doc:"user id"`
+		SpaceID int    `path:"spaceId" maxLength:"30" example:"1" doc:"space id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the form the patch is based on"`
+		Body    FormPatch
+	}
+
 	FormByIdRequest struct {
 		UserID  int `path:"userId" maxLength:"30" example:"1" doc:"user id"`
 		SpaceID int `path:"spaceId" maxLength:"30" example:"1" doc:"space id"`
 	}
 
 	UpdateFormRequest struct {
-		UserID  int `path:"userId" maxLength:"30" example:"1" doc:"user id"`
-		SpaceID int `path:"spaceId" maxLength:"30" example:"1" doc:"space id"`
+		UserID  int    `path:"userId" maxLength:"30" example:"1" doc:"user id"`
+		SpaceID int    `path:"spaceId" maxLength:"30" example:"1" doc:"space id"`
+		IfMatch string `header:"If-Match" example:"\"1\"" doc:"ETag of the form the update is based on"`
 		Body    struct {
-			UserTags entity.Tags
-			PairTags entity.Tags
+			UserTags     entity.Tags
+			PairTags     entity.Tags
+			Availability *entity.Availability `json:"availability,omitempty" required:"false" doc:"Weekly availability for meetings, omitted clears it"`
 		}
 	}
 
 	UserResponse struct {
+		ETag string `header:"ETag" doc:"User version"`
 		Body struct {
 			*entity.User
 		}
 	}
 
 	UserWithFormsResponse struct {
+		// ETag is version of the user, or of the form after form update.
+		ETag string `header:"ETag" doc:"User or updated form version"`
 		Body struct {
 			*entity.User
 			Forms []*entity.Form
 		}
 	}
 
+	UserExportResponse struct {
+		Body struct {
+			*entity.UserExport
+		}
+	}
+
 	FormResponse struct {
+		ETag string `header:"ETag" doc:"Form version"`
 		Body struct {
 			*entity.Form
 		}
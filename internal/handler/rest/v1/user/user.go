@@ -2,17 +2,15 @@ package user
 
 import (
 	"context"
-	"errors"
 	"github.com/Slava02/Involvio/internal/entity"
-	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/etag"
+	"github.com/Slava02/Involvio/internal/handler/rest/v1/problem"
 	"github.com/Slava02/Involvio/internal/usecase"
 	"github.com/Slava02/Involvio/internal/usecase/commands"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"log/slog"
 	"time"
-
-	"github.com/danielgtaylor/huma/v2"
 )
 
 type IUserUseCase interface {
@@ -22,9 +20,14 @@ type IUserUseCase interface {
 	DeleteUser(ctx context.Context, cmd commands.FormByIdCommand) error
 	GetForm(ctx context.Context, cmd commands.FormByIdCommand) (*entity.Form, error)
 	UpdateForm(ctx context.Context, cmd commands.UpdateFormCommand) (*entity.User, []*entity.Form, error)
+	PatchUser(ctx context.Context, cmd commands.PatchUserCommand) (*entity.User, error)
+	PatchForm(ctx context.Context, cmd commands.PatchFormCommand) (*entity.User, []*entity.Form, error)
+	ExportUser(ctx context.Context, cmd commands.UserByIdCommand) (*entity.UserExport, error)
+	EraseUser(ctx context.Context, cmd commands.UserByIdCommand) error
 }
 
 var _ IUserUseCase = (*usecase.UserUseCase)(nil)
+var _ IUserUseCase = (*usecase.AuditedUserUseCase)(nil)
 
 const tracerName = "user handler"
 
@@ -40,7 +43,7 @@ func (uh *UserHandler) GetUserWithForms(ctx context.Context, req *UserByIdReques
 	const op = "Handler:GetUserWithForms"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -53,14 +56,7 @@ func (uh *UserHandler) GetUserWithForms(ctx context.Context, req *UserByIdReques
 
 	user, forms, err := uh.userUC.GetUser(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserNotFound):
-			log.Info("couldn't get user: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't get user: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't get user", err)
 	}
 
 	resp := ToUserWithFormsOutputFromEntity(user, forms)
@@ -72,7 +68,7 @@ func (uh *UserHandler) CreateUser(ctx context.Context, req *CreateUserRequest) (
 	const op = "Handler:CreateUser"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -90,17 +86,7 @@ func (uh *UserHandler) CreateUser(ctx context.Context, req *CreateUserRequest) (
 
 	user, err := uh.userUC.CreateUser(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserAlreadyExists):
-			log.Info("couldn't create user: ", err.Error())
-			return nil, huma.Error400BadRequest("user in space already exists")
-		case errors.Is(err, repository.ErrUserNotFound):
-			log.Info("couldn't create user: ", err.Error())
-			return nil, huma.Error404NotFound("space not found")
-		default:
-			log.Error("couldn't create user: ", err.Error())
-			return nil, huma.Error500InternalServerError("internal service error")
-		}
+		return nil, problem.From(log, "couldn't create user", err)
 	}
 
 	resp := ToUserOutputFromEntity(user)
@@ -112,7 +98,7 @@ func (uh *UserHandler) UpdateUser(ctx context.Context, req *UpdateUserRequest) (
 	const op = "Handler:UpdateUser"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -120,25 +106,58 @@ func (uh *UserHandler) UpdateUser(ctx context.Context, req *UpdateUserRequest) (
 	)
 	log.Debug(op)
 
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
 	cmd := commands.UpdateUserCommand{
 		ID:        req.ID,
 		FirstName: req.Body.FirstName,
 		LastName:  req.Body.LastName,
 		UserName:  req.Body.Username,
-		PhotoURL:  req.Body.Username,
+		PhotoURL:  req.Body.PhotoURL,
+		Version:   version,
 	}
 
 	user, err := uh.userUC.UpdateUser(ctx, cmd)
 
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserNotFound):
-			log.Info("couldn't update user: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't update user: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't update user", err)
+	}
+
+	resp := ToUserOutputFromEntity(user)
+
+	return resp, nil
+}
+
+func (uh *UserHandler) PatchUser(ctx context.Context, req *PatchUserRequest) (*UserResponse, error) {
+	const op = "Handler:PatchUser"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", req.ID),
+	)
+	log.Debug(op)
+
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
+	cmd := commands.PatchUserCommand{
+		ID:      req.ID,
+		Patch:   req.Body,
+		Version: version,
+	}
+
+	user, err := uh.userUC.PatchUser(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't patch user", err)
 	}
 
 	resp := ToUserOutputFromEntity(user)
@@ -150,7 +169,7 @@ func (uh *UserHandler) DeleteUser(ctx context.Context, req *DeleteUserRequest) (
 	const op = "Handler:DeleteUser"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -162,14 +181,7 @@ func (uh *UserHandler) DeleteUser(ctx context.Context, req *DeleteUserRequest) (
 
 	err := uh.userUC.DeleteUser(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserNotFound):
-			log.Info("couldn't delete user: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't delete user: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't delete user", err)
 	}
 
 	return &struct{}{}, nil
@@ -179,7 +191,7 @@ func (uh *UserHandler) GetForm(ctx context.Context, req *FormByIdRequest) (*Form
 	const op = "Handler:DeleteUser"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -191,14 +203,7 @@ func (uh *UserHandler) GetForm(ctx context.Context, req *FormByIdRequest) (*Form
 
 	form, err := uh.userUC.GetForm(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserNotFound):
-			log.Info("couldn't get form: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't get form: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
-		}
+		return nil, problem.From(log, "couldn't get form", err)
 	}
 
 	resp := ToFormOutputFromEntity(form)
@@ -210,7 +215,7 @@ func (uh *UserHandler) UpdateForm(ctx context.Context, req *UpdateFormRequest) (
 	const op = "Handler:UpdateForm"
 
 	tracer := otel.Tracer(tracerName)
-	_, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
 
 	log := slog.With(
@@ -218,26 +223,120 @@ func (uh *UserHandler) UpdateForm(ctx context.Context, req *UpdateFormRequest) (
 	)
 	log.Debug(op)
 
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
 	cmd := commands.UpdateFormCommand{
-		UserID:   req.UserID,
-		SpaceID:  req.SpaceID,
-		UserTags: req.Body.UserTags,
-		PairTags: req.Body.PairTags,
+		UserID:       req.UserID,
+		SpaceID:      req.SpaceID,
+		UserTags:     req.Body.UserTags,
+		PairTags:     req.Body.PairTags,
+		Availability: req.Body.Availability,
+		Version:      version,
 	}
 
 	user, forms, err := uh.userUC.UpdateForm(ctx, cmd)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserNotFound):
-			log.Info("couldn't update user: ", err.Error())
-			return nil, huma.Error404NotFound(err.Error())
-		default:
-			log.Error("couldn't update user: ", err.Error())
-			return nil, huma.Error500InternalServerError(err.Error())
+		return nil, problem.From(log, "couldn't update user", err)
+	}
+
+	resp := ToUserWithFormsOutputFromEntity(user, forms)
+	for _, form := range forms {
+		if form.SpaceID == req.SpaceID {
+			resp.ETag = etag.Format(form.Version)
 		}
 	}
 
+	return resp, nil
+}
+
+func (uh *UserHandler) PatchForm(ctx context.Context, req *PatchFormRequest) (*UserWithFormsResponse, error) {
+	const op = "Handler:PatchForm"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", req.UserID),
+		slog.Int("space id", req.SpaceID),
+	)
+	log.Debug(op)
+
+	version, err := etag.Parse(req.IfMatch)
+	if err != nil {
+		return nil, problem.From(log, "couldn't parse If-Match", err)
+	}
+
+	cmd := commands.PatchFormCommand{
+		UserID:  req.UserID,
+		SpaceID: req.SpaceID,
+		Patch:   req.Body,
+		Version: version,
+	}
+
+	user, forms, err := uh.userUC.PatchForm(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't patch form", err)
+	}
+
 	resp := ToUserWithFormsOutputFromEntity(user, forms)
+	for _, form := range forms {
+		if form.SpaceID == req.SpaceID {
+			resp.ETag = etag.Format(form.Version)
+		}
+	}
 
 	return resp, nil
 }
+
+func (uh *UserHandler) ExportUser(ctx context.Context, req *UserByIdRequest) (*UserExportResponse, error) {
+	const op = "Handler:ExportUser"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.UserByIdCommand{ID: req.ID}
+
+	export, err := uh.userUC.ExportUser(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't export user", err)
+	}
+
+	resp := ToUserExportOutputFromEntity(export)
+
+	return resp, nil
+}
+
+func (uh *UserHandler) EraseUser(ctx context.Context, req *UserByIdRequest) (*struct{}, error) {
+	const op = "Handler:EraseUser"
+
+	tracer := otel.Tracer(tracerName)
+	ctx, span := tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", req.ID),
+	)
+	log.Debug(op)
+
+	cmd := commands.UserByIdCommand{ID: req.ID}
+
+	err := uh.userUC.EraseUser(ctx, cmd)
+	if err != nil {
+		return nil, problem.From(log, "couldn't erase user", err)
+	}
+
+	return &struct{}{}, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/pkg/database"
+	"log/slog"
+	"sync"
+)
+
+func NewAuditRepository(once *sync.Once, db *database.Postgres) *AuditRepository {
+	var repo *AuditRepository
+	once.Do(func() {
+		repo = &AuditRepository{db: db}
+	})
+
+	return repo
+}
+
+type AuditRepository struct {
+	db *database.Postgres
+}
+
+func (r *AuditRepository) InsertEntry(ctx context.Context, entry *entity.AuditEntry) error {
+	const op = "Repo:InsertAuditEntry"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("action", entry.Action),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Insert("audit_log").
+		Columns("space_id, actor_id, action, target_type, target_id, diff, request_id").
+		Values(entry.SpaceID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.Diff, entry.RequestID).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		log.Debug("couldn't insert data in audit_log", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// GetEntries returns page of space audit log, newest first, and total number of matching entries.
+func (r *AuditRepository) GetEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	const op = "Repo:GetAuditEntries"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", filter.SpaceID),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.AuditEntry, int, error) {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	where := squirrel.And{squirrel.Eq{"space_id": filter.SpaceID}}
+	if filter.ActorID != 0 {
+		where = append(where, squirrel.Eq{"actor_id": filter.ActorID})
+	}
+	if filter.Action != "" {
+		where = append(where, squirrel.Eq{"action": filter.Action})
+	}
+	if filter.TargetType != "" {
+		where = append(where, squirrel.Eq{"target_type": filter.TargetType})
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, squirrel.GtOrEq{"created_at": filter.Since})
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, squirrel.Lt{"created_at": filter.Until})
+	}
+
+	countQuery, countArgs, err := r.db.Builder.
+		Select("count(*)").
+		From("audit_log").
+		Where(where).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	var total int
+
+	err = r.db.Pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
+	if err != nil {
+		log.Debug("couldn't count audit entries", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("id, space_id, actor_id, action, target_type, target_id, diff, request_id, created_at").
+		From("audit_log").
+		Where(where).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't select audit entries", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	entries := make([]*entity.AuditEntry, 0, filter.Limit)
+
+	for rows.Next() {
+		e := new(entity.AuditEntry)
+
+		err = rows.Scan(&e.ID, &e.SpaceID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.Diff, &e.RequestID, &e.CreatedAt)
+		if err != nil {
+			return fail(err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return entries, total, nil
+}
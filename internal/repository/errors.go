@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgError translates pgx errors to domain errors. notFound and duplicate are
+// specific to the queried table, nil keeps the original error for that case.
+// Errors it doesn't recognize are returned as is and end up as internal ones.
+func pgError(err error, notFound, duplicate error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		if notFound != nil {
+			return notFound
+		}
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.UniqueViolation:
+		if duplicate != nil {
+			return duplicate
+		}
+	case pgerrcode.ForeignKeyViolation:
+		return errs.ErrReferenceNotFound
+	case pgerrcode.CheckViolation, pgerrcode.NotNullViolation,
+		pgerrcode.InvalidTextRepresentation, pgerrcode.StringDataRightTruncationDataException:
+		return errs.ErrInvalidData
+	}
+
+	return err
+}
+
+// versionError explains update that matched no row. Callers check the row exists
+// before updating, so with a version given it most likely was modified meanwhile.
+func versionError(version int, notFound error) error {
+	if version > 0 {
+		return errs.ErrVersionMismatch
+	}
+
+	return notFound
+}
@@ -2,20 +2,21 @@ package repository
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
 	"github.com/Slava02/Involvio/pkg/database"
-	"github.com/jackc/pgerrcode"
-	"github.com/jackc/pgx/v5/pgconn"
 	"log/slog"
 	"sync"
+	"time"
 )
 
 var (
-	ErrEventNotFound      = errors.New("event not found")
-	ErrEventAlreadyExists = errors.New("event already exists")
+	ErrEventNotFound      = errs.New(errs.NotFound, "event.not_found", "event not found")
+	ErrEventAlreadyExists = errs.New(errs.Conflict, "event.duplicate", "event already exists")
+	// ErrParticipantAlreadyExists is returned when user joins event twice.
+	ErrParticipantAlreadyExists = errs.New(errs.Conflict, "participation.duplicate", "user already participates in event")
 )
 
 func NewEventRepository(once *sync.Once, db *database.Postgres) *EventRepository {
@@ -45,11 +46,11 @@ func (r *EventRepository) InsertEvent(ctx context.Context, userId int, event *en
 
 	queryEvent, argsEvent, err := r.db.Builder.
 		Insert("event").
-		Columns("id, space_id, name, description, begin_date, end_date, tags").
-		Values(event.ID, event.SpaceId, event.Name, event.Description, event.BeginDate, event.EndDate, event.Tags).
+		Columns("id, space_id, name, description, begin_date, end_date, timezone, tags").
+		Values(event.ID, event.SpaceId, event.Name, event.Description, event.BeginDate, event.EndDate, event.Timezone, event.Tags).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -59,7 +60,7 @@ func (r *EventRepository) InsertEvent(ctx context.Context, userId int, event *en
 		Values(userId, event.ID).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -69,20 +70,20 @@ func (r *EventRepository) InsertEvent(ctx context.Context, userId int, event *en
 	}
 	defer tx.Rollback(ctx)
 
-	_, err = r.db.Pool.Exec(ctx, queryEvent, argsEvent...)
+	_, err = tx.Exec(ctx, queryEvent, argsEvent...)
 	if err != nil {
-		log.Debug("couldn't insert data in event: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't insert data in event", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, ErrEventAlreadyExists))
 	}
 
-	_, err = r.db.Pool.Exec(ctx, queryUserEvent, argsUserEvent...)
+	_, err = tx.Exec(ctx, queryUserEvent, argsUserEvent...)
 	if err != nil {
-		log.Debug("couldn't insert data in event: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't insert data in user_event", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		log.Debug("couldn't commit transaction: ", err.Error())
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -103,26 +104,21 @@ func (r *EventRepository) GetEvent(ctx context.Context, id int) (*entity.Event,
 	}
 
 	query, args, err := r.db.Builder.
-		Select("id, space_id, name, description, begin_date, end_date, tags").
+		Select("id, space_id, name, description, begin_date, end_date, timezone, tags").
 		From("event").
-		Where("id = ?", id).
+		Where("id = ? AND deleted_at IS NULL", id).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	event := new(entity.Event)
 
-	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&event.ID, &event.SpaceId, &event.Name, &event.Description, &event.BeginDate, &event.EndDate, &event.Tags)
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&event.ID, &event.SpaceId, &event.Name, &event.Description, &event.BeginDate, &event.EndDate, &event.Timezone, &event.Tags)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Debug("event not found: ", err.Error())
-			return fail(ErrEventNotFound)
-		} else {
-			log.Debug("error: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't get event", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrEventNotFound, nil))
 	}
 
 	return event, nil
@@ -144,37 +140,31 @@ func (r *EventRepository) AddUser(ctx context.Context, eventId, userId int) erro
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	// a user who left the event earlier gets the soft deleted row back
 	query, args, err := r.db.Builder.
 		Insert("user_event").
 		Columns("user_id, event_id").
 		Values(userId, eventId).
+		Suffix("ON CONFLICT (user_id, event_id) DO UPDATE SET deleted_at = NULL WHERE user_event.deleted_at IS NOT NULL").
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err == nil && tag.RowsAffected() == 0 {
+		return fail(ErrParticipantAlreadyExists)
+	}
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case pgerrcode.UniqueViolation:
-				log.Debug("couldn't insert data in user_event: ", err.Error())
-				return fail(ErrEventAlreadyExists)
-			default:
-				log.Debug("couldn't insert data in user_event: ", err.Error())
-				return fail(err)
-			}
-		} else {
-			log.Debug("couldn't insert data in user_event: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't insert data in user_event", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, ErrParticipantAlreadyExists))
 	}
 
 	return nil
 }
 
+// DeleteEvent soft deletes event together with its participants.
 func (r *EventRepository) DeleteEvent(ctx context.Context, id int) error {
 	const op = "Repo:DeleteEvent"
 
@@ -188,21 +178,24 @@ func (r *EventRepository) DeleteEvent(ctx context.Context, id int) error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	// now() is the transaction start time, so all rows get the same deleted_at
 	queryEvent, argsEvent, err := r.db.Builder.
-		Delete("event").
-		Where("id = ?", id).
+		Update("event").
+		Set("deleted_at", squirrel.Expr("now()")).
+		Where("id = ? AND deleted_at IS NULL", id).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	queryUserEvent, argsUserEvent, err := r.db.Builder.
-		Delete("user_event").
-		Where("event_id = ?", id).
+		Update("user_event").
+		Set("deleted_at", squirrel.Expr("now()")).
+		Where("event_id = ? AND deleted_at IS NULL", id).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -212,20 +205,107 @@ func (r *EventRepository) DeleteEvent(ctx context.Context, id int) error {
 	}
 	defer tx.Rollback(ctx)
 
-	_, err = r.db.Pool.Exec(ctx, queryUserEvent, argsUserEvent...)
+	tag, err := tx.Exec(ctx, queryEvent, argsEvent...)
+	if err != nil {
+		log.Debug("couldn't delete event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(ErrEventNotFound)
+	}
+
+	_, err = tx.Exec(ctx, queryUserEvent, argsUserEvent...)
+	if err != nil {
+		log.Debug("couldn't delete data from user_event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// GetDeletedEvent returns soft deleted event, it is used to check who may restore it.
+func (r *EventRepository) GetDeletedEvent(ctx context.Context, id int) (*entity.Event, error) {
+	const op = "Repo:GetDeletedEvent"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("event id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.Event, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("id, space_id, name, description, begin_date, end_date, timezone, tags").
+		From("event").
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	event := new(entity.Event)
+
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&event.ID, &event.SpaceId, &event.Name, &event.Description, &event.BeginDate, &event.EndDate, &event.Timezone, &event.Tags)
+	if err != nil {
+		log.Debug("couldn't get deleted event", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrEventNotFound, nil))
+	}
+
+	return event, nil
+}
+
+// RestoreEvent reverts DeleteEvent: event and participants deleted together with it are restored.
+func (r *EventRepository) RestoreEvent(ctx context.Context, id int) error {
+	const op = "Repo:RestoreEvent"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("event id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedAt time.Time
+
+	err = tx.QueryRow(ctx, `SELECT deleted_at FROM event WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&deletedAt)
+	if err != nil {
+		log.Debug("couldn't get deleted event", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrEventNotFound, nil))
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE event SET deleted_at = NULL WHERE id = $1`, id)
 	if err != nil {
-		log.Debug("couldn't delete data from user_event: ", err.Error())
+		log.Debug("couldn't restore event", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, queryEvent, argsEvent...)
+	_, err = tx.Exec(ctx, `UPDATE user_event SET deleted_at = NULL WHERE event_id = $1 AND deleted_at = $2`, id, deletedAt)
 	if err != nil {
-		log.Debug("couldn't delete data from event: ", err.Error())
+		log.Debug("couldn't restore data in user_event", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		log.Debug("couldn't commit transaction: ", err.Error())
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
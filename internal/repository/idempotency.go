@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/Slava02/Involvio/pkg/idempotency"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func NewIdempotencyRepository(once *sync.Once, db *database.Postgres) *IdempotencyRepository {
+	var repo *IdempotencyRepository
+	once.Do(func() {
+		repo = &IdempotencyRepository{db: db}
+	})
+
+	return repo
+}
+
+// IdempotencyRepository is idempotency.Store shared by all replicas.
+type IdempotencyRepository struct {
+	db *database.Postgres
+}
+
+var _ idempotency.Store = (*IdempotencyRepository)(nil)
+
+func (r *IdempotencyRepository) Claim(ctx context.Context, key, fingerprint string, ttl time.Duration) (*idempotency.Record, bool, error) {
+	const op = "Repo:ClaimIdempotencyKey"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("key", key),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*idempotency.Record, bool, error) {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+
+	// expired keys and keys of abandoned requests are claimed again
+	query := `INSERT INTO idempotency_key (key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = excluded.fingerprint, status = NULL, headers = NULL, body = NULL,
+		    created_at = excluded.created_at, expires_at = excluded.expires_at
+		WHERE idempotency_key.expires_at < $3
+		   OR (idempotency_key.status IS NULL AND idempotency_key.created_at < $5)`
+
+	tag, err := r.db.Pool.Exec(ctx, query, key, fingerprint, now, now.Add(ttl), now.Add(-idempotency.LockTimeout))
+	if err != nil {
+		log.Debug("couldn't insert data in idempotency_key", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() > 0 {
+		return nil, true, nil
+	}
+
+	var (
+		rec     idempotency.Record
+		status  *int
+		headers []byte
+		body    []byte
+	)
+
+	err = r.db.Pool.QueryRow(ctx, `SELECT fingerprint, status, headers, body FROM idempotency_key WHERE key = $1`, key).
+		Scan(&rec.Fingerprint, &status, &headers, &body)
+	if err != nil {
+		// released between insert and select, let client retry
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &idempotency.Record{Fingerprint: fingerprint}, false, nil
+		}
+		log.Debug("couldn't get idempotency key", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if status != nil {
+		rec.Response = &idempotency.Response{Status: *status, Header: http.Header{}, Body: body}
+		if err = json.Unmarshal(headers, &rec.Response.Header); err != nil {
+			return fail(err)
+		}
+	}
+
+	return &rec, false, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, resp idempotency.Response) error {
+	const op = "Repo:CompleteIdempotencyKey"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("key", key),
+	)
+	log.Debug(op)
+
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("idempotency_key").
+		Set("status", resp.Status).
+		Set("headers", headers).
+		Set("body", resp.Body).
+		Where("key = ?", key).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create query", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't update idempotency key", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	const op = "Repo:ReleaseIdempotencyKey"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("key", key),
+	)
+	log.Debug(op)
+
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM idempotency_key WHERE key = $1 AND status IS NULL`, key)
+	if err != nil {
+		log.Debug("couldn't delete idempotency key", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Purge drops keys expired before the given time.
+func (r *IdempotencyRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	const op = "Repo:PurgeIdempotencyKeys"
+
+	log := slog.With(
+		slog.String("op", op),
+	)
+	log.Debug(op)
+
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM idempotency_key WHERE expires_at < $1`, before)
+	if err != nil {
+		log.Debug("couldn't delete data from idempotency_key", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
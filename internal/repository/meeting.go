@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"sync"
+)
+
+var ErrMeetingNotFound = errs.New(errs.NotFound, "meeting.not_found", "meeting not found")
+
+const meetingColumns = "id, round_id, space_id, state, proposed_slots, scheduled_at, created_at, updated_at, version"
+
+func NewMeetingRepository(once *sync.Once, db *database.Postgres) *MeetingRepository {
+	var repo *MeetingRepository
+	once.Do(func() {
+		repo = &MeetingRepository{db: db}
+	})
+
+	return repo
+}
+
+type MeetingRepository struct {
+	db *database.Postgres
+}
+
+func (r *MeetingRepository) GetMeeting(ctx context.Context, id int) (*entity.Meeting, error) {
+	const op = "Repo:GetMeeting"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.Meeting, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select(meetingColumns).
+		From("meeting").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	meeting, err := scanMeeting(r.db.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		log.Debug("couldn't get meeting", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrMeetingNotFound, nil))
+	}
+
+	if err = getParticipants(ctx, r.db.Pool, r.db.Builder, meeting); err != nil {
+		log.Debug("couldn't get participants", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return meeting, nil
+}
+
+// UpdateMeeting saves state, time and participants of the meeting of the given version.
+func (r *MeetingRepository) UpdateMeeting(ctx context.Context, meeting *entity.Meeting, version int) error {
+	const op = "Repo:UpdateMeeting"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", meeting.ID),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = updateMeeting(ctx, tx, r.db.Builder, meeting, version); err != nil {
+		log.Debug("couldn't update meeting", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// OpenMeetings returns meetings in the space the user takes part in that are not over yet.
+func (r *MeetingRepository) OpenMeetings(ctx context.Context, spaceId, userId int) ([]*entity.Meeting, error) {
+	const op = "Repo:OpenMeetings"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.Meeting, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select(meetingColumns).
+		From("meeting").
+		Where("space_id = ?", spaceId).
+		Where(squirrel.Eq{"state": []entity.MeetingState{entity.MeetingProposed, entity.MeetingAccepted, entity.MeetingScheduled}}).
+		Where("id IN (SELECT meeting_id FROM meeting_participant WHERE user_id = ? AND response <> ?)", userId, entity.ResponseDeclined).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't get meetings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	meetings := make([]*entity.Meeting, 0)
+	for rows.Next() {
+		meeting, err := scanMeeting(rows)
+		if err != nil {
+			log.Debug("couldn't scan meeting", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		meetings = append(meetings, meeting)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	if err = getParticipants(ctx, r.db.Pool, r.db.Builder, meetings...); err != nil {
+		log.Debug("couldn't get participants", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return meetings, nil
+}
+
+// insertMeeting saves a new meeting with its participants and fills generated fields.
+func insertMeeting(ctx context.Context, db database.Database, builder squirrel.StatementBuilderType, meeting *entity.Meeting) error {
+	query, args, err := builder.
+		Insert("meeting").
+		Columns("round_id, space_id, state, proposed_slots").
+		Values(meeting.RoundID, meeting.SpaceID, meeting.State, meeting.ProposedSlots).
+		Suffix("RETURNING id, created_at, updated_at, version").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = db.QueryRow(ctx, query, args...).Scan(&meeting.ID, &meeting.CreatedAt, &meeting.UpdatedAt, &meeting.Version)
+	if err != nil {
+		return pgError(err, nil, nil)
+	}
+
+	insert := builder.
+		Insert("meeting_participant").
+		Columns("meeting_id, user_id, response, joined")
+	for _, p := range meeting.Participants {
+		insert = insert.Values(meeting.ID, p.UserID, p.Response, p.Joined)
+	}
+
+	query, args, err = insert.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = db.Exec(ctx, query, args...); err != nil {
+		return pgError(err, nil, nil)
+	}
+
+	return nil
+}
+
+// updateMeeting saves the meeting of the given version, participants who
+// joined it are added.
+func updateMeeting(ctx context.Context, db database.Database, builder squirrel.StatementBuilderType, meeting *entity.Meeting, version int) error {
+	query, args, err := builder.
+		Update("meeting").
+		Set("state", meeting.State).
+		Set("proposed_slots", meeting.ProposedSlots).
+		Set("scheduled_at", meeting.ScheduledAt).
+		Set("updated_at", squirrel.Expr("now()")).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ? AND version = ?", meeting.ID, version).
+		Suffix("RETURNING updated_at, version").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = db.QueryRow(ctx, query, args...).Scan(&meeting.UpdatedAt, &meeting.Version)
+	if err != nil {
+		return pgError(err, versionError(version, ErrMeetingNotFound), nil)
+	}
+
+	for _, p := range meeting.Participants {
+		query, args, err := builder.
+			Insert("meeting_participant").
+			Columns("meeting_id, user_id, response, held, no_show, responded_at, joined, reported_by").
+			Values(meeting.ID, p.UserID, p.Response, p.Held, p.NoShow, p.RespondedAt, p.Joined, p.ReportedBy).
+			Suffix(`ON CONFLICT (meeting_id, user_id) DO UPDATE SET response = EXCLUDED.response,
+				held = EXCLUDED.held, no_show = EXCLUDED.no_show, responded_at = EXCLUDED.responded_at,
+				reported_by = EXCLUDED.reported_by`).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = db.Exec(ctx, query, args...); err != nil {
+			return pgError(err, nil, nil)
+		}
+	}
+
+	return nil
+}
+
+func scanMeeting(row pgx.Row) (*entity.Meeting, error) {
+	meeting := new(entity.Meeting)
+
+	err := row.Scan(&meeting.ID, &meeting.RoundID, &meeting.SpaceID, &meeting.State, &meeting.ProposedSlots, &meeting.ScheduledAt,
+		&meeting.CreatedAt, &meeting.UpdatedAt, &meeting.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return meeting, nil
+}
+
+// getParticipants fills participants of the meetings with a single query.
+func getParticipants(ctx context.Context, db database.Database, builder squirrel.StatementBuilderType, meetings ...*entity.Meeting) error {
+	if len(meetings) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*entity.Meeting, len(meetings))
+	ids := make([]int, 0, len(meetings))
+	for _, m := range meetings {
+		m.Participants = make([]*entity.Participant, 0, entity.MinGroupSize)
+		byID[m.ID] = m
+		ids = append(ids, m.ID)
+	}
+
+	query, args, err := builder.
+		Select("meeting_id, user_id, response, held, no_show, responded_at, joined, reported_by").
+		From("meeting_participant").
+		Where(squirrel.Expr("meeting_id = ANY(?)", ids)).
+		OrderBy("meeting_id", "user_id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var meetingID int
+		p := new(entity.Participant)
+
+		if err = rows.Scan(&meetingID, &p.UserID, &p.Response, &p.Held, &p.NoShow, &p.RespondedAt, &p.Joined, &p.ReportedBy); err != nil {
+			return err
+		}
+
+		byID[meetingID].Participants = append(byID[meetingID].Participants, p)
+	}
+
+	return rows.Err()
+}
+
+// eraseReports removes the users from no-show claims of other participants.
+func eraseReports(ctx context.Context, db database.Database, userIds []int) error {
+	_, err := db.Exec(ctx, `
+		UPDATE meeting_participant
+		SET reported_by = (SELECT array_agg(id) FROM unnest(reported_by) id WHERE id <> ALL($1))
+		WHERE reported_by && $1::int[]`, userIds)
+	if err != nil {
+		return pgError(err, nil, nil)
+	}
+
+	return nil
+}
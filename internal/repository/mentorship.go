@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"sync"
+)
+
+var (
+	ErrMentorshipNotFound = errs.New(errs.NotFound, "mentorship.not_found", "mentorship not found")
+	ErrMenteeHasMentor    = errs.New(errs.Conflict, "mentorship.mentee_has_mentor", "mentee already has a mentor")
+)
+
+func NewMentorshipRepository(once *sync.Once, db *database.Postgres) *MentorshipRepository {
+	var repo *MentorshipRepository
+	once.Do(func() {
+		repo = &MentorshipRepository{db: db}
+	})
+
+	return repo
+}
+
+type MentorshipRepository struct {
+	db *database.Postgres
+}
+
+const mentorshipColumns = "id, space_id, mentor_id, mentee_id, started_at, ended_at"
+
+func (r *MentorshipRepository) GetMentorship(ctx context.Context, id int) (*entity.Mentorship, error) {
+	const op = "Repo:GetMentorship"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("mentorship id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.Mentorship, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select(mentorshipColumns).
+		From("mentorship").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	mentorship, err := scanMentorship(r.db.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		log.Debug("couldn't get mentorship", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrMentorshipNotFound, nil))
+	}
+
+	return mentorship, nil
+}
+
+// GetMentorships returns mentorships of the space, oldest first. Ended ones
+// are returned only if withEnded is set.
+func (r *MentorshipRepository) GetMentorships(ctx context.Context, spaceId int, withEnded bool) ([]*entity.Mentorship, error) {
+	const op = "Repo:GetMentorships"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.Mentorship, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	builder := r.db.Builder.
+		Select(mentorshipColumns).
+		From("mentorship").
+		Where("space_id = ?", spaceId).
+		OrderBy("started_at, id")
+	if !withEnded {
+		builder = builder.Where("ended_at IS NULL")
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't get mentorships", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	mentorships := make([]*entity.Mentorship, 0)
+	for rows.Next() {
+		mentorship, err := scanMentorship(rows)
+		if err != nil {
+			log.Debug("couldn't scan mentorship", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		mentorships = append(mentorships, mentorship)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return mentorships, nil
+}
+
+// updateMentorships ends the given mentorships and starts new ones, filling
+// their IDs and start times. A mentee who got a mentor meanwhile fails it
+// with ErrMenteeHasMentor. Rounds update mentorships in their transaction.
+func updateMentorships(ctx context.Context, db database.Database, builder squirrel.StatementBuilderType, ended []int, started []*entity.Mentorship) error {
+	if len(ended) > 0 {
+		_, err := db.Exec(ctx, `UPDATE mentorship SET ended_at = now() WHERE id = ANY($1) AND ended_at IS NULL`, ended)
+		if err != nil {
+			return pgError(err, nil, nil)
+		}
+	}
+
+	for _, mentorship := range started {
+		query, args, err := builder.
+			Insert("mentorship").
+			Columns("space_id, mentor_id, mentee_id").
+			Values(mentorship.SpaceID, mentorship.MentorID, mentorship.MenteeID).
+			Suffix("RETURNING id, started_at").
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		if err = db.QueryRow(ctx, query, args...).Scan(&mentorship.ID, &mentorship.StartedAt); err != nil {
+			return pgError(err, nil, ErrMenteeHasMentor)
+		}
+	}
+
+	return nil
+}
+
+// EndMentorship ends the active mentorship, entity.ErrMentorshipEnded if it has ended already.
+func (r *MentorshipRepository) EndMentorship(ctx context.Context, id int) error {
+	const op = "Repo:EndMentorship"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("mentorship id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("mentorship").
+		Set("ended_at", squirrel.Expr("now()")).
+		Where("id = ?", id).
+		Where("ended_at IS NULL").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't update mentorship", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(entity.ErrMentorshipEnded)
+	}
+
+	return nil
+}
+
+func scanMentorship(row pgx.Row) (*entity.Mentorship, error) {
+	mentorship := new(entity.Mentorship)
+
+	err := row.Scan(&mentorship.ID, &mentorship.SpaceID, &mentorship.MentorID, &mentorship.MenteeID, &mentorship.StartedAt, &mentorship.EndedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return mentorship, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/pkg/database"
+	"github.com/Slava02/Involvio/pkg/ratelimit"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+func NewRateLimitRepository(once *sync.Once, db *database.Postgres) *RateLimitRepository {
+	var repo *RateLimitRepository
+	once.Do(func() {
+		repo = &RateLimitRepository{db: db}
+	})
+
+	return repo
+}
+
+// RateLimitRepository is ratelimit.Store shared by all replicas.
+type RateLimitRepository struct {
+	db *database.Postgres
+}
+
+var _ ratelimit.Store = (*RateLimitRepository)(nil)
+
+func (r *RateLimitRepository) Take(ctx context.Context, key string, limit ratelimit.Limit) (bool, time.Duration, error) {
+	const op = "Repo:TakeToken"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("key", key),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (bool, time.Duration, error) {
+		return false, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	// row lock serializes concurrent requests of the same key
+	_, err = tx.Exec(ctx, `INSERT INTO rate_limit (key) VALUES ($1) ON CONFLICT (key) DO NOTHING`, key)
+	if err != nil {
+		log.Debug("couldn't insert data in rate_limit", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	var (
+		tokens  *float64
+		updated *time.Time
+	)
+
+	err = tx.QueryRow(ctx, `SELECT tokens, updated_at FROM rate_limit WHERE key = $1 FOR UPDATE`, key).Scan(&tokens, &updated)
+	if err != nil {
+		log.Debug("couldn't get bucket", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	var bucket ratelimit.Bucket
+	if tokens != nil && updated != nil {
+		bucket = ratelimit.Bucket{Tokens: *tokens, Updated: *updated}
+	}
+
+	bucket, ok, wait := limit.Take(bucket, time.Now())
+
+	_, err = tx.Exec(ctx, `UPDATE rate_limit SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1`,
+		key, bucket.Tokens, bucket.Updated, limit.Full(bucket))
+	if err != nil {
+		log.Debug("couldn't update bucket", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return ok, wait, nil
+}
+
+// Purge drops buckets refilled before the given time, they are equal to new ones.
+func (r *RateLimitRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	const op = "Repo:PurgeRateLimit"
+
+	log := slog.With(
+		slog.String("op", op),
+	)
+	log.Debug(op)
+
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM rate_limit WHERE full_at < $1`, before)
+	if err != nil {
+		log.Debug("couldn't delete data from rate_limit", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/pkg/database"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+func NewRetentionRepository(once *sync.Once, db *database.Postgres) *RetentionRepository {
+	var repo *RetentionRepository
+	once.Do(func() {
+		repo = &RetentionRepository{db: db}
+	})
+
+	return repo
+}
+
+type RetentionRepository struct {
+	db *database.Postgres
+}
+
+// purgeQueries remove rows soft deleted before $1. Children go first, together
+// with rows that still reference purged parents, so foreign keys hold.
+var purgeQueries = []string{
+	`DELETE FROM rematch_pool
+		WHERE round_id IN (SELECT id FROM round WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1))
+		OR user_id IN (SELECT id FROM "user" WHERE deleted_at < $1)`,
+	`DELETE FROM meeting_participant
+		WHERE meeting_id IN (SELECT id FROM meeting WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1))
+		OR user_id IN (SELECT id FROM "user" WHERE deleted_at < $1)`,
+	`DELETE FROM mentorship WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1)
+		OR mentor_id IN (SELECT id FROM "user" WHERE deleted_at < $1)
+		OR mentee_id IN (SELECT id FROM "user" WHERE deleted_at < $1)`,
+	`DELETE FROM round_preview WHERE expires_at < now()
+		OR space_id IN (SELECT id FROM space WHERE deleted_at < $1)
+		OR admin_id IN (SELECT id FROM "user" WHERE deleted_at < $1)`,
+	`DELETE FROM meeting WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1)`,
+	`DELETE FROM round_snapshot
+		WHERE round_id IN (SELECT id FROM round WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1))`,
+	`DELETE FROM round WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1)`,
+	`DELETE FROM user_event WHERE deleted_at < $1
+		OR event_id IN (SELECT id FROM event WHERE deleted_at < $1
+			OR space_id IN (SELECT id FROM space WHERE deleted_at < $1))
+		OR user_id IN (SELECT id FROM "user" WHERE deleted_at < $1)`,
+	`DELETE FROM user_space WHERE deleted_at < $1
+		OR space_id IN (SELECT id FROM space WHERE deleted_at < $1)
+		OR user_id IN (SELECT id FROM "user" WHERE deleted_at < $1)`,
+	`DELETE FROM event WHERE deleted_at < $1
+		OR space_id IN (SELECT id FROM space WHERE deleted_at < $1)`,
+	`DELETE FROM space_settings WHERE space_id IN (SELECT id FROM space WHERE deleted_at < $1)`,
+	`DELETE FROM space WHERE deleted_at < $1`,
+	`DELETE FROM "user" WHERE deleted_at < $1`,
+}
+
+// Purge hard deletes rows soft deleted before the given moment and returns how many rows were removed.
+func (r *RetentionRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	const op = "Repo:Purge"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Time("before", before),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (int64, error) {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	// snapshots, previews and no-show reports hold user IDs foreign keys don't reach
+	var userIds []int
+	err = tx.QueryRow(ctx, `SELECT coalesce(array_agg(id), '{}') FROM "user" WHERE deleted_at < $1`, before).Scan(&userIds)
+	if err != nil {
+		log.Debug("couldn't select purged users", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if len(userIds) > 0 {
+		if err = eraseSnapshots(ctx, tx, userIds); err != nil {
+			log.Debug("couldn't erase purged users from round_snapshot", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		if err = erasePreviews(ctx, tx, userIds); err != nil {
+			log.Debug("couldn't erase purged users from round_preview", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		if err = eraseReports(ctx, tx, userIds); err != nil {
+			log.Debug("couldn't erase purged users from no-show reports", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
+	var purged int64
+	for _, query := range purgeQueries {
+		tag, err := tx.Exec(ctx, query, before)
+		if err != nil {
+			log.Debug("couldn't purge deleted rows", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		purged += tag.RowsAffected()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,672 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/Slava02/Involvio/pkg/database"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrRoundNotFound   = errs.New(errs.NotFound, "round.not_found", "round not found")
+	ErrPreviewNotFound = errs.New(errs.NotFound, "preview.not_found", "round preview not found or expired")
+)
+
+func NewRoundRepository(once *sync.Once, db *database.Postgres) *RoundRepository {
+	var repo *RoundRepository
+	once.Do(func() {
+		repo = &RoundRepository{db: db}
+	})
+
+	return repo
+}
+
+type RoundRepository struct {
+	db *database.Postgres
+}
+
+// InsertRound saves round with its meetings and participants and ends and
+// starts mentorships of the round in one transaction, filling generated IDs
+// and times.
+func (r *RoundRepository) InsertRound(ctx context.Context, round *entity.Round, ended []int, started []*entity.Mentorship) error {
+	const op = "Repo:InsertRound"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", round.SpaceID),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = updateMentorships(ctx, tx, r.db.Builder, ended, started); err != nil {
+		log.Debug("couldn't update mentorships", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = insertRound(ctx, tx, r.db.Builder, round); err != nil {
+		log.Debug("couldn't insert round", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+func insertRound(ctx context.Context, db database.Database, builder squirrel.StatementBuilderType, round *entity.Round) error {
+	query, args, err := builder.
+		Insert("round").
+		Columns("space_id, settings_version, seed, strategy, strategy_version").
+		Values(round.SpaceID, round.SettingsVersion, round.Seed, round.Strategy, round.StrategyVersion).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if err = db.QueryRow(ctx, query, args...).Scan(&round.ID, &round.CreatedAt); err != nil {
+		return pgError(err, nil, nil)
+	}
+
+	for _, meeting := range round.Meetings {
+		meeting.RoundID, meeting.SpaceID = round.ID, round.SpaceID
+
+		if err = insertMeeting(ctx, db, builder, meeting); err != nil {
+			return err
+		}
+	}
+
+	if round.Snapshot == nil {
+		return nil
+	}
+
+	// meetings change after the round, replays compare with them as matched
+	matched := make([][]int, 0, len(round.Meetings))
+	for _, meeting := range round.Meetings {
+		group := make([]int, 0, len(meeting.Participants))
+		for _, p := range meeting.Participants {
+			group = append(group, p.UserID)
+		}
+		matched = append(matched, group)
+	}
+
+	query, args, err = builder.
+		Insert("round_snapshot").
+		Columns("round_id, snapshot, matched").
+		Values(round.ID, round.Snapshot, matched).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = db.Exec(ctx, query, args...); err != nil {
+		return pgError(err, nil, nil)
+	}
+
+	return nil
+}
+
+// InsertPreview saves round preview until it expires and fills its times.
+func (r *RoundRepository) InsertPreview(ctx context.Context, preview *entity.RoundPreview) error {
+	const op = "Repo:InsertPreview"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", preview.SpaceID),
+		slog.String("preview id", preview.ID),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Insert("round_preview").
+		Columns("id, space_id, admin_id, settings_version, preview, expires_at").
+		Values(preview.ID, preview.SpaceID, preview.AdminID, preview.SettingsVersion, preview,
+			squirrel.Expr("now() + make_interval(secs => ?)", entity.PreviewTTL.Seconds())).
+		Suffix("RETURNING created_at, expires_at, version").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&preview.CreatedAt, &preview.ExpiresAt, &preview.Version); err != nil {
+		log.Debug("couldn't insert data in round_preview", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	return nil
+}
+
+// GetPreview returns round preview which hasn't expired yet.
+func (r *RoundRepository) GetPreview(ctx context.Context, id string) (*entity.RoundPreview, error) {
+	const op = "Repo:GetPreview"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("preview id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.RoundPreview, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("preview, created_at, expires_at, version").
+		From("round_preview").
+		Where("id = ?::uuid AND expires_at > now()", id).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	preview := new(entity.RoundPreview)
+
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(preview, &preview.CreatedAt, &preview.ExpiresAt, &preview.Version)
+	if err != nil {
+		log.Debug("couldn't get round preview", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrPreviewNotFound, nil))
+	}
+
+	return preview, nil
+}
+
+// UpdatePreview saves overridden preview if its version is still the given
+// one and increments the version.
+func (r *RoundRepository) UpdatePreview(ctx context.Context, preview *entity.RoundPreview, version int) error {
+	const op = "Repo:UpdatePreview"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("preview id", preview.ID),
+		slog.Int("version", version),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("round_preview").
+		Set("preview", preview).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ?::uuid AND version = ? AND expires_at > now()", preview.ID, version).
+		Suffix("RETURNING version").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&preview.Version); err != nil {
+		log.Debug("couldn't update round preview", slog.String("error", err.Error()))
+		return fail(pgError(err, versionError(version, ErrPreviewNotFound), nil))
+	}
+
+	return nil
+}
+
+// PublishPreview saves round of the preview and its mentorship changes in one
+// transaction with removing the preview, so it can be published only once.
+func (r *RoundRepository) PublishPreview(ctx context.Context, id string, round *entity.Round, ended []int, started []*entity.Mentorship) error {
+	const op = "Repo:PublishPreview"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("preview id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM round_preview WHERE id = $1::uuid AND expires_at > now()`, id)
+	if err != nil {
+		log.Debug("couldn't delete round preview", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrPreviewNotFound, nil))
+	}
+	if tag.RowsAffected() == 0 {
+		return fail(ErrPreviewNotFound)
+	}
+
+	if err = updateMentorships(ctx, tx, r.db.Builder, ended, started); err != nil {
+		log.Debug("couldn't update mentorships", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = insertRound(ctx, tx, r.db.Builder, round); err != nil {
+		log.Debug("couldn't insert round", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+func (r *RoundRepository) GetRound(ctx context.Context, id int) (*entity.Round, error) {
+	const op = "Repo:GetRound"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.Round, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("id, space_id, settings_version, coalesce(seed, 0), coalesce(strategy, ''), coalesce(strategy_version, 0), created_at").
+		From("round").
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	round := new(entity.Round)
+
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&round.ID, &round.SpaceID, &round.SettingsVersion, &round.Seed, &round.Strategy, &round.StrategyVersion, &round.CreatedAt)
+	if err != nil {
+		log.Debug("couldn't get round", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrRoundNotFound, nil))
+	}
+
+	query, args, err = r.db.Builder.
+		Select(meetingColumns).
+		From("meeting").
+		Where("round_id = ?", id).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't get meetings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	round.Meetings = make([]*entity.Meeting, 0)
+	for rows.Next() {
+		meeting, err := scanMeeting(rows)
+		if err != nil {
+			log.Debug("couldn't scan meeting", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		round.Meetings = append(round.Meetings, meeting)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	if err = getParticipants(ctx, r.db.Pool, r.db.Builder, round.Meetings...); err != nil {
+		log.Debug("couldn't get participants", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = r.db.Pool.QueryRow(ctx,
+		`SELECT coalesce(array_agg(user_id ORDER BY created_at, user_id), '{}') FROM rematch_pool WHERE round_id = $1`, id).
+		Scan(&round.RematchPool)
+	if err != nil {
+		log.Debug("couldn't get rematch pool", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return round, nil
+}
+
+// GetSnapshot returns inputs of the round and its meetings as they were matched.
+func (r *RoundRepository) GetSnapshot(ctx context.Context, roundId int) (*entity.RoundSnapshot, [][]int, error) {
+	const op = "Repo:GetSnapshot"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", roundId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.RoundSnapshot, [][]int, error) {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("snapshot, matched").
+		From("round_snapshot").
+		Where("round_id = ?", roundId).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	snapshot := new(entity.RoundSnapshot)
+	var matched [][]int
+
+	if err = r.db.Pool.QueryRow(ctx, query, args...).Scan(snapshot, &matched); err != nil {
+		log.Debug("couldn't get round snapshot", slog.String("error", err.Error()))
+		return fail(pgError(err, entity.ErrSnapshotNotFound, nil))
+	}
+
+	return snapshot, matched, nil
+}
+
+// Paths of stored snapshots and previews holding user IDs, $ids are the
+// erased users. They only find the rows, entity Erase removes the users.
+var (
+	snapshotPaths = []string{
+		`$.members[*].user_id ? (@ == $ids[*])`,
+		`$.pending[*] ? (@ == $ids[*])`,
+		`$.banned[*] ? (@ == $ids[*])`,
+		`$.held[*][*] ? (@ == $ids[*])`,
+		`$.mentorships[*] ? (@.mentor_id == $ids[*] || @.mentee_id == $ids[*])`,
+		`$.overrides[*].user_ids[*] ? (@ == $ids[*])`,
+		`$.overrides[*].groups[*][*] ? (@ == $ids[*])`,
+	}
+	previewPaths = []string{
+		`$.meetings[*].participants[*] ? (@ == $ids[*])`,
+		`$.unmatched[*].user_id ? (@ == $ids[*])`,
+		`$.no_overlap[*] ? (@ == $ids[*])`,
+		`$.overrides[*].user_ids[*] ? (@ == $ids[*])`,
+		`$.overrides[*].groups[*][*] ? (@ == $ids[*])`,
+		`$.started_mentorships[*] ? (@.mentor_id == $ids[*] || @.mentee_id == $ids[*])`,
+	}
+)
+
+// mentioning returns condition on the jsonb column holding any of $1 user IDs at the paths.
+func mentioning(column string, paths ...string) string {
+	conditions := make([]string, 0, len(paths))
+	for _, path := range paths {
+		conditions = append(conditions, fmt.Sprintf("jsonb_path_exists(%s, '%s', jsonb_build_object('ids', $1::int[]))", column, path))
+	}
+	return strings.Join(conditions, " OR ")
+}
+
+// erasePreviews deletes previews the users made and removes the users from
+// other previews holding them, marking those stale so admins see why they
+// changed.
+func erasePreviews(ctx context.Context, db database.Database, userIds []int) error {
+	if _, err := db.Exec(ctx, `DELETE FROM round_preview WHERE admin_id = ANY($1)`, userIds); err != nil {
+		return err
+	}
+
+	snapshotOfPreview := make([]string, 0, len(snapshotPaths))
+	for _, path := range snapshotPaths {
+		snapshotOfPreview = append(snapshotOfPreview, "$.snapshot"+strings.TrimPrefix(path, "$"))
+	}
+
+	rows, err := db.Query(ctx, `SELECT preview FROM round_preview WHERE `+
+		mentioning("preview", append(previewPaths, snapshotOfPreview...)...)+` FOR UPDATE`, userIds)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	erased := make([]*entity.RoundPreview, 0)
+	for rows.Next() {
+		preview := new(entity.RoundPreview)
+		if err = rows.Scan(preview); err != nil {
+			return err
+		}
+
+		changed := false
+		for _, id := range userIds {
+			if preview.Erase(id) {
+				changed = true
+			}
+		}
+		if changed {
+			erased = append(erased, preview)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// version changes, so overrides read before the erasure fail
+	for _, preview := range erased {
+		_, err = db.Exec(ctx, `UPDATE round_preview SET preview = $2, version = version + 1 WHERE id = $1::uuid`, preview.ID, preview)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eraseSnapshots removes the users from round snapshots holding them, so the
+// snapshots keep no tags or availability of the users. The rest of a snapshot
+// stays for replays, marked redacted.
+func eraseSnapshots(ctx context.Context, db database.Database, userIds []int) error {
+	rows, err := db.Query(ctx, `SELECT round_id, snapshot, matched FROM round_snapshot WHERE `+
+		mentioning("snapshot", snapshotPaths...)+` OR `+mentioning("matched", `$[*][*] ? (@ == $ids[*])`)+` FOR UPDATE`, userIds)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type stored struct {
+		roundID  int
+		snapshot *entity.RoundSnapshot
+		matched  [][]int
+	}
+
+	erased := make([]stored, 0)
+	for rows.Next() {
+		s := stored{snapshot: new(entity.RoundSnapshot)}
+		if err = rows.Scan(&s.roundID, s.snapshot, &s.matched); err != nil {
+			return err
+		}
+
+		changed := false
+		for _, id := range userIds {
+			inSnapshot := s.snapshot.Erase(id)
+			var inMatched bool
+			s.matched, inMatched = entity.EraseFromGroups(s.matched, id, entity.MinGroupSize)
+			if inSnapshot || inMatched {
+				changed = true
+			}
+		}
+		if changed {
+			s.snapshot.Redacted = true
+			erased = append(erased, s)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range erased {
+		_, err = db.Exec(ctx, `UPDATE round_snapshot SET snapshot = $2, matched = $3 WHERE round_id = $1`, s.roundID, s.snapshot, s.matched)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddToPool puts members left without a partner by the meeting into the
+// rematch pool of its round, members already there stay as they are.
+func (r *RoundRepository) AddToPool(ctx context.Context, roundId, meetingId int, userIds []int) error {
+	const op = "Repo:AddToPool"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", roundId),
+		slog.Int("meeting id", meetingId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	builder := r.db.Builder.
+		Insert("rematch_pool").
+		Columns("round_id, user_id, meeting_id").
+		Suffix("ON CONFLICT (round_id, user_id) DO NOTHING")
+	for _, userId := range userIds {
+		builder = builder.Values(roundId, userId, meetingId)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if _, err = r.db.Pool.Exec(ctx, query, args...); err != nil {
+		log.Debug("couldn't insert data in rematch_pool", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	return nil
+}
+
+// Rematch takes matched members out of the rematch pool of the round, saves
+// meetings created for them and meetings they joined, in one transaction.
+// errs.ErrVersionMismatch means another rematch or change got there first.
+func (r *RoundRepository) Rematch(ctx context.Context, roundId int, matched []int, created, joined []*entity.Meeting) error {
+	const op = "Repo:Rematch"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", roundId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM rematch_pool WHERE round_id = $1 AND user_id = ANY($2)`, roundId, matched)
+	if err != nil {
+		log.Debug("couldn't delete data from rematch_pool", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	if tag.RowsAffected() != int64(len(matched)) {
+		return fail(errs.ErrVersionMismatch)
+	}
+
+	for _, meeting := range created {
+		if err = insertMeeting(ctx, tx, r.db.Builder, meeting); err != nil {
+			log.Debug("couldn't insert meeting", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
+	for _, meeting := range joined {
+		if err = updateMeeting(ctx, tx, r.db.Builder, meeting, meeting.Version); err != nil {
+			log.Debug("couldn't update meeting", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// HeldMeetings returns participants of meetings held in the last rounds of the space.
+func (r *RoundRepository) HeldMeetings(ctx context.Context, spaceId, rounds int) ([][]int, error) {
+	const op = "Repo:HeldMeetings"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([][]int, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if rounds <= 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT array_agg(mp.user_id ORDER BY mp.user_id)
+		FROM meeting m
+		JOIN meeting_participant mp ON mp.meeting_id = m.id
+		WHERE m.state = $1
+		  AND mp.response <> $4
+		  AND m.round_id IN (SELECT id FROM round WHERE space_id = $2 ORDER BY id DESC LIMIT $3)
+		GROUP BY m.id`, entity.MeetingHeld, spaceId, rounds, entity.ResponseDeclined)
+	if err != nil {
+		log.Debug("couldn't get held meetings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	groups := make([][]int, 0)
+	for rows.Next() {
+		var group []int
+		if err = rows.Scan(&group); err != nil {
+			return fail(err)
+		}
+
+		groups = append(groups, group)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErasePaths checks paths finding erased users name stored JSON keys.
+func TestErasePaths(t *testing.T) {
+	snapshot := &entity.RoundSnapshot{
+		Members:     []entity.SnapshotMember{{UserID: 1}},
+		Pending:     []int{2},
+		Banned:      []int{3},
+		Held:        [][]int{{1, 2}},
+		Mentorships: []*entity.Mentorship{{MentorID: 1, MenteeID: 2}},
+		Overrides:   []entity.Override{{Kind: entity.OverrideSwap, UserIDs: []int{1, 2}, Groups: [][]int{{1, 3}}}},
+	}
+	preview := &entity.RoundPreview{
+		Meetings:           []*entity.PlannedMeeting{{Participants: []int{1, 2}}},
+		Unmatched:          []entity.Exclusion{{UserID: 3}},
+		NoOverlap:          []int{3},
+		Overrides:          snapshot.Overrides,
+		StartedMentorships: snapshot.Mentorships,
+	}
+
+	keys := regexp.MustCompile(`\.([a-z_]+)`)
+	check := func(doc any, paths []string) {
+		data, err := json.Marshal(doc)
+		assert.NoError(t, err)
+
+		for _, path := range paths {
+			for _, key := range keys.FindAllStringSubmatch(path, -1) {
+				assert.Contains(t, string(data), `"`+key[1]+`":`, "path %s", path)
+			}
+		}
+	}
+
+	check(snapshot, snapshotPaths)
+	check(preview, previewPaths)
+}
@@ -2,20 +2,24 @@ package repository
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
 	"github.com/Slava02/Involvio/pkg/database"
-	"github.com/jackc/pgerrcode"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5"
 	"log/slog"
 	"sync"
+	"time"
 )
 
 var (
-	ErrSpaceNotFound      = errors.New("space not found")
-	ErrSpaceAlreadyExists = errors.New("space already exists")
+	ErrSpaceNotFound      = errs.New(errs.NotFound, "space.not_found", "space not found")
+	ErrSpaceAlreadyExists = errs.New(errs.Conflict, "space.duplicate", "space already exists")
+	ErrMemberNotFound     = errs.New(errs.NotFound, "membership.not_found", "member not found")
+	// ErrMemberAlreadyExists is returned when user joins space twice.
+	ErrMemberAlreadyExists = errs.New(errs.Conflict, "membership.duplicate", "user is already space member")
+	ErrSettingsNotFound    = errs.New(errs.NotFound, "settings.not_found", "space settings version not found")
 )
 
 func NewSpaceRepository(once *sync.Once, db *database.Postgres) *SpaceRepository {
@@ -31,7 +35,8 @@ type SpaceRepository struct {
 	db *database.Postgres
 }
 
-func (r *SpaceRepository) UpdateSpace(ctx context.Context, id int, name, description string) error {
+// UpdateSpace updates space of the given version, zero version updates any.
+func (r *SpaceRepository) UpdateSpace(ctx context.Context, id int, name, description string, tags entity.Tags, version int) error {
 	const op = "Repo:UpdateSpace"
 
 	log := slog.With(
@@ -43,21 +48,31 @@ func (r *SpaceRepository) UpdateSpace(ctx context.Context, id int, name, descrip
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	query, args, err := r.db.Builder.
+	builder := r.db.Builder.
 		Update("space").
 		Set("name", name).
 		Set("description", description).
-		Where("id = ?", id).
-		ToSql()
+		Set("tags", tags).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ? AND deleted_at IS NULL", id)
+	if version > 0 {
+		builder = builder.Where("version = ?", version)
+	}
+
+	query, args, err := builder.ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
 	if err != nil {
-		log.Debug("couldn't update space: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't update space", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(versionError(version, ErrSpaceNotFound))
 	}
 
 	return nil
@@ -73,36 +88,32 @@ func (r *SpaceRepository) GetSpace(ctx context.Context, id int) (*entity.Space,
 	log.Debug(op)
 
 	fail := func(err error) (*entity.Space, error) {
-		return nil, fmt.Errorf("%r: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	query, args, err := r.db.Builder.
-		Select("id, name, description, tags").
+		Select("id, name, description, timezone, join_approval, tags, version").
 		From("space").
-		Where("id = ?", id).
+		Where("id = ? AND deleted_at IS NULL", id).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	space := new(entity.Space)
 
-	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&space.ID, &space.Name, &space.Description, &space.Tags)
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&space.ID, &space.Name, &space.Description, &space.Timezone, &space.JoinApproval, &space.Tags, &space.Version)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Debug("space not found: ", err.Error())
-			return fail(ErrSpaceNotFound)
-		} else {
-			log.Debug("error: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrSpaceNotFound, nil))
 	}
 
 	return space, nil
 }
 
-func (r *SpaceRepository) InsertSpace(ctx context.Context, userId int, space *entity.Space) error {
+// InsertSpace creates space with its creator and the first version of settings.
+func (r *SpaceRepository) InsertSpace(ctx context.Context, userId int, space *entity.Space, settings entity.SpaceSettings) error {
 	const op = "Repo:InsertSpace"
 
 	log := slog.With(
@@ -116,11 +127,12 @@ func (r *SpaceRepository) InsertSpace(ctx context.Context, userId int, space *en
 
 	querySpace, argsSpace, err := r.db.Builder.
 		Insert("space").
-		Columns("id, name, description, tags").
-		Values(space.ID, space.Name, space.Description, space.Tags).
+		Columns("id, name, description, timezone, join_approval, tags").
+		Values(space.ID, space.Name, space.Description, space.Timezone, space.JoinApproval, space.Tags).
+		Suffix("RETURNING version").
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -130,7 +142,17 @@ func (r *SpaceRepository) InsertSpace(ctx context.Context, userId int, space *en
 		Values(userId, space.ID, true, true).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	querySettings, argsSettings, err := r.db.Builder.
+		Insert("space_settings").
+		Columns("space_id, version, settings").
+		Values(space.ID, 1, settings).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -140,26 +162,33 @@ func (r *SpaceRepository) InsertSpace(ctx context.Context, userId int, space *en
 	}
 	defer tx.Rollback(ctx)
 
-	_, err = r.db.Pool.Exec(ctx, querySpace, argsSpace...)
+	err = tx.QueryRow(ctx, querySpace, argsSpace...).Scan(&space.Version)
 	if err != nil {
-		log.Debug("couldn't insert data in space: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't insert data in space", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, ErrSpaceAlreadyExists))
 	}
 
-	_, err = r.db.Pool.Exec(ctx, queryUserSpace, argsUserSpace...)
+	_, err = tx.Exec(ctx, queryUserSpace, argsUserSpace...)
 	if err != nil {
-		log.Debug("couldn't insert data in space: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't insert data in user_space", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	_, err = tx.Exec(ctx, querySettings, argsSettings...)
+	if err != nil {
+		log.Debug("couldn't insert data in space_settings", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		log.Debug("couldn't commit transaction: ", err.Error())
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	return nil
 }
 
+// DeleteSpace soft deletes space together with its memberships and events.
 func (r *SpaceRepository) DeleteSpace(ctx context.Context, id int) error {
 	const op = "Repo:DeleteSpace"
 
@@ -173,52 +202,103 @@ func (r *SpaceRepository) DeleteSpace(ctx context.Context, id int) error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	querySpace, argsSpace, err := r.db.Builder.
-		Delete("space").
-		Where("id = ?", id).
-		ToSql()
+	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
 		return fail(err)
 	}
+	defer tx.Rollback(ctx)
 
-	queryUserSpace, argsUserSpace, err := r.db.Builder.
-		Delete("user_space").
-		Where("space_id = ?", id).
-		ToSql()
+	// now() is the transaction start time, so all rows get the same deleted_at
+	tag, err := tx.Exec(ctx, `UPDATE space SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`, id)
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't delete space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fail(ErrSpaceNotFound)
+	}
+
+	cascade := []string{
+		`UPDATE user_space SET deleted_at = now() WHERE space_id = $1 AND deleted_at IS NULL`,
+		`UPDATE user_event SET deleted_at = now()
+			WHERE event_id IN (SELECT id FROM event WHERE space_id = $1 AND deleted_at IS NULL) AND deleted_at IS NULL`,
+		`UPDATE event SET deleted_at = now() WHERE space_id = $1 AND deleted_at IS NULL`,
+	}
+
+	for _, query := range cascade {
+		if _, err = tx.Exec(ctx, query, id); err != nil {
+			log.Debug("couldn't delete space data", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// RestoreSpace reverts DeleteSpace: rows deleted together with the space are restored,
+// memberships and events deleted before it stay deleted.
+func (r *SpaceRepository) RestoreSpace(ctx context.Context, id int) error {
+	const op = "Repo:RestoreSpace"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", id),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
 		return fail(err)
 	}
 	defer tx.Rollback(ctx)
 
-	_, err = r.db.Pool.Exec(ctx, queryUserSpace, argsUserSpace...)
+	var deletedAt time.Time
+
+	err = tx.QueryRow(ctx, `SELECT deleted_at FROM space WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&deletedAt)
 	if err != nil {
-		log.Debug("couldn't delete data from user_space: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't get deleted space", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrSpaceNotFound, nil))
 	}
 
-	_, err = r.db.Pool.Exec(ctx, querySpace, argsSpace...)
+	_, err = tx.Exec(ctx, `UPDATE space SET deleted_at = NULL WHERE id = $1`, id)
 	if err != nil {
-		log.Debug("couldn't delete data from space: ", err.Error())
+		log.Debug("couldn't restore space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
+	cascade := []string{
+		`UPDATE user_space SET deleted_at = NULL WHERE space_id = $1 AND deleted_at = $2`,
+		`UPDATE event SET deleted_at = NULL WHERE space_id = $1 AND deleted_at = $2`,
+		`UPDATE user_event SET deleted_at = NULL
+			WHERE event_id IN (SELECT id FROM event WHERE space_id = $1) AND deleted_at = $2`,
+	}
+
+	for _, query := range cascade {
+		if _, err = tx.Exec(ctx, query, id, deletedAt); err != nil {
+			log.Debug("couldn't restore space data", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
 	if err = tx.Commit(ctx); err != nil {
-		log.Debug("couldn't commit transaction: ", err.Error())
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	return nil
 }
 
-// TODO: err ALready Exists
-func (r *SpaceRepository) AddUser(ctx context.Context, userId, spaceId int) error {
+func (r *SpaceRepository) AddUser(ctx context.Context, userId, spaceId int, status entity.MemberStatus) error {
 	const op = "Repo:AddUserToSpace"
 
 	log := slog.With(
@@ -233,33 +313,610 @@ func (r *SpaceRepository) AddUser(ctx context.Context, userId, spaceId int) erro
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	// a kicked or left user joins again with a fresh membership in place of the soft deleted one
 	query, args, err := r.db.Builder.
 		Insert("user_space").
-		Columns("user_id, space_id, is_admin, is_creator").
-		Values(userId, spaceId, false, false).
+		Columns("user_id, space_id, is_admin, is_creator, status").
+		Values(userId, spaceId, false, false, status).
+		Suffix(`ON CONFLICT (user_id, space_id) DO UPDATE SET
+			is_admin = false, is_creator = false, status = EXCLUDED.status, ban_reason = NULL,
+			user_tags = NULL, pair_tags = NULL, availability = NULL, match_role = NULL, capacity = NULL, joined_at = now(), deleted_at = NULL
+			WHERE user_space.deleted_at IS NOT NULL`).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err == nil && tag.RowsAffected() == 0 {
+		return fail(ErrMemberAlreadyExists)
+	}
+	if err != nil {
+		log.Debug("couldn't insert data in user_space", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, ErrMemberAlreadyExists))
+	}
+
+	return nil
+}
+
+func (r *SpaceRepository) GetMembers(ctx context.Context, spaceId int, status entity.MemberStatus, limit, offset int) ([]*entity.Member, int, error) {
+	const op = "Repo:GetMembers"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.Member, int, error) {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	filter := squirrel.And{
+		squirrel.Eq{"us.space_id": spaceId},
+		squirrel.Expr("us.deleted_at IS NULL"),
+		squirrel.Expr("u.deleted_at IS NULL"),
+	}
+	if status != "" {
+		filter = append(filter, squirrel.Eq{"us.status": status})
+	}
+
+	countQuery, countArgs, err := r.db.Builder.
+		Select("count(*)").
+		From("user_space us").
+		Join("\"user\" u ON u.id = us.user_id").
+		Where(filter).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	query, args, err := r.db.Builder.
+		Select(memberColumns).
+		From("user_space us").
+		Join("\"user\" u ON u.id = us.user_id").
+		Where(filter).
+		OrderBy("us.joined_at", "us.user_id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case pgerrcode.UniqueViolation:
-				log.Debug("couldn't insert data in user_space: ", err.Error())
-				return fail(ErrSpaceAlreadyExists)
-			default:
-				log.Debug("couldn't insert data in user_space: ", err.Error())
-				return fail(err)
-			}
-		} else {
-			log.Debug("couldn't insert data in user_space: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	var total int
+	if err = r.db.Pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		log.Debug("couldn't count members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't get members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	members := make([]*entity.Member, 0, limit)
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			log.Debug("couldn't scan member", slog.String("error", err.Error()))
 			return fail(err)
 		}
+
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return members, total, nil
+}
+
+func (r *SpaceRepository) GetMember(ctx context.Context, spaceId, userId int) (*entity.Member, error) {
+	const op = "Repo:GetMember"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.Member, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select(memberColumns).
+		From("user_space us").
+		Join("\"user\" u ON u.id = us.user_id").
+		Where(squirrel.Eq{"us.space_id": spaceId, "us.user_id": userId}).
+		Where("us.deleted_at IS NULL AND u.deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	member, err := scanMember(r.db.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		log.Debug("couldn't get member", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrMemberNotFound, nil))
+	}
+
+	return member, nil
+}
+
+func (r *SpaceRepository) SetMemberStatus(ctx context.Context, spaceId, userId int, status entity.MemberStatus, reason string) error {
+	const op = "Repo:SetMemberStatus"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+		slog.String("status", string(status)),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("user_space").
+		Set("status", status).
+		Set("ban_reason", reason).
+		Where(squirrel.Eq{"space_id": spaceId, "user_id": userId}).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't update user_space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(ErrMemberNotFound)
 	}
 
 	return nil
 }
+
+// SetMatchRole sets the mentoring role of the member, empty role and zero
+// capacity clear them.
+func (r *SpaceRepository) SetMatchRole(ctx context.Context, spaceId, userId int, role string, capacity int) error {
+	const op = "Repo:SetMatchRole"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+		slog.String("role", role),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("user_space").
+		Set("match_role", squirrel.Expr("NULLIF(?, '')", role)).
+		Set("capacity", squirrel.Expr("NULLIF(?, 0)", capacity)).
+		Where(squirrel.Eq{"space_id": spaceId, "user_id": userId}).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't update user_space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(ErrMemberNotFound)
+	}
+
+	return nil
+}
+
+func (r *SpaceRepository) RemoveUser(ctx context.Context, spaceId, userId int) error {
+	const op = "Repo:RemoveUserFromSpace"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("user_space").
+		Set("deleted_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"space_id": spaceId, "user_id": userId}).
+		Where("deleted_at IS NULL").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't delete data from user_space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(ErrMemberNotFound)
+	}
+
+	return nil
+}
+
+// RestoreMember restores membership removed by RemoveUser.
+func (r *SpaceRepository) RestoreMember(ctx context.Context, spaceId, userId int) error {
+	const op = "Repo:RestoreMember"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Update("user_space").
+		Set("deleted_at", nil).
+		Where(squirrel.Eq{"space_id": spaceId, "user_id": userId}).
+		Where("deleted_at IS NOT NULL").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't restore data in user_space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(ErrMemberNotFound)
+	}
+
+	return nil
+}
+
+// GetDeletedSpaceMember returns member whose membership was deleted together with the space.
+func (r *SpaceRepository) GetDeletedSpaceMember(ctx context.Context, spaceId, userId int) (*entity.Member, error) {
+	const op = "Repo:GetDeletedSpaceMember"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.Member, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select(memberColumns).
+		From("user_space us").
+		Join("\"user\" u ON u.id = us.user_id").
+		Join("space s ON s.id = us.space_id").
+		Where(squirrel.Eq{"us.space_id": spaceId, "us.user_id": userId}).
+		Where("us.deleted_at = s.deleted_at").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	member, err := scanMember(r.db.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		log.Debug("couldn't get member", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrMemberNotFound, nil))
+	}
+
+	return member, nil
+}
+
+const memberColumns = "u.id, u.first_name, u.last_name, u.username, u.photo_url, u.auth_date, " +
+	"us.is_admin, us.is_creator, us.status, COALESCE(us.ban_reason, ''), us.user_tags, us.pair_tags, us.availability, " +
+	"COALESCE(us.match_role, ''), COALESCE(us.capacity, 0), us.joined_at"
+
+// GetSettings returns the given version of space settings, zero version returns the current one.
+func (r *SpaceRepository) GetSettings(ctx context.Context, spaceId, version int) (*entity.SpaceSettingsVersion, error) {
+	const op = "Repo:GetSettings"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+		slog.Int("version", version),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (*entity.SpaceSettingsVersion, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	builder := r.db.Builder.
+		Select("space_id, version, settings, created_at").
+		From("space_settings").
+		Where("space_id = ?", spaceId).
+		OrderBy("version DESC").
+		Limit(1)
+	if version > 0 {
+		builder = builder.Where("version = ?", version)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	settings := new(entity.SpaceSettingsVersion)
+
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&settings.SpaceID, &settings.Version, &settings.SpaceSettings, &settings.CreatedAt)
+	if err != nil {
+		log.Debug("couldn't get settings", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrSettingsNotFound, nil))
+	}
+
+	return settings, nil
+}
+
+// InsertSettings saves a new version of space settings based on the given
+// version, zero version is based on any. Space timezone and join approval
+// follow the settings, so the space version is incremented too.
+func (r *SpaceRepository) InsertSettings(ctx context.Context, spaceId int, settings entity.SpaceSettings, version int) error {
+	const op = "Repo:InsertSettings"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	// space row lock serializes concurrent updates of settings
+	tag, err := tx.Exec(ctx, `SELECT 1 FROM space WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, spaceId)
+	if err != nil {
+		log.Debug("couldn't lock space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fail(ErrSpaceNotFound)
+	}
+
+	var current int
+	err = tx.QueryRow(ctx, `SELECT coalesce(max(version), 0) FROM space_settings WHERE space_id = $1`, spaceId).Scan(&current)
+	if err != nil {
+		log.Debug("couldn't get settings version", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if version > 0 && version != current {
+		return fail(errs.ErrVersionMismatch)
+	}
+
+	query, args, err := r.db.Builder.
+		Insert("space_settings").
+		Columns("space_id, version, settings").
+		Values(spaceId, current+1, settings).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		log.Debug("couldn't insert data in space_settings", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, errs.ErrVersionMismatch))
+	}
+
+	query, args, err = r.db.Builder.
+		Update("space").
+		Set("timezone", settings.Timezone).
+		Set("join_approval", settings.JoinMode == entity.JoinApproval).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ?", spaceId).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		log.Debug("couldn't update space", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// ExistingUsers reports which of ids belong to not deleted users.
+func (r *SpaceRepository) ExistingUsers(ctx context.Context, ids []int) (map[int]bool, error) {
+	const op = "Repo:ExistingUsers"
+
+	log := slog.With(
+		slog.String("op", op),
+	)
+	log.Debug(op)
+
+	fail := func(err error) (map[int]bool, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	existing := make(map[int]bool, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	query, args, err := r.db.Builder.
+		Select("id").
+		From("\"user\"").
+		Where(squirrel.Expr("id = ANY(?)", ids)).
+		Where(squirrel.Expr("deleted_at IS NULL")).
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't get users", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return fail(err)
+		}
+		existing[id] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return existing, nil
+}
+
+// ImportMembers creates users and adds members as active in one transaction.
+// Any existing membership fails the whole import with ErrMemberAlreadyExists.
+func (r *SpaceRepository) ImportMembers(ctx context.Context, spaceId int, users []*entity.User, members []*entity.Member) error {
+	const op = "Repo:ImportMembers"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, user := range users {
+		query, args, err := r.db.Builder.
+			Insert("\"user\"").
+			Columns("id, first_name, last_name, username, photo_url, auth_date").
+			Values(user.ID, user.FirstName, user.LastName, user.UserName, user.PhotoURL, user.AuthDate).
+			Suffix("RETURNING version").
+			ToSql()
+		if err != nil {
+			log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		if err = tx.QueryRow(ctx, query, args...).Scan(&user.Version); err != nil {
+			log.Debug("couldn't insert data in user", slog.String("error", err.Error()))
+			return fail(pgError(err, nil, ErrUserAlreadyExists))
+		}
+	}
+
+	for _, member := range members {
+		// same revival of a soft deleted membership as in AddUser
+		query, args, err := r.db.Builder.
+			Insert("user_space").
+			Columns("user_id, space_id, is_admin, is_creator, status, user_tags").
+			Values(member.User.ID, spaceId, false, false, entity.StatusActive, member.UserTags).
+			Suffix(`ON CONFLICT (user_id, space_id) DO UPDATE SET
+				is_admin = false, is_creator = false, status = EXCLUDED.status, ban_reason = NULL,
+				user_tags = EXCLUDED.user_tags, pair_tags = NULL, availability = NULL, match_role = NULL, capacity = NULL, joined_at = now(), deleted_at = NULL
+				WHERE user_space.deleted_at IS NOT NULL`).
+			ToSql()
+		if err != nil {
+			log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		tag, err := tx.Exec(ctx, query, args...)
+		if err == nil && tag.RowsAffected() == 0 {
+			return fail(ErrMemberAlreadyExists)
+		}
+		if err != nil {
+			log.Debug("couldn't insert data in user_space", slog.String("error", err.Error()))
+			return fail(pgError(err, nil, ErrMemberAlreadyExists))
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+func scanMember(row pgx.Row) (*entity.Member, error) {
+	var admin, creator bool
+
+	member := &entity.Member{User: new(entity.User)}
+
+	err := row.Scan(
+		&member.User.ID, &member.User.FirstName, &member.User.LastName, &member.User.UserName, &member.User.PhotoURL, &member.User.AuthDate,
+		&admin, &creator, &member.Status, &member.BanReason, &member.UserTags, &member.PairTags, &member.Availability,
+		&member.MatchRole, &member.Capacity, &member.JoinedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Role = entity.MemberRoleOf(admin, creator)
+
+	return member, nil
+}
@@ -2,20 +2,19 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
 	"github.com/Slava02/Involvio/pkg/database"
-	"github.com/jackc/pgerrcode"
-	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/pkg/errors"
+	"github.com/jackc/pgx/v5"
 	"log/slog"
 	"sync"
 )
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserNotFound      = errs.New(errs.NotFound, "user.not_found", "user not found")
+	ErrUserAlreadyExists = errs.New(errs.Conflict, "user.duplicate", "user already exists")
 )
 
 func NewUserRepository(once *sync.Once, db *database.Postgres) *UserRepository {
@@ -41,30 +40,25 @@ func (r *UserRepository) GetUserData(ctx context.Context, id int) (*entity.User,
 	log.Debug(op)
 
 	fail := func(err error) (*entity.User, error) {
-		return nil, fmt.Errorf("%r: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	query, args, err := r.db.Builder.
-		Select("id, first_name, last_name, username, photo_url, auth_date").
+		Select("id, first_name, last_name, username, photo_url, auth_date, version").
 		From("\"user\"").
-		Where("id = ?", id).
+		Where("id = ? AND deleted_at IS NULL", id).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	user := new(entity.User)
 
-	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.PhotoURL, &user.AuthDate)
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.PhotoURL, &user.AuthDate, &user.Version)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Debug("user not found: ", err.Error())
-			return fail(ErrUserNotFound)
-		} else {
-			log.Debug("error: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrUserNotFound, nil))
 	}
 
 	return user, nil
@@ -80,7 +74,7 @@ func (r *UserRepository) GetUserForms(ctx context.Context, userId int) ([]*entit
 	log.Debug(op)
 
 	fail := func(err error) ([]*entity.Form, error) {
-		return nil, fmt.Errorf("%r: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	// TODO: fix bug
@@ -91,29 +85,25 @@ func (r *UserRepository) GetUserForms(ctx context.Context, userId int) ([]*entit
 	//	Where("user_id = ?::int", userId).
 	//	ToSql()
 	//if err != nil {
-	//	log.Debug("couldn't create SQL statement: ", err.Error())
+	//	log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 	//	return fail(err)
 	//}
 
-	query := `SELECT space_id, is_admin, is_creator, user_tags, pair_tags FROM user_space WHERE user_id = $1`
+	query := `SELECT space_id, is_admin, is_creator, user_tags, pair_tags, availability, version FROM user_space WHERE user_id = $1 AND deleted_at IS NULL`
 
 	forms := make([]*entity.Form, 0)
 
 	rows, err := r.db.Pool.Query(ctx, query, userId)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Debug("user not found: ", err.Error())
-			return fail(ErrUserNotFound)
-		} else {
-			log.Debug("error: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't select forms", slog.String("error", err.Error()))
+		return fail(err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		form := new(entity.Form)
 
-		err = rows.Scan(&form.SpaceID, &form.Admin, &form.Creator, &form.UserTags, &form.PairTags)
+		err = rows.Scan(&form.SpaceID, &form.Admin, &form.Creator, &form.UserTags, &form.PairTags, &form.Availability, &form.Version)
 		if err != nil {
 			return fail(err)
 		}
@@ -141,34 +131,24 @@ func (r *UserRepository) InsertUser(ctx context.Context, user *entity.User) erro
 		Insert("\"user\"").
 		Columns("id, first_name, last_name, username, photo_url, auth_date").
 		Values(user.ID, user.FirstName, user.LastName, user.UserName, user.PhotoURL, user.AuthDate).
+		Suffix("RETURNING version").
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&user.Version)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case pgerrcode.UniqueViolation:
-				log.Debug("couldn't insert data in user: ", err.Error())
-				return fail(ErrUserAlreadyExists)
-			default:
-				log.Debug("couldn't insert data in user: ", err.Error())
-				return fail(err)
-			}
-		} else {
-			log.Debug("couldn't insert data in user: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't insert data in user", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, ErrUserAlreadyExists))
 	}
 
 	return nil
 }
 
-func (r *UserRepository) UpdateUser(ctx context.Context, id int, firstName, lastName, userName, photoURL string) (*entity.User, error) {
+// UpdateUser updates user of the given version, zero version updates any.
+func (r *UserRepository) UpdateUser(ctx context.Context, id int, firstName, lastName, userName, photoURL string, version int) (*entity.User, error) {
 	const op = "Repo:UpdateUser"
 
 	log := slog.With(
@@ -180,28 +160,37 @@ func (r *UserRepository) UpdateUser(ctx context.Context, id int, firstName, last
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	query, args, err := r.db.Builder.
+	builder := r.db.Builder.
 		Update("\"user\"").
 		Set("first_name", firstName).
 		Set("last_name", lastName).
 		Set("username", userName).
 		Set("photo_url", photoURL).
-		Where("id = ?", id).
-		ToSql()
+		Set("version", squirrel.Expr("version + 1")).
+		Where("id = ? AND deleted_at IS NULL", id)
+	if version > 0 {
+		builder = builder.Where("version = ?", version)
+	}
+
+	query, args, err := builder.ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
 	if err != nil {
-		log.Debug("couldn't update space: ", err.Error())
-		return fail(err)
+		log.Debug("couldn't update user", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(versionError(version, ErrUserNotFound))
 	}
 
 	user, err := r.GetUserData(ctx, id)
 	if err != nil {
-		log.Debug("couldn't get user: ", err.Error())
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -223,17 +212,18 @@ func (r *UserRepository) DeleteUser(ctx context.Context, userId, spaceId int) er
 	}
 
 	query, args, err := r.db.Builder.
-		Delete("user_space").
-		Where("user_id = ? AND space_id = ?", userId, spaceId).
+		Update("user_space").
+		Set("deleted_at", squirrel.Expr("now()")).
+		Where("user_id = ? AND space_id = ? AND deleted_at IS NULL", userId, spaceId).
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	_, err = r.db.Pool.Exec(ctx, query, args...)
 	if err != nil {
-		log.Debug("couldn't delete data from user_space: ", err.Error())
+		log.Debug("couldn't delete data from user_space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -251,7 +241,7 @@ func (r *UserRepository) GetForm(ctx context.Context, userId, spaceId int) (*ent
 	log.Debug(op)
 
 	fail := func(err error) (*entity.Form, error) {
-		return nil, fmt.Errorf("%r: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	//query, args, err := r.db.Builder.
@@ -260,29 +250,25 @@ func (r *UserRepository) GetForm(ctx context.Context, userId, spaceId int) (*ent
 	//	Where("user_id = ? AND space_id = ?", userId, spaceId).
 	//	ToSql()
 	//if err != nil {
-	//	log.Debug("couldn't create SQL statement: ", err.Error())
+	//	log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 	//	return fail(err)
 	//}
 
-	query := "SELECT space_id, is_admin, is_creator, user_tags, pair_tags FROM user_space WHERE user_id = $1 AND space_id = $2"
+	query := "SELECT space_id, is_admin, is_creator, user_tags, pair_tags, availability, version FROM user_space WHERE user_id = $1 AND space_id = $2 AND deleted_at IS NULL"
 
 	form := new(entity.Form)
 
-	err := r.db.Pool.QueryRow(ctx, query, userId, spaceId).Scan(&form.SpaceID, &form.Admin, &form.Creator, &form.UserTags, &form.PairTags)
+	err := r.db.Pool.QueryRow(ctx, query, userId, spaceId).Scan(&form.SpaceID, &form.Admin, &form.Creator, &form.UserTags, &form.PairTags, &form.Availability, &form.Version)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			log.Debug("form not found: ", err.Error())
-			return fail(ErrUserNotFound)
-		} else {
-			log.Debug("error: ", err.Error())
-			return fail(err)
-		}
+		log.Debug("couldn't get form", slog.String("error", err.Error()))
+		return fail(pgError(err, ErrMemberNotFound, nil))
 	}
 
 	return form, nil
 }
 
-func (r *UserRepository) UpdateForm(ctx context.Context, userId, spaceId int, userTags, pairTags entity.Tags) error {
+// UpdateForm updates form of the given version, zero version updates any.
+func (r *UserRepository) UpdateForm(ctx context.Context, userId, spaceId int, userTags, pairTags entity.Tags, availability *entity.Availability, version int) error {
 	const op = "Repo:UpdateUser"
 
 	log := slog.With(
@@ -294,20 +280,289 @@ func (r *UserRepository) UpdateForm(ctx context.Context, userId, spaceId int, us
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	query, args, err := r.db.Builder.
+	builder := r.db.Builder.
 		Update("user_space").
 		Set("user_tags", userTags).
 		Set("pair_tags", pairTags).
-		Where("user_id = ? AND space_id = ?", userId, spaceId).
+		Set("availability", availability).
+		Set("version", squirrel.Expr("version + 1")).
+		Where("user_id = ? AND space_id = ? AND deleted_at IS NULL", userId, spaceId)
+	if version > 0 {
+		builder = builder.Where("version = ?", version)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't update form", slog.String("error", err.Error()))
+		return fail(pgError(err, nil, nil))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(versionError(version, ErrMemberNotFound))
+	}
+
+	return nil
+}
+
+// GetMemberships returns all memberships of the user including deleted ones not yet purged.
+func (r *UserRepository) GetMemberships(ctx context.Context, userId int) ([]*entity.Membership, error) {
+	const op = "Repo:GetMemberships"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.Membership, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select(membershipColumns).
+		From("user_space us").
+		Join("space s ON s.id = us.space_id").
+		Where("us.user_id = ?", userId).
+		OrderBy("us.joined_at").
 		ToSql()
 	if err != nil {
-		log.Debug("couldn't create SQL statement: ", err.Error())
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		log.Debug("couldn't update form: ", err.Error())
+		log.Debug("couldn't select memberships", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	memberships := make([]*entity.Membership, 0)
+
+	for rows.Next() {
+		m, err := scanMembership(rows)
+		if err != nil {
+			return fail(err)
+		}
+
+		memberships = append(memberships, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return memberships, nil
+}
+
+// membershipColumns are scanned by scanMembership, only banned members have a ban reason.
+const membershipColumns = "us.space_id, s.name, us.is_admin, us.is_creator, us.status, COALESCE(us.ban_reason, ''), us.joined_at, us.deleted_at"
+
+func scanMembership(row pgx.Row) (*entity.Membership, error) {
+	var admin, creator bool
+	m := new(entity.Membership)
+
+	err := row.Scan(&m.SpaceID, &m.SpaceName, &admin, &creator, &m.Status, &m.BanReason, &m.JoinedAt, &m.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	m.Role = entity.MemberRoleOf(admin, creator)
+
+	return m, nil
+}
+
+// GetParticipations returns all events the user joined including deleted ones not yet purged.
+func (r *UserRepository) GetParticipations(ctx context.Context, userId int) ([]*entity.Participation, error) {
+	const op = "Repo:GetParticipations"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.Participation, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("e.id, e.space_id, e.name, e.begin_date, e.end_date, ue.deleted_at").
+		From("user_event ue").
+		Join("event e ON e.id = ue.event_id").
+		Where("ue.user_id = ?", userId).
+		OrderBy("e.begin_date").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't select participations", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	participations := make([]*entity.Participation, 0)
+
+	for rows.Next() {
+		p := new(entity.Participation)
+
+		err = rows.Scan(&p.EventID, &p.SpaceID, &p.Name, &p.BeginDate, &p.EndDate, &p.DeletedAt)
+		if err != nil {
+			return fail(err)
+		}
+
+		participations = append(participations, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return participations, nil
+}
+
+func (r *UserRepository) GetMeetings(ctx context.Context, userId int) ([]*entity.MeetingParticipation, error) {
+	const op = "Repo:GetMeetings"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) ([]*entity.MeetingParticipation, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.db.Builder.
+		Select("m.id, m.round_id, m.space_id, m.state, mp.response, mp.held, mp.no_show, m.scheduled_at, m.created_at").
+		From("meeting_participant mp").
+		Join("meeting m ON m.id = mp.meeting_id").
+		Where("mp.user_id = ?", userId).
+		OrderBy("m.created_at", "m.id").
+		ToSql()
+	if err != nil {
+		log.Debug("couldn't create SQL statement", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		log.Debug("couldn't select meetings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	defer rows.Close()
+
+	meetings := make([]*entity.MeetingParticipation, 0)
+
+	for rows.Next() {
+		m := new(entity.MeetingParticipation)
+
+		err = rows.Scan(&m.MeetingID, &m.RoundID, &m.SpaceID, &m.State, &m.Response, &m.Held, &m.NoShow, &m.ScheduledAt, &m.CreatedAt)
+		if err != nil {
+			return fail(err)
+		}
+
+		meetings = append(meetings, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fail(err)
+	}
+
+	return meetings, nil
+}
+
+// EraseUser removes the user and everything referencing them in one transaction.
+// Unlike soft deletion nothing is left for the retention job.
+func (r *UserRepository) EraseUser(ctx context.Context, userId int) error {
+	const op = "Repo:EraseUser"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", userId),
+	)
+	log.Debug(op)
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_event WHERE user_id = $1`, userId)
+	if err != nil {
+		log.Debug("couldn't delete data from user_event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM rematch_pool WHERE user_id = $1`, userId)
+	if err != nil {
+		log.Debug("couldn't delete data from rematch_pool", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM meeting_participant WHERE user_id = $1`, userId)
+	if err != nil {
+		log.Debug("couldn't delete data from meeting_participant", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = eraseReports(ctx, tx, []int{userId})
+	if err != nil {
+		log.Debug("couldn't erase user from no-show reports", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM mentorship WHERE mentor_id = $1 OR mentee_id = $1`, userId)
+	if err != nil {
+		log.Debug("couldn't delete data from mentorship", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = eraseSnapshots(ctx, tx, []int{userId})
+	if err != nil {
+		log.Debug("couldn't erase user from round_snapshot", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = erasePreviews(ctx, tx, []int{userId})
+	if err != nil {
+		log.Debug("couldn't erase user from round_preview", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM user_space WHERE user_id = $1`, userId)
+	if err != nil {
+		log.Debug("couldn't delete data from user_space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM "user" WHERE id = $1`, userId)
+	if err != nil {
+		log.Debug("couldn't delete user", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fail(ErrUserNotFound)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Debug("couldn't commit transaction", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
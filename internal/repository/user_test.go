@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+// row decodes text values as pgx decodes a fetched row, nil is NULL.
+type row struct {
+	oids   []uint32
+	values []*string
+}
+
+func (r row) Scan(dest ...any) error {
+	m := pgtype.NewMap()
+	for i, d := range dest {
+		var src []byte
+		if r.values[i] != nil {
+			src = []byte(*r.values[i])
+		}
+		if err := m.Scan(r.oids[i], pgtype.TextFormatCode, src, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var coalesced = regexp.MustCompile(`^COALESCE\((.+), '(.*)'\)$`)
+
+// splitColumns splits a select list on commas outside parentheses.
+func splitColumns(columns string) []string {
+	split := make([]string, 0)
+	depth, start := 0, 0
+	for i, c := range columns {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				split = append(split, strings.TrimSpace(columns[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(split, strings.TrimSpace(columns[start:]))
+}
+
+// selectColumns selects columns from a stored row, coalescing NULLs as the query does.
+func selectColumns(columns string, stored map[string]*string) []*string {
+	values := make([]*string, 0)
+	for _, column := range splitColumns(columns) {
+		if m := coalesced.FindStringSubmatch(column); m != nil {
+			if v := stored[m[1]]; v != nil {
+				values = append(values, v)
+			} else {
+				values = append(values, &m[2])
+			}
+			continue
+		}
+		values = append(values, stored[column])
+	}
+	return values
+}
+
+func TestScanMembership(t *testing.T) {
+	text := func(s string) *string { return &s }
+
+	oids := []uint32{pgtype.Int4OID, pgtype.TextOID, pgtype.BoolOID, pgtype.BoolOID, pgtype.TextOID, pgtype.TextOID,
+		pgtype.TimestamptzOID, pgtype.TimestamptzOID}
+
+	tests := []struct {
+		name      string
+		stored    map[string]*string
+		status    entity.MemberStatus
+		banReason string
+	}{
+		{"active member without ban reason", map[string]*string{
+			"us.space_id": text("7"), "s.name": text("MAI"), "us.is_admin": text("f"), "us.is_creator": text("f"),
+			"us.status": text("active"), "us.ban_reason": nil, "us.joined_at": text("2026-10-19 12:00:00+00"), "us.deleted_at": nil,
+		}, entity.StatusActive, ""},
+		{"banned member", map[string]*string{
+			"us.space_id": text("7"), "s.name": text("MAI"), "us.is_admin": text("f"), "us.is_creator": text("f"),
+			"us.status": text("banned"), "us.ban_reason": text("spam"), "us.joined_at": text("2026-10-19 12:00:00+00"), "us.deleted_at": nil,
+		}, entity.StatusBanned, "spam"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := scanMembership(row{oids: oids, values: selectColumns(membershipColumns, tt.stored)})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 7, m.SpaceID)
+			assert.Equal(t, tt.status, m.Status)
+			assert.Equal(t, tt.banReason, m.BanReason)
+			assert.Equal(t, entity.RoleMember, m.Role)
+			assert.Nil(t, m.DeletedAt)
+		})
+	}
+}
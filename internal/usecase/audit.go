@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"github.com/Slava02/Involvio/pkg/reqctx"
+	"log/slog"
+)
+
+type IAuditRepository interface {
+	InsertEntry(ctx context.Context, entry *entity.AuditEntry) error
+	GetEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, int, error)
+}
+
+func NewAuditUseCase(ar IAuditRepository, sr ISpaceRepository) *AuditUseCase {
+	return &AuditUseCase{auditRepo: ar, spaceRepo: sr}
+}
+
+type AuditUseCase struct {
+	auditRepo IAuditRepository
+	spaceRepo ISpaceRepository
+}
+
+// GetAuditLog returns audit entries of the space to its admins.
+func (ac *AuditUseCase) GetAuditLog(ctx context.Context, cmd commands.AuditLogCommand) ([]*entity.AuditEntry, int, error) {
+	const op = "Usecase:GetAuditLog"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	err := checkAdmin(ctx, ac.spaceRepo, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	entries, total, err := ac.auditRepo.GetEntries(ctx, entity.AuditFilter{
+		SpaceID:    cmd.SpaceID,
+		ActorID:    cmd.ActorID,
+		Action:     cmd.Action,
+		TargetType: cmd.TargetType,
+		Since:      cmd.Since,
+		Until:      cmd.Until,
+		Limit:      cmd.Limit,
+		Offset:     cmd.Offset,
+	})
+	if err != nil {
+		log.Debug("couldn't get audit entries", slog.String("error", err.Error()))
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, total, nil
+}
+
+// auditor records audit entries on behalf of usecase decorators.
+type auditor struct {
+	auditRepo IAuditRepository
+}
+
+// audited runs call and, if it succeeds, records entry with the difference between
+// before and after states of the target. after receives call result, so it may also
+// fill entry fields known only after the call, e.g. id of a created space.
+func audited[T any](ctx context.Context, a *auditor, entry *entity.AuditEntry, before any, call func() (T, error), after func(T) any) (T, error) {
+	res, err := call()
+	if err != nil {
+		return res, err
+	}
+
+	var state any
+	if after != nil {
+		state = after(res)
+	}
+
+	a.record(ctx, entry, before, state)
+
+	return res, nil
+}
+
+// auditedErr is audited for calls returning only an error.
+func auditedErr(ctx context.Context, a *auditor, entry *entity.AuditEntry, before any, call func() error, after func() any) error {
+	_, err := audited(ctx, a, entry, before,
+		func() (struct{}, error) { return struct{}{}, call() },
+		func(struct{}) any {
+			if after == nil {
+				return nil
+			}
+			return after()
+		},
+	)
+
+	return err
+}
+
+// record never fails the audited call: the change is already committed at this point.
+func (a *auditor) record(ctx context.Context, entry *entity.AuditEntry, before, after any) {
+	const op = "Usecase:Audit"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.String("action", entry.Action),
+		slog.Int("space id", entry.SpaceID),
+	)
+
+	diff, err := entity.Diff(before, after)
+	if err != nil {
+		log.Error("couldn't diff audit states", slog.String("error", err.Error()))
+		diff = map[string]entity.FieldChange{}
+	}
+	entry.Diff = diff
+
+	if entry.ActorID == nil {
+		if actor, ok := reqctx.Actor(ctx); ok {
+			entry.ActorID = &actor
+		}
+	}
+	entry.RequestID = reqctx.RequestID(ctx)
+
+	if err = a.auditRepo.InsertEntry(ctx, entry); err != nil {
+		log.Error("couldn't record audit entry", slog.String("error", err.Error()))
+	}
+}
+
+// actor returns pointer to id given in a command, nil if the command has none.
+func actor(id int) *int {
+	if id == 0 {
+		return nil
+	}
+
+	return &id
+}
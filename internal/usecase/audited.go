@@ -0,0 +1,404 @@
+package usecase
+
+import (
+	"context"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+)
+
+// Audited usecases decorate regular ones and record an audit entry for every
+// mutating call of a space, its members, forms and events. Reads are passed through.
+// User profile calls are not audited: they don't belong to a space and the audit
+// log is append-only, so it must not keep profile data of erased users.
+
+type AuditedSpaceUseCase struct {
+	*SpaceUseCase
+	audit *auditor
+}
+
+func NewAuditedSpaceUseCase(sc *SpaceUseCase, ar IAuditRepository) *AuditedSpaceUseCase {
+	return &AuditedSpaceUseCase{SpaceUseCase: sc, audit: &auditor{auditRepo: ar}}
+}
+
+func (a *AuditedSpaceUseCase) CreateSpace(ctx context.Context, cmd commands.CreateSpaceCommand) (*entity.Space, error) {
+	entry := &entity.AuditEntry{Action: "CreateSpace", TargetType: entity.AuditTargetSpace, ActorID: actor(cmd.UserID)}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (*entity.Space, error) { return a.SpaceUseCase.CreateSpace(ctx, cmd) },
+		func(space *entity.Space) any {
+			entry.SpaceID, entry.TargetID = space.ID, space.ID
+			return space
+		},
+	)
+}
+
+func (a *AuditedSpaceUseCase) UpdateSpace(ctx context.Context, cmd commands.UpdateSpaceCommand) (*entity.Space, error) {
+	entry := &entity.AuditEntry{Action: "UpdateSpace", TargetType: entity.AuditTargetSpace, SpaceID: cmd.ID, TargetID: cmd.ID}
+
+	return audited(ctx, a.audit, entry, a.spaceState(ctx, cmd.ID),
+		func() (*entity.Space, error) { return a.SpaceUseCase.UpdateSpace(ctx, cmd) },
+		func(space *entity.Space) any { return space },
+	)
+}
+
+func (a *AuditedSpaceUseCase) PatchSpace(ctx context.Context, cmd commands.PatchSpaceCommand) (*entity.Space, error) {
+	entry := &entity.AuditEntry{Action: "PatchSpace", TargetType: entity.AuditTargetSpace, SpaceID: cmd.ID, TargetID: cmd.ID}
+
+	return audited(ctx, a.audit, entry, a.spaceState(ctx, cmd.ID),
+		func() (*entity.Space, error) { return a.SpaceUseCase.PatchSpace(ctx, cmd) },
+		func(space *entity.Space) any { return space },
+	)
+}
+
+func (a *AuditedSpaceUseCase) UpdateSettings(ctx context.Context, cmd commands.UpdateSettingsCommand) (*entity.SpaceSettingsVersion, error) {
+	entry := &entity.AuditEntry{Action: "UpdateSettings", TargetType: entity.AuditTargetSpace, SpaceID: cmd.SpaceID, TargetID: cmd.SpaceID, ActorID: actor(cmd.AdminID)}
+
+	var before any
+	if settings, err := a.spaceRepo.GetSettings(ctx, cmd.SpaceID, 0); err == nil {
+		before = settings
+	}
+
+	return audited(ctx, a.audit, entry, before,
+		func() (*entity.SpaceSettingsVersion, error) { return a.SpaceUseCase.UpdateSettings(ctx, cmd) },
+		func(settings *entity.SpaceSettingsVersion) any { return settings },
+	)
+}
+
+func (a *AuditedSpaceUseCase) DeleteSpace(ctx context.Context, cmd commands.SpaceByIdCommand) error {
+	entry := &entity.AuditEntry{Action: "DeleteSpace", TargetType: entity.AuditTargetSpace, SpaceID: cmd.ID, TargetID: cmd.ID}
+
+	return auditedErr(ctx, a.audit, entry, a.spaceState(ctx, cmd.ID),
+		func() error { return a.SpaceUseCase.DeleteSpace(ctx, cmd) },
+		nil,
+	)
+}
+
+func (a *AuditedSpaceUseCase) RestoreSpace(ctx context.Context, cmd commands.RestoreSpaceCommand) (*entity.Space, error) {
+	entry := &entity.AuditEntry{Action: "RestoreSpace", TargetType: entity.AuditTargetSpace, SpaceID: cmd.ID, TargetID: cmd.ID, ActorID: actor(cmd.AdminID)}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (*entity.Space, error) { return a.SpaceUseCase.RestoreSpace(ctx, cmd) },
+		func(space *entity.Space) any { return space },
+	)
+}
+
+func (a *AuditedSpaceUseCase) JoinSpace(ctx context.Context, cmd commands.JoinSpaceCommand) (entity.MemberStatus, error) {
+	entry := &entity.AuditEntry{Action: "JoinSpace", TargetType: entity.AuditTargetMember, SpaceID: cmd.SpaceID, TargetID: cmd.UserID, ActorID: actor(cmd.UserID)}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (entity.MemberStatus, error) { return a.SpaceUseCase.JoinSpace(ctx, cmd) },
+		func(entity.MemberStatus) any { return a.memberState(ctx, cmd.SpaceID, cmd.UserID) },
+	)
+}
+
+// ImportMembers records a committed import as a single entry with counts only,
+// dry runs and rejected files change nothing and are not recorded.
+func (a *AuditedSpaceUseCase) ImportMembers(ctx context.Context, cmd commands.ImportMembersCommand) (*entity.MemberImport, error) {
+	report, err := a.SpaceUseCase.ImportMembers(ctx, cmd)
+	if err != nil || !report.Committed {
+		return report, err
+	}
+
+	entry := &entity.AuditEntry{Action: "ImportMembers", TargetType: entity.AuditTargetSpace, SpaceID: cmd.SpaceID, TargetID: cmd.SpaceID, ActorID: actor(cmd.AdminID)}
+	a.audit.record(ctx, entry, nil, map[string]int{"created": report.Created, "joined": report.Joined})
+
+	return report, nil
+}
+
+func (a *AuditedSpaceUseCase) KickMember(ctx context.Context, cmd commands.MemberCommand) error {
+	return a.moderate(ctx, "KickMember", cmd, func() error { return a.SpaceUseCase.KickMember(ctx, cmd) })
+}
+
+func (a *AuditedSpaceUseCase) BanMember(ctx context.Context, cmd commands.BanMemberCommand) error {
+	member := commands.MemberCommand{SpaceID: cmd.SpaceID, UserID: cmd.UserID, AdminID: cmd.AdminID}
+
+	return a.moderate(ctx, "BanMember", member, func() error { return a.SpaceUseCase.BanMember(ctx, cmd) })
+}
+
+func (a *AuditedSpaceUseCase) SetMatchRole(ctx context.Context, cmd commands.SetMatchRoleCommand) error {
+	member := commands.MemberCommand{SpaceID: cmd.SpaceID, UserID: cmd.UserID, AdminID: cmd.AdminID}
+
+	return a.moderate(ctx, "SetMatchRole", member, func() error { return a.SpaceUseCase.SetMatchRole(ctx, cmd) })
+}
+
+func (a *AuditedSpaceUseCase) ApproveMember(ctx context.Context, cmd commands.MemberCommand) error {
+	return a.moderate(ctx, "ApproveMember", cmd, func() error { return a.SpaceUseCase.ApproveMember(ctx, cmd) })
+}
+
+func (a *AuditedSpaceUseCase) RestoreMember(ctx context.Context, cmd commands.MemberCommand) error {
+	return a.moderate(ctx, "RestoreMember", cmd, func() error { return a.SpaceUseCase.RestoreMember(ctx, cmd) })
+}
+
+func (a *AuditedSpaceUseCase) moderate(ctx context.Context, action string, cmd commands.MemberCommand, call func() error) error {
+	entry := &entity.AuditEntry{Action: action, TargetType: entity.AuditTargetMember, SpaceID: cmd.SpaceID, TargetID: cmd.UserID, ActorID: actor(cmd.AdminID)}
+
+	return auditedErr(ctx, a.audit, entry, a.memberState(ctx, cmd.SpaceID, cmd.UserID),
+		call,
+		func() any { return a.memberState(ctx, cmd.SpaceID, cmd.UserID) },
+	)
+}
+
+// spaceState returns nil if space can't be read, the wrapped call reports the error then.
+func (a *AuditedSpaceUseCase) spaceState(ctx context.Context, id int) any {
+	space, err := a.spaceRepo.GetSpace(ctx, id)
+	if err != nil {
+		return nil
+	}
+
+	return space
+}
+
+// memberState is a membership without personal data, see entity.MemberAudit.
+func (a *AuditedSpaceUseCase) memberState(ctx context.Context, spaceID, userID int) any {
+	member, err := a.spaceRepo.GetMember(ctx, spaceID, userID)
+	if err != nil {
+		return nil
+	}
+
+	return entity.MemberAuditOf(member)
+}
+
+type AuditedUserUseCase struct {
+	*UserUseCase
+	audit *auditor
+}
+
+func NewAuditedUserUseCase(uc *UserUseCase, ar IAuditRepository) *AuditedUserUseCase {
+	return &AuditedUserUseCase{UserUseCase: uc, audit: &auditor{auditRepo: ar}}
+}
+
+func (a *AuditedUserUseCase) UpdateForm(ctx context.Context, cmd commands.UpdateFormCommand) (*entity.User, []*entity.Form, error) {
+	entry := &entity.AuditEntry{Action: "UpdateForm", TargetType: entity.AuditTargetForm, SpaceID: cmd.SpaceID, TargetID: cmd.UserID, ActorID: actor(cmd.UserID)}
+
+	var forms []*entity.Form
+
+	user, err := audited(ctx, a.audit, entry, a.formState(ctx, cmd.UserID, cmd.SpaceID),
+		func() (*entity.User, error) {
+			user, userForms, err := a.UserUseCase.UpdateForm(ctx, cmd)
+			forms = userForms
+			return user, err
+		},
+		func(*entity.User) any { return a.formState(ctx, cmd.UserID, cmd.SpaceID) },
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, forms, nil
+}
+
+func (a *AuditedUserUseCase) PatchForm(ctx context.Context, cmd commands.PatchFormCommand) (*entity.User, []*entity.Form, error) {
+	entry := &entity.AuditEntry{Action: "PatchForm", TargetType: entity.AuditTargetForm, SpaceID: cmd.SpaceID, TargetID: cmd.UserID, ActorID: actor(cmd.UserID)}
+
+	var forms []*entity.Form
+
+	user, err := audited(ctx, a.audit, entry, a.formState(ctx, cmd.UserID, cmd.SpaceID),
+		func() (*entity.User, error) {
+			user, userForms, err := a.UserUseCase.PatchForm(ctx, cmd)
+			forms = userForms
+			return user, err
+		},
+		func(*entity.User) any { return a.formState(ctx, cmd.UserID, cmd.SpaceID) },
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, forms, nil
+}
+
+// DeleteUser removes user from a space, so it is recorded as membership change.
+func (a *AuditedUserUseCase) DeleteUser(ctx context.Context, cmd commands.FormByIdCommand) error {
+	entry := &entity.AuditEntry{Action: "DeleteUser", TargetType: entity.AuditTargetMember, SpaceID: cmd.SpaceID, TargetID: cmd.UserID, ActorID: actor(cmd.UserID)}
+
+	return auditedErr(ctx, a.audit, entry, a.formState(ctx, cmd.UserID, cmd.SpaceID),
+		func() error { return a.UserUseCase.DeleteUser(ctx, cmd) },
+		nil,
+	)
+}
+
+// formState is a form without tags and availability, see entity.MemberAudit.
+func (a *AuditedUserUseCase) formState(ctx context.Context, userID, spaceID int) any {
+	form, err := a.userRepo.GetForm(ctx, userID, spaceID)
+	if err != nil {
+		return nil
+	}
+
+	return entity.FormAuditOf(userID, form)
+}
+
+type AuditedEventUseCase struct {
+	*EventUseCase
+	audit *auditor
+}
+
+func NewAuditedEventUseCase(ec *EventUseCase, ar IAuditRepository) *AuditedEventUseCase {
+	return &AuditedEventUseCase{EventUseCase: ec, audit: &auditor{auditRepo: ar}}
+}
+
+func (a *AuditedEventUseCase) CreateEvent(ctx context.Context, cmd commands.CreateEventCommand) (*entity.Event, error) {
+	entry := &entity.AuditEntry{Action: "CreateEvent", TargetType: entity.AuditTargetEvent, SpaceID: cmd.SpaceId, ActorID: actor(cmd.UserId)}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (*entity.Event, error) { return a.EventUseCase.CreateEvent(ctx, cmd) },
+		func(event *entity.Event) any {
+			entry.TargetID = event.ID
+			return event
+		},
+	)
+}
+
+func (a *AuditedEventUseCase) JoinEvent(ctx context.Context, cmd commands.JoinEventCommand) error {
+	entry := &entity.AuditEntry{Action: "JoinEvent", TargetType: entity.AuditTargetEvent, TargetID: cmd.EventId, ActorID: actor(cmd.UserId)}
+
+	return auditedErr(ctx, a.audit, entry, nil,
+		func() error { return a.EventUseCase.JoinEvent(ctx, cmd) },
+		func() any {
+			if event, err := a.eventRepo.GetEvent(ctx, cmd.EventId); err == nil {
+				entry.SpaceID = event.SpaceId
+			}
+			return map[string]int{"participant": cmd.UserId}
+		},
+	)
+}
+
+func (a *AuditedEventUseCase) DeleteEvent(ctx context.Context, cmd commands.EventByIdCommand) error {
+	entry := &entity.AuditEntry{Action: "DeleteEvent", TargetType: entity.AuditTargetEvent, TargetID: cmd.ID}
+
+	var before any
+	if event, err := a.eventRepo.GetEvent(ctx, cmd.ID); err == nil {
+		entry.SpaceID = event.SpaceId
+		before = event
+	}
+
+	return auditedErr(ctx, a.audit, entry, before,
+		func() error { return a.EventUseCase.DeleteEvent(ctx, cmd) },
+		nil,
+	)
+}
+
+func (a *AuditedEventUseCase) RestoreEvent(ctx context.Context, cmd commands.RestoreEventCommand) (*entity.Event, error) {
+	entry := &entity.AuditEntry{Action: "RestoreEvent", TargetType: entity.AuditTargetEvent, TargetID: cmd.ID, ActorID: actor(cmd.AdminID)}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (*entity.Event, error) { return a.EventUseCase.RestoreEvent(ctx, cmd) },
+		func(event *entity.Event) any {
+			entry.SpaceID = event.SpaceId
+			return event
+		},
+	)
+}
+
+type AuditedRoundUseCase struct {
+	*RoundUseCase
+	audit *auditor
+}
+
+func NewAuditedRoundUseCase(rc *RoundUseCase, ar IAuditRepository) *AuditedRoundUseCase {
+	return &AuditedRoundUseCase{RoundUseCase: rc, audit: &auditor{auditRepo: ar}}
+}
+
+// CreateRound records meeting IDs only, participants are in the meetings.
+func (a *AuditedRoundUseCase) CreateRound(ctx context.Context, cmd commands.CreateRoundCommand) (*entity.Round, error) {
+	entry := &entity.AuditEntry{Action: "CreateRound", TargetType: entity.AuditTargetRound, SpaceID: cmd.SpaceID, ActorID: actor(cmd.AdminID)}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (*entity.Round, error) { return a.RoundUseCase.CreateRound(ctx, cmd) },
+		func(round *entity.Round) any {
+			entry.TargetID = round.ID
+
+			meetings := make([]int, 0, len(round.Meetings))
+			for _, m := range round.Meetings {
+				meetings = append(meetings, m.ID)
+			}
+			return map[string]any{"settings_version": round.SettingsVersion, "meetings": meetings}
+		},
+	)
+}
+
+// PublishPreview is recorded as creating the round with admin overrides it
+// was matched around, previews themselves aren't audited as nothing changes
+// until they are published.
+func (a *AuditedRoundUseCase) PublishPreview(ctx context.Context, cmd commands.PublishPreviewCommand) (*entity.Round, error) {
+	entry := &entity.AuditEntry{Action: "PublishRound", TargetType: entity.AuditTargetRound, SpaceID: cmd.SpaceID, ActorID: actor(cmd.AdminID)}
+
+	// the preview is gone once published
+	var overrides []entity.Override
+	if preview, err := a.roundRepo.GetPreview(ctx, cmd.PreviewID); err == nil {
+		overrides = preview.Overrides
+	}
+
+	return audited(ctx, a.audit, entry, nil,
+		func() (*entity.Round, error) { return a.RoundUseCase.PublishPreview(ctx, cmd) },
+		func(round *entity.Round) any {
+			entry.TargetID = round.ID
+
+			meetings := make([]int, 0, len(round.Meetings))
+			for _, m := range round.Meetings {
+				meetings = append(meetings, m.ID)
+			}
+			return map[string]any{"preview_id": cmd.PreviewID, "settings_version": round.SettingsVersion, "meetings": meetings, "overrides": overrides}
+		},
+	)
+}
+
+type AuditedMeetingUseCase struct {
+	*MeetingUseCase
+	audit *auditor
+}
+
+func NewAuditedMeetingUseCase(mc *MeetingUseCase, ar IAuditRepository) *AuditedMeetingUseCase {
+	return &AuditedMeetingUseCase{MeetingUseCase: mc, audit: &auditor{auditRepo: ar}}
+}
+
+func (a *AuditedMeetingUseCase) AcceptMeeting(ctx context.Context, cmd commands.MeetingActionCommand) (*entity.Meeting, error) {
+	return a.participate(ctx, "AcceptMeeting", cmd.MeetingID, cmd.UserID, func() (*entity.Meeting, error) { return a.MeetingUseCase.AcceptMeeting(ctx, cmd) })
+}
+
+func (a *AuditedMeetingUseCase) DeclineMeeting(ctx context.Context, cmd commands.MeetingActionCommand) (*entity.Meeting, error) {
+	return a.participate(ctx, "DeclineMeeting", cmd.MeetingID, cmd.UserID, func() (*entity.Meeting, error) { return a.MeetingUseCase.DeclineMeeting(ctx, cmd) })
+}
+
+func (a *AuditedMeetingUseCase) CompleteMeeting(ctx context.Context, cmd commands.CompleteMeetingCommand) (*entity.Meeting, error) {
+	return a.participate(ctx, "CompleteMeeting", cmd.MeetingID, cmd.UserID, func() (*entity.Meeting, error) { return a.MeetingUseCase.CompleteMeeting(ctx, cmd) })
+}
+
+func (a *AuditedMeetingUseCase) ScheduleMeeting(ctx context.Context, cmd commands.ScheduleMeetingCommand) (*entity.Meeting, error) {
+	return a.participate(ctx, "ScheduleMeeting", cmd.MeetingID, cmd.UserID, func() (*entity.Meeting, error) { return a.MeetingUseCase.ScheduleMeeting(ctx, cmd) })
+}
+
+func (a *AuditedMeetingUseCase) participate(ctx context.Context, action string, meetingID, userID int, call func() (*entity.Meeting, error)) (*entity.Meeting, error) {
+	entry := &entity.AuditEntry{Action: action, TargetType: entity.AuditTargetMeeting, TargetID: meetingID, ActorID: actor(userID)}
+
+	var before any
+	if meeting, err := a.meetingRepo.GetMeeting(ctx, meetingID); err == nil {
+		entry.SpaceID = meeting.SpaceID
+		before = meeting
+	}
+
+	return audited(ctx, a.audit, entry, before, call, func(meeting *entity.Meeting) any { return meeting })
+}
+
+type AuditedMentorshipUseCase struct {
+	*MentorshipUseCase
+	audit *auditor
+}
+
+func NewAuditedMentorshipUseCase(mc *MentorshipUseCase, ar IAuditRepository) *AuditedMentorshipUseCase {
+	return &AuditedMentorshipUseCase{MentorshipUseCase: mc, audit: &auditor{auditRepo: ar}}
+}
+
+func (a *AuditedMentorshipUseCase) EndMentorship(ctx context.Context, cmd commands.EndMentorshipCommand) (*entity.Mentorship, error) {
+	entry := &entity.AuditEntry{Action: "EndMentorship", TargetType: entity.AuditTargetMentorship, TargetID: cmd.ID, ActorID: actor(cmd.UserID)}
+
+	var before any
+	if mentorship, err := a.mentorshipRepo.GetMentorship(ctx, cmd.ID); err == nil {
+		entry.SpaceID = mentorship.SpaceID
+		before = mentorship
+	}
+
+	return audited(ctx, a.audit, entry, before,
+		func() (*entity.Mentorship, error) { return a.MentorshipUseCase.EndMentorship(ctx, cmd) },
+		func(mentorship *entity.Mentorship) any { return mentorship },
+	)
+}
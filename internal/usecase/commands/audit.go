@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"time"
+)
+
+// AUDIT
+type (
+	AuditLogCommand struct {
+		SpaceID    int
+		AdminID    int
+		ActorID    int
+		Action     string
+		TargetType entity.AuditTarget
+		Since      time.Time
+		Until      time.Time
+		Limit      int
+		Offset     int
+	}
+)
@@ -14,6 +14,7 @@ type (
 		Description string
 		BeginDate   time.Time
 		EndDate     time.Time
+		Timezone    string
 		Tags        entity.Tags
 	}
 
@@ -21,6 +22,11 @@ type (
 		ID int `path:"id" maxLength:"30" example:"1" doc:"event id"`
 	}
 
+	RestoreEventCommand struct {
+		ID      int
+		AdminID int
+	}
+
 	JoinEventCommand struct {
 		EventId int `json:"eventId" example:"123" doc:"Event ID"`
 		UserId  int `json:"userId" example:"123" doc:"User ID"`
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"time"
+)
+
+// ROUNDS AND MEETINGS
+type (
+	CreateRoundCommand struct {
+		SpaceID int
+		AdminID int
+	}
+
+	PublishPreviewCommand struct {
+		SpaceID   int
+		PreviewID string
+		AdminID   int
+	}
+
+	OverridePreviewCommand struct {
+		SpaceID   int
+		PreviewID string
+		AdminID   int
+		Kind      entity.OverrideKind
+		UserIDs   []int
+	}
+
+	ReplayRoundCommand struct {
+		RoundID int
+		AdminID int
+	}
+
+	RoundByIdCommand struct {
+		ID int
+	}
+
+	MeetingByIdCommand struct {
+		ID int
+	}
+
+	// MeetingActionCommand is an accept or decline by a participant.
+	MeetingActionCommand struct {
+		MeetingID int
+		UserID    int
+	}
+
+	// CompleteMeetingCommand reports how the meeting went. With NoShow the
+	// reporter claims Absent participants, every other one if empty, didn't come.
+	CompleteMeetingCommand struct {
+		MeetingID int
+		UserID    int
+		NoShow    bool
+		Absent    []int
+	}
+
+	// ScheduleMeetingCommand picks meeting time, one of proposed slots if any.
+	ScheduleMeetingCommand struct {
+		MeetingID int
+		UserID    int
+		At        time.Time
+	}
+
+	// MentorshipsCommand lists mentorships of the space, ended ones too with WithEnded.
+	MentorshipsCommand struct {
+		SpaceID   int
+		WithEnded bool
+	}
+
+	EndMentorshipCommand struct {
+		ID     int
+		UserID int
+	}
+)
@@ -11,10 +11,12 @@ type (
 	}
 
 	CreateSpaceCommand struct {
-		UserID      int
-		Name        string
-		Description string
-		Tags        entity.Tags
+		UserID       int
+		Name         string
+		Description  string
+		Timezone     string
+		JoinApproval bool
+		Tags         entity.Tags
 	}
 
 	SpaceByIdCommand struct {
@@ -30,5 +32,83 @@ type (
 		ID          int
 		Name        string
 		Description string
+		// Tags replace space tags, nil keeps them.
+		Tags entity.Tags
+		// Version of space the update is based on, zero updates any version.
+		Version int
+	}
+
+	// PatchSpaceCommand carries RFC 7396 merge patch of name, description and tags.
+	PatchSpaceCommand struct {
+		ID      int
+		Patch   map[string]any
+		Version int
+	}
+
+	MembersCommand struct {
+		SpaceID int
+		Status  entity.MemberStatus
+		Limit   int
+		Offset  int
+	}
+
+	MemberCommand struct {
+		SpaceID int
+		UserID  int
+		AdminID int
+	}
+
+	RestoreSpaceCommand struct {
+		ID      int
+		AdminID int
+	}
+
+	BanMemberCommand struct {
+		SpaceID int
+		UserID  int
+		AdminID int
+		Reason  string
+	}
+
+	// SetMatchRoleCommand sets the mentoring role of the member, empty Role
+	// clears it. Capacity overrides the space default for mentors, 0 keeps it.
+	SetMatchRoleCommand struct {
+		SpaceID  int
+		UserID   int
+		AdminID  int
+		Role     string
+		Capacity int
+	}
+
+	// ImportMembersCommand carries a members CSV. Columns maps user fields to CSV
+	// headers, a field without mapping is read from the header of the same name.
+	// TagColumns are headers imported as user tags named after the header.
+	ImportMembersCommand struct {
+		SpaceID    int
+		AdminID    int
+		CSV        []byte
+		Columns    map[string]string
+		TagColumns []string
+		DryRun     bool
+	}
+
+	ExportMembersCommand struct {
+		SpaceID    int
+		AdminID    int
+		Status     entity.MemberStatus
+		TagColumns []string
+	}
+
+	// SettingsCommand selects a version of space settings, zero is the current one.
+	SettingsCommand struct {
+		SpaceID int
+		Version int
+	}
+
+	UpdateSettingsCommand struct {
+		SpaceID  int
+		AdminID  int
+		Settings entity.SpaceSettings
+		Version  int
 	}
 )
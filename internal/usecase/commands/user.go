@@ -21,6 +21,25 @@ type (
 		SpaceID  int
 		UserTags entity.Tags
 		PairTags entity.Tags
+		// Availability replaces the stored one, nil clears it.
+		Availability *entity.Availability
+		// Version of form the update is based on, zero updates any version.
+		Version int
+	}
+
+	// PatchFormCommand carries RFC 7396 merge patch of user_tags, pair_tags and availability.
+	PatchFormCommand struct {
+		UserID  int
+		SpaceID int
+		Patch   map[string]any
+		Version int
+	}
+
+	// PatchUserCommand carries RFC 7396 merge patch of user profile.
+	PatchUserCommand struct {
+		ID      int
+		Patch   map[string]any
+		Version int
 	}
 
 	UpdateUserCommand struct {
@@ -29,6 +48,8 @@ type (
 		LastName  string
 		UserName  string
 		PhotoURL  string
+		// Version of user the update is based on, zero updates any version.
+		Version int
 	}
 
 	CreateUserCommand struct {
@@ -5,25 +5,33 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
 	"github.com/Slava02/Involvio/internal/repository"
 	"github.com/Slava02/Involvio/internal/usecase/commands"
 	"github.com/Slava02/Involvio/pkg/hexid"
+	"github.com/Slava02/Involvio/pkg/metrics"
 	"log/slog"
 )
 
+// ErrSpaceDeleted is returned on restoring an event of a deleted space.
+var ErrSpaceDeleted = errs.New(errs.Conflict, "event.space_deleted", "space of the event is deleted, restore the space first")
+
 type IEventRepository interface {
 	InsertEvent(ctx context.Context, userId int, event *entity.Event) error
 	GetEvent(ctx context.Context, id int) (*entity.Event, error)
 	AddUser(ctx context.Context, eventId, userId int) error
 	DeleteEvent(ctx context.Context, id int) error
+	GetDeletedEvent(ctx context.Context, id int) (*entity.Event, error)
+	RestoreEvent(ctx context.Context, id int) error
 }
 
-func NewEventUseCase(ur IEventRepository) *EventUseCase {
-	return &EventUseCase{eventRepo: ur}
+func NewEventUseCase(er IEventRepository, sr ISpaceRepository) *EventUseCase {
+	return &EventUseCase{eventRepo: er, spaceRepo: sr}
 }
 
 type EventUseCase struct {
 	eventRepo IEventRepository
+	spaceRepo ISpaceRepository
 }
 
 func (ec *EventUseCase) CreateEvent(ctx context.Context, cmd commands.CreateEventCommand) (*entity.Event, error) {
@@ -38,10 +46,27 @@ func (ec *EventUseCase) CreateEvent(ctx context.Context, cmd commands.CreateEven
 	)
 	log.Debug(op)
 
+	space, err := ec.spaceRepo.GetSpace(ctx, cmd.SpaceId)
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	// events without explicit timezone inherit the timezone of their space
+	timezone := cmd.Timezone
+	if timezone == "" {
+		timezone = space.Timezone
+	}
+
+	if _, err := entity.LoadLocation(timezone); err != nil {
+		log.Debug("invalid timezone", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
 	// TODO: вынести генерацию id в зависимость
 	eventId, err := hexid.Generate()
 	if err != nil {
-		log.Error("couldn't generate id: ", err.Error())
+		log.Error("couldn't generate id", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -53,11 +78,18 @@ func (ec *EventUseCase) CreateEvent(ctx context.Context, cmd commands.CreateEven
 		Tags:        cmd.Tags,
 		BeginDate:   cmd.BeginDate,
 		EndDate:     cmd.EndDate,
+		Timezone:    timezone,
 	}
 
 	err = ec.eventRepo.InsertEvent(ctx, cmd.UserId, event)
 	if err != nil {
-		log.Debug("couldn't insert event: ", err.Error())
+		log.Debug("couldn't insert event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	metrics.EventsCreated.Inc()
+
+	if err = event.Localize(); err != nil {
 		return fail(err)
 	}
 
@@ -80,13 +112,18 @@ func (ec *EventUseCase) GetEvent(ctx context.Context, cmd commands.EventByIdComm
 	event, err := ec.eventRepo.GetEvent(ctx, cmd.ID)
 	if err != nil {
 		if errors.Is(err, repository.ErrEventNotFound) {
-			log.Info("couldn't get event: ", err.Error())
+			log.Info("couldn't get event", slog.String("error", err.Error()))
 		} else {
-			log.Debug("couldn't get event: ", err.Error())
+			log.Debug("couldn't get event", slog.String("error", err.Error()))
 		}
 		return fail(err)
 	}
 
+	if err = event.Localize(); err != nil {
+		log.Error("couldn't localize event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
 	return event, nil
 }
 
@@ -106,16 +143,18 @@ func (ec *EventUseCase) JoinEvent(ctx context.Context, cmd commands.JoinEventCom
 
 	_, err := ec.GetEvent(ctx, commands.EventByIdCommand{ID: cmd.EventId})
 	if err != nil {
-		log.Debug("couldn't get event: ", err.Error())
+		log.Debug("couldn't get event", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	err = ec.eventRepo.AddUser(ctx, cmd.EventId, cmd.UserId)
 	if err != nil {
-		log.Debug("couldn't add user to event: ", err.Error())
+		log.Debug("couldn't add user to event", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
+	metrics.EventJoins.Inc()
+
 	return nil
 }
 
@@ -134,15 +173,65 @@ func (ec *EventUseCase) DeleteEvent(ctx context.Context, cmd commands.EventByIdC
 
 	_, err := ec.GetEvent(ctx, commands.EventByIdCommand{ID: cmd.ID})
 	if err != nil {
-		log.Debug("couldn't get event: ", err.Error())
+		log.Debug("couldn't get event", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	err = ec.eventRepo.DeleteEvent(ctx, cmd.ID)
 	if err != nil {
-		log.Debug("couldn't delete event: ", err.Error())
+		log.Debug("couldn't delete event", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	return nil
 }
+
+// RestoreEvent brings back a deleted event with its participants. Events deleted
+// together with their space come back only with the space.
+func (ec *EventUseCase) RestoreEvent(ctx context.Context, cmd commands.RestoreEventCommand) (*entity.Event, error) {
+	const op = "Usecase:RestoreEvent"
+
+	fail := func(err error) (*entity.Event, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("event id", cmd.ID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	event, err := ec.eventRepo.GetDeletedEvent(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get deleted event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	_, err = ec.spaceRepo.GetSpace(ctx, event.SpaceId)
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		if errors.Is(err, repository.ErrSpaceNotFound) {
+			return fail(ErrSpaceDeleted)
+		}
+		return fail(err)
+	}
+
+	admin, err := ec.spaceRepo.GetMember(ctx, event.SpaceId, cmd.AdminID)
+	if err != nil && !errors.Is(err, repository.ErrMemberNotFound) {
+		log.Debug("couldn't get admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if err != nil || !admin.IsAdmin() {
+		return fail(ErrNotSpaceAdmin)
+	}
+
+	err = ec.eventRepo.RestoreEvent(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't restore event", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return ec.GetEvent(ctx, commands.EventByIdCommand{ID: cmd.ID})
+}
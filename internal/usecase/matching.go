@@ -0,0 +1,662 @@
+package usecase
+
+import (
+	"github.com/Slava02/Involvio/internal/entity"
+	"sort"
+	"time"
+)
+
+const (
+	// matchAttempts bounds reshuffles looking for a matching that leaves nobody out.
+	matchAttempts = 20
+	// improvePasses bounds rounds of swaps between groups in groups mode.
+	improvePasses = 5
+	// repeatCost weighs a repeat pair in groups mode, a broken soft
+	// constraint rule costs 1.
+	repeatCost = 10
+	// forbiddenCost weighs a pair hard constraint rules forbid, such pairs are
+	// taken apart after grouping.
+	forbiddenCost = 1 << 20
+)
+
+// pairKey is an unordered pair of members.
+type pairKey struct{ a, b int }
+
+func pairOf(a, b int) pairKey {
+	if a > b {
+		a, b = b, a
+	}
+	return pairKey{a, b}
+}
+
+// metPairs returns pairs of members who met in the given groups.
+func metPairs(groups [][]int) map[pairKey]bool {
+	met := make(map[pairKey]bool)
+	for _, group := range groups {
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				met[pairOf(group[i], group[j])] = true
+			}
+		}
+	}
+	return met
+}
+
+// pairRules are pairs of members space constraint rules keep apart.
+type pairRules struct {
+	// forbid are pairs hard rules never let meet.
+	forbid map[pairKey]bool
+	// penalty counts soft rules a pair breaks.
+	penalty map[pairKey]int
+}
+
+// constrainedPairs applies constraint rules to every pair of members.
+func constrainedPairs(rules []entity.ConstraintRule, members []*entity.Member) pairRules {
+	pr := pairRules{forbid: make(map[pairKey]bool), penalty: make(map[pairKey]int)}
+	if len(rules) == 0 {
+		return pr
+	}
+
+	for i, a := range members {
+		for _, b := range members[i+1:] {
+			for _, rule := range rules {
+				if !rule.Breaks(a, b) {
+					continue
+				}
+
+				if rule.Hard() {
+					pr.forbid[pairOf(a.User.ID, b.User.ID)] = true
+				} else {
+					pr.penalty[pairOf(a.User.ID, b.User.ID)]++
+				}
+			}
+		}
+	}
+
+	return pr
+}
+
+// with returns pairs of avoid together with forbidden ones, avoid isn't changed.
+func (pr pairRules) with(avoid map[pairKey]bool) map[pairKey]bool {
+	all := make(map[pairKey]bool, len(avoid)+len(pr.forbid))
+	for key := range avoid {
+		all[key] = true
+	}
+	for key := range pr.forbid {
+		all[key] = true
+	}
+	return all
+}
+
+// costs weighs pairs for groups mode, where repeats in avoid are allowed.
+func (pr pairRules) costs(avoid map[pairKey]bool) map[pairKey]int {
+	costs := make(map[pairKey]int, len(avoid)+len(pr.forbid)+len(pr.penalty))
+	for key := range avoid {
+		costs[key] += repeatCost
+	}
+	for key := range pr.forbid {
+		costs[key] += forbiddenCost
+	}
+	for key, n := range pr.penalty {
+		costs[key] += n
+	}
+	return costs
+}
+
+// matchMembers matches members into meetings as the settings mode says.
+// Pairs forbidden by rules never meet, pairs breaking soft rules meet as
+// rarely as possible.
+func matchMembers(settings entity.SpaceSettings, members []int, avoid map[pairKey]bool, rules pairRules, shuffle func(n int, swap func(i, j int))) ([][]int, []int) {
+	size, minSize, maxSize := settings.GroupBounds()
+	if settings.Mode == entity.MatchGroups {
+		return partitionGroups(members, size, minSize, maxSize, rules.costs(avoid), shuffle)
+	}
+	return matchGroups(members, size, maxSize, rules.with(avoid), rules.penalty, shuffle)
+}
+
+// noOverlapPairs adds pairs of members whose availability has no common time
+// after from to avoid and returns members who have common time with nobody.
+// Members without availability are never avoided.
+func noOverlapPairs(availability map[int]*entity.Availability, from time.Time, avoid map[pairKey]bool) []int {
+	to := from.Add(entity.AvailabilityHorizon)
+
+	ids := make([]int, 0, len(availability))
+	intervals := make(map[int][]entity.Interval, len(availability))
+	for id, a := range availability {
+		if a != nil {
+			ids = append(ids, id)
+			intervals[id] = a.Intervals(from, to)
+		}
+	}
+	sort.Ints(ids)
+
+	overlaps := make(map[int]bool, len(ids))
+	for i, a := range ids {
+		for _, b := range ids[i+1:] {
+			if entity.Overlaps(intervals[a], intervals[b]) {
+				overlaps[a], overlaps[b] = true, true
+				continue
+			}
+			avoid[pairOf(a, b)] = true
+		}
+	}
+
+	alone := make([]int, 0)
+	for _, id := range ids {
+		if !overlaps[id] {
+			alone = append(alone, id)
+		}
+	}
+
+	return alone
+}
+
+// matchGroups partitions members into groups of size so that no pair in avoid,
+// e.g. members who met before, meets. A member left alone joins a group that
+// has room up to maxSize, members who still have nobody are returned as
+// unmatched. Order is randomized with shuffle, the attempt with fewest
+// unmatched members wins, then the one whose pairs break fewest soft rules
+// counted by penalty.
+func matchGroups(members []int, size, maxSize int, avoid map[pairKey]bool, penalty map[pairKey]int, shuffle func(n int, swap func(i, j int))) ([][]int, []int) {
+	var best [][]int
+	var bestUnmatched []int
+	bestPenalty := 0
+
+	order := append([]int(nil), members...)
+
+	for attempt := 0; attempt < matchAttempts; attempt++ {
+		shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		groups, unmatched := matchGreedy(order, size, maxSize, avoid, penalty)
+
+		total := 0
+		for _, group := range groups {
+			for i, member := range group {
+				total += cost(group[i+1:], member, member, penalty)
+			}
+		}
+
+		if best == nil || len(unmatched) < len(bestUnmatched) || len(unmatched) == len(bestUnmatched) && total < bestPenalty {
+			best, bestUnmatched, bestPenalty = groups, unmatched, total
+		}
+
+		if len(bestUnmatched) == 0 && bestPenalty == 0 {
+			break
+		}
+	}
+
+	return best, bestUnmatched
+}
+
+// matchGreedy fills groups in order, each with the members who fit it and
+// break fewest soft rules, the earliest of them on a tie.
+func matchGreedy(order []int, size, maxSize int, avoid map[pairKey]bool, penalty map[pairKey]int) ([][]int, []int) {
+	fits := func(group []int, member int) bool {
+		for _, m := range group {
+			if avoid[pairOf(m, member)] {
+				return false
+			}
+		}
+		return true
+	}
+
+	remaining := append([]int(nil), order...)
+	groups := make([][]int, 0, len(order)/size+1)
+	alone := make([]int, 0)
+
+	for len(remaining) > 0 {
+		group := []int{remaining[0]}
+		remaining = remaining[1:]
+
+		for len(group) < size {
+			next, lowest := -1, 0
+			for i, member := range remaining {
+				if !fits(group, member) {
+					continue
+				}
+
+				if n := cost(group, member, member, penalty); next < 0 || n < lowest {
+					next, lowest = i, n
+				}
+				if lowest == 0 {
+					break
+				}
+			}
+
+			if next < 0 {
+				break
+			}
+			group = append(group, remaining[next])
+			remaining = append(remaining[:next], remaining[next+1:]...)
+		}
+
+		if len(group) == 1 {
+			alone = append(alone, group[0])
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	unmatched := make([]int, 0)
+	for _, member := range alone {
+		g, lowest := -1, 0
+		for i, group := range groups {
+			if len(group) >= maxSize || !fits(group, member) {
+				continue
+			}
+
+			if n := cost(group, member, member, penalty); g < 0 || n < lowest {
+				g, lowest = i, n
+			}
+		}
+
+		if g < 0 {
+			unmatched = append(unmatched, member)
+			continue
+		}
+		groups[g] = append(groups[g], member)
+	}
+
+	return groups, unmatched
+}
+
+// partitionGroups splits members into groups as close to size as minSize and
+// maxSize allow, so that pairs meeting cost as little as possible by costs.
+// Unlike matchGroups it doesn't leave members out to avoid a repeat, only
+// members the bounds have no room for or who can't be grouped without a
+// forbidden pair are returned as unmatched. Members are placed one by one
+// where they add the least cost, then swapped between groups while that
+// lowers it. The cheapest attempt wins.
+func partitionGroups(members []int, size, minSize, maxSize int, costs map[pairKey]int, shuffle func(n int, swap func(i, j int))) ([][]int, []int) {
+	var best [][]int
+	var bestUnmatched []int
+	bestCost := -1
+
+	order := append([]int(nil), members...)
+
+	for attempt := 0; attempt < matchAttempts; attempt++ {
+		shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		sizes := groupSizes(len(order), size, minSize, maxSize)
+		placed := 0
+		for _, n := range sizes {
+			placed += n
+		}
+
+		groups := make([][]int, len(sizes))
+		for _, member := range order[:placed] {
+			g, fewest := -1, 0
+			for i, group := range groups {
+				if len(group) == sizes[i] {
+					continue
+				}
+
+				n := cost(group, member, member, costs)
+				if g < 0 || n < fewest || n == fewest && len(group) < len(groups[g]) {
+					g, fewest = i, n
+				}
+			}
+			groups[g] = append(groups[g], member)
+		}
+
+		improveGroups(groups, costs)
+
+		total := 0
+		for _, group := range groups {
+			for i, member := range group {
+				total += cost(group[i+1:], member, member, costs)
+			}
+		}
+
+		if bestCost < 0 || total < bestCost {
+			best, bestUnmatched, bestCost = groups, append(make([]int, 0), order[placed:]...), total
+		}
+
+		if bestCost == 0 {
+			break
+		}
+	}
+
+	if bestCost < forbiddenCost {
+		return best, bestUnmatched
+	}
+
+	groups, dropped := dropForbidden(best, minSize, maxSize, costs)
+	return groups, append(bestUnmatched, dropped...)
+}
+
+// groupSizes splits n members into groups as close to size as the bounds
+// allow. If no split fits the bounds, members beyond what groups of maxSize
+// take are left out.
+func groupSizes(n, size, minSize, maxSize int) []int {
+	if n < minSize {
+		return nil
+	}
+
+	fewest, most := (n+maxSize-1)/maxSize, n/minSize
+
+	k := (2*n + size) / (2 * size)
+	switch {
+	case fewest > most:
+		k, n = most, most*maxSize
+	case k < fewest:
+		k = fewest
+	case k > most:
+		k = most
+	}
+
+	sizes := make([]int, k)
+	for i := range sizes {
+		sizes[i] = n / k
+		if i < n%k {
+			sizes[i]++
+		}
+	}
+
+	return sizes
+}
+
+// improveGroups swaps members between groups while a swap lowers their cost.
+func improveGroups(groups [][]int, costs map[pairKey]int) {
+	for pass := 0; pass < improvePasses; pass++ {
+		improved := false
+
+		for i := range groups {
+			for j := i + 1; j < len(groups); j++ {
+				for x, a := range groups[i] {
+					for y, b := range groups[j] {
+						before := cost(groups[i], a, a, costs) + cost(groups[j], b, b, costs)
+						after := cost(groups[i], b, a, costs) + cost(groups[j], a, b, costs)
+						if after < before {
+							groups[i][x], groups[j][y] = b, a
+							a = b
+							improved = true
+						}
+					}
+				}
+			}
+		}
+
+		if !improved {
+			return
+		}
+	}
+}
+
+// dropForbidden takes members out of groups where they meet a pair forbidden
+// by costs, the one with most forbidden partners first. Groups left below
+// minSize are dissolved. Members taken out join the cheapest group with room
+// below maxSize and nobody forbidden for them, or are returned as unmatched.
+func dropForbidden(groups [][]int, minSize, maxSize int, costs map[pairKey]int) ([][]int, []int) {
+	dropped := make([]int, 0)
+	kept := make([][]int, 0, len(groups))
+
+	for _, group := range groups {
+		for {
+			worst, most := -1, 0
+			for x, a := range group {
+				n := 0
+				for _, b := range group {
+					if a != b && costs[pairOf(a, b)] >= forbiddenCost {
+						n++
+					}
+				}
+				if n > most {
+					worst, most = x, n
+				}
+			}
+
+			if worst < 0 {
+				break
+			}
+			dropped = append(dropped, group[worst])
+			group = append(append([]int(nil), group[:worst]...), group[worst+1:]...)
+		}
+
+		if len(group) < minSize {
+			dropped = append(dropped, group...)
+			continue
+		}
+		kept = append(kept, group)
+	}
+
+	unmatched := make([]int, 0)
+	for _, member := range dropped {
+		g, lowest := -1, 0
+		for i, group := range kept {
+			if len(group) >= maxSize {
+				continue
+			}
+
+			if n := cost(group, member, member, costs); n < forbiddenCost && (g < 0 || n < lowest) {
+				g, lowest = i, n
+			}
+		}
+
+		if g < 0 {
+			unmatched = append(unmatched, member)
+			continue
+		}
+		kept[g] = append(kept[g], member)
+	}
+
+	return kept, unmatched
+}
+
+// cost sums costs of member meeting members of group other than except.
+func cost(group []int, member, except int, costs map[pairKey]int) int {
+	n := 0
+	for _, m := range group {
+		if m != except && m != member {
+			n += costs[pairOf(m, member)]
+		}
+	}
+	return n
+}
+
+// pinnedGroups joins members that pin and swap overrides keep together into
+// groups, in order the members first appear in overrides.
+func pinnedGroups(overrides []entity.Override) [][]int {
+	parent := make(map[int]int)
+	order := make([]int, 0)
+
+	var find func(x int) int
+	find = func(x int) int {
+		if _, ok := parent[x]; !ok {
+			parent[x] = x
+			order = append(order, x)
+		}
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	join := func(a, b int) {
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	for _, o := range overrides {
+		switch o.Kind {
+		case entity.OverridePin:
+			join(o.UserIDs[0], o.UserIDs[1])
+		case entity.OverrideSwap:
+			for _, group := range o.Groups {
+				for _, member := range group[1:] {
+					join(group[0], member)
+				}
+			}
+		}
+	}
+
+	index := make(map[int]int)
+	groups := make([][]int, 0)
+	for _, member := range order {
+		root := find(member)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], member)
+	}
+
+	return groups
+}
+
+// swapMembers returns the two groups a and b are in with a and b exchanged.
+// It reports false unless they are in different groups.
+func swapMembers(groups [][]int, a, b int) ([][]int, bool) {
+	ga, gb := -1, -1
+	for i, group := range groups {
+		for _, member := range group {
+			switch member {
+			case a:
+				ga = i
+			case b:
+				gb = i
+			}
+		}
+	}
+
+	if ga < 0 || gb < 0 || ga == gb {
+		return nil, false
+	}
+
+	replace := func(group []int, from, to int) []int {
+		swapped := make([]int, len(group))
+		for i, member := range group {
+			if member == from {
+				member = to
+			}
+			swapped[i] = member
+		}
+		return swapped
+	}
+
+	return [][]int{replace(groups[ga], a, b), replace(groups[gb], b, a)}, true
+}
+
+// rematchPool matches members of the rematch pool. They are grouped among
+// themselves first if they make a group of minSize, a member left alone then
+// joins the open group with room below maxSize where it breaks fewest soft
+// rules. Returns new groups, the index of the open group each joining member
+// goes to and members still without a partner.
+func rematchPool(pool []int, open [][]int, size, minSize, maxSize int, avoid map[pairKey]bool, penalty map[pairKey]int, shuffle func(n int, swap func(i, j int))) ([][]int, map[int]int, []int) {
+	matched, alone := matchGroups(pool, size, maxSize, avoid, penalty, shuffle)
+
+	groups := make([][]int, 0, len(matched))
+	for _, group := range matched {
+		if len(group) < minSize {
+			alone = append(alone, group...)
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	fits := func(group []int, member int) bool {
+		for _, m := range group {
+			if avoid[pairOf(m, member)] {
+				return false
+			}
+		}
+		return true
+	}
+
+	grown := make([][]int, len(open))
+	for i, group := range open {
+		grown[i] = append([]int(nil), group...)
+	}
+
+	joins := make(map[int]int)
+	unmatched := make([]int, 0)
+	for _, member := range alone {
+		g, lowest := -1, 0
+		for i, group := range grown {
+			if len(group) >= maxSize || !fits(group, member) {
+				continue
+			}
+
+			if n := cost(group, member, member, penalty); g < 0 || n < lowest {
+				g, lowest = i, n
+			}
+		}
+
+		if g < 0 {
+			unmatched = append(unmatched, member)
+			continue
+		}
+		grown[g] = append(grown[g], member)
+		joins[member] = g
+	}
+
+	return groups, joins, unmatched
+}
+
+// assignMentees assigns mentees to compatible mentors within their capacity,
+// as many mentees as possible. A mentee who finds no mentor with room may
+// move an assigned one to another compatible mentor. Mentors with fewer
+// mentees are tried first, after those breaking fewer soft rules counted by
+// penalty. Mentees are taken in shuffled order, those left without a mentor
+// are returned as unmatched.
+func assignMentees(mentees, mentors []int, capacity map[int]int, compatible func(mentee, mentor int) bool, penalty map[pairKey]int, shuffle func(n int, swap func(i, j int))) (map[int]int, []int) {
+	mentorOf := make(map[int]int, len(mentees))
+	load := make(map[int]int, len(mentors))
+
+	order := append([]int(nil), mentees...)
+	shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+	var assign func(mentee int, visited map[int]bool) bool
+	assign = func(mentee int, visited map[int]bool) bool {
+		candidates := make([]int, 0, len(mentors))
+		for _, mentor := range mentors {
+			if !visited[mentor] && compatible(mentee, mentor) {
+				candidates = append(candidates, mentor)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			pi, pj := penalty[pairOf(mentee, candidates[i])], penalty[pairOf(mentee, candidates[j])]
+			return pi < pj || pi == pj && load[candidates[i]] < load[candidates[j]]
+		})
+
+		for _, mentor := range candidates {
+			if load[mentor] < capacity[mentor] {
+				mentorOf[mentee] = mentor
+				load[mentor]++
+				return true
+			}
+		}
+
+		for _, mentor := range candidates {
+			visited[mentor] = true
+			for _, other := range order {
+				if of, ok := mentorOf[other]; !ok || of != mentor || other == mentee {
+					continue
+				}
+
+				// other moves to another mentor and leaves room here
+				load[mentor]--
+				if assign(other, visited) {
+					mentorOf[mentee] = mentor
+					load[mentor]++
+					return true
+				}
+				load[mentor]++
+			}
+		}
+
+		return false
+	}
+
+	unmatched := make([]int, 0)
+	for _, mentee := range order {
+		if !assign(mentee, make(map[int]bool)) {
+			unmatched = append(unmatched, mentee)
+		}
+	}
+	sort.Ints(unmatched)
+
+	return mentorOf, unmatched
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// noShuffle keeps members in the given order.
+func noShuffle(int, func(i, j int)) {}
+
+func TestMatchGroups(t *testing.T) {
+	groups, unmatched := matchGroups([]int{1, 2, 3, 4, 5}, 2, 3, nil, nil, noShuffle)
+	assert.Equal(t, [][]int{{1, 2, 5}, {3, 4}}, groups)
+	assert.Empty(t, unmatched)
+
+	met := metPairs([][]int{{1, 2}, {3, 4}})
+	groups, unmatched = matchGroups([]int{1, 2, 3, 4}, 2, 3, met, nil, noShuffle)
+	assert.Equal(t, [][]int{{1, 3}, {2, 4}}, groups)
+	assert.Empty(t, unmatched)
+}
+
+func TestMatchGroupsUnmatched(t *testing.T) {
+	met := metPairs([][]int{{1, 2, 3}})
+
+	groups, unmatched := matchGroups([]int{1, 2, 3}, 2, 3, met, nil, noShuffle)
+	assert.Empty(t, groups)
+	assert.ElementsMatch(t, []int{1, 2, 3}, unmatched)
+
+	groups, unmatched = matchGroups([]int{1, 2, 3, 4}, 2, 2, met, nil, noShuffle)
+	assert.Equal(t, [][]int{{1, 4}}, groups)
+	assert.Equal(t, []int{2, 3}, unmatched)
+}
+
+func TestNoOverlapPairs(t *testing.T) {
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	evening := &entity.Availability{Ranges: []entity.TimeRange{{Weekday: "monday", Start: "18:00", End: "20:00"}}}
+	morning := &entity.Availability{Ranges: []entity.TimeRange{{Weekday: "monday", Start: "08:00", End: "10:00"}}}
+
+	avoid := map[pairKey]bool{}
+	alone := noOverlapPairs(map[int]*entity.Availability{1: evening, 2: evening, 3: morning, 4: nil}, monday, avoid)
+
+	assert.Equal(t, []int{3}, alone)
+	assert.Equal(t, map[pairKey]bool{pairOf(1, 3): true, pairOf(2, 3): true}, avoid)
+
+	groups, unmatched := matchGroups([]int{1, 3, 2, 4}, 2, 3, avoid, nil, noShuffle)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, groups)
+	assert.Empty(t, unmatched)
+}
+
+func TestRematchPool(t *testing.T) {
+	open := [][]int{{10, 11, 12}, {20, 21}, {30, 31}}
+	avoid := metPairs([][]int{{1, 2}, {3, 20}})
+
+	groups, joins, unmatched := rematchPool([]int{1, 2, 3}, open, 2, 2, 3, avoid, nil, noShuffle)
+	assert.Equal(t, [][]int{{1, 3}}, groups)
+	assert.Equal(t, map[int]int{2: 1}, joins)
+	assert.Empty(t, unmatched)
+
+	groups, joins, unmatched = rematchPool([]int{3, 4}, [][]int{{20, 21}}, 2, 2, 3, metPairs([][]int{{3, 4}, {3, 20}}), nil, noShuffle)
+	assert.Empty(t, groups)
+	assert.Equal(t, map[int]int{4: 0}, joins)
+	assert.Equal(t, []int{3}, unmatched)
+}
+
+func TestGroupSizes(t *testing.T) {
+	assert.Equal(t, []int{4, 3, 3}, groupSizes(10, 4, 3, 6))
+	assert.Equal(t, []int{5, 4, 4}, groupSizes(13, 6, 3, 6))
+	assert.Equal(t, []int{4}, groupSizes(7, 4, 4, 4))
+	assert.Nil(t, groupSizes(2, 4, 3, 6))
+}
+
+func TestPartitionGroups(t *testing.T) {
+	countRepeats := func(groups [][]int, avoid map[pairKey]bool) int {
+		n := 0
+		for _, group := range groups {
+			for i, member := range group {
+				n += cost(group[i+1:], member, member, pairRules{}.costs(avoid))
+			}
+		}
+		return n / repeatCost
+	}
+
+	avoid := metPairs([][]int{{1, 2}, {3, 4}, {5, 6}})
+	groups, unmatched := partitionGroups([]int{1, 2, 3, 4, 5, 6, 7}, 4, 3, 6, pairRules{}.costs(avoid), noShuffle)
+	assert.Len(t, groups, 2)
+	assert.Empty(t, unmatched)
+	assert.Equal(t, 0, countRepeats(groups, avoid))
+
+	// two groups of three can't split a trio who all met
+	avoid = metPairs([][]int{{1, 2, 3}})
+	groups, unmatched = partitionGroups([]int{1, 2, 3, 4, 5, 6}, 3, 3, 3, pairRules{}.costs(avoid), noShuffle)
+	assert.Len(t, groups, 2)
+	assert.Empty(t, unmatched)
+	assert.Equal(t, 1, countRepeats(groups, avoid))
+
+	groups, unmatched = partitionGroups([]int{1, 2, 3, 4, 5}, 4, 4, 4, nil, noShuffle)
+	assert.Equal(t, [][]int{{1, 2, 3, 4}}, groups)
+	assert.Equal(t, []int{5}, unmatched)
+}
+
+func TestAssignMentees(t *testing.T) {
+	// mentee 1 fits mentors 10 and 20, mentee 2 only mentor 10
+	fits := map[pairKey]bool{pairOf(1, 10): true, pairOf(1, 20): true, pairOf(2, 10): true, pairOf(3, 10): true}
+	compatible := func(mentee, mentor int) bool { return fits[pairOf(mentee, mentor)] }
+
+	mentorOf, unmatched := assignMentees([]int{1, 2}, []int{10, 20}, map[int]int{10: 1, 20: 1}, compatible, nil, noShuffle)
+	assert.Equal(t, map[int]int{1: 20, 2: 10}, mentorOf)
+	assert.Empty(t, unmatched)
+
+	// mentee 1 takes the only room of mentor 10 first and moves for mentee 2
+	mentorOf, unmatched = assignMentees([]int{1, 2, 3}, []int{10, 20}, map[int]int{10: 1, 20: 1}, compatible, nil, noShuffle)
+	assert.Equal(t, map[int]int{1: 20, 2: 10}, mentorOf)
+	assert.Equal(t, []int{3}, unmatched)
+
+	mentorOf, unmatched = assignMentees([]int{1, 2, 3}, []int{10}, map[int]int{10: 2}, compatible, nil, noShuffle)
+	assert.Equal(t, map[int]int{1: 10, 2: 10}, mentorOf)
+	assert.Equal(t, []int{3}, unmatched)
+}
+
+func TestConstrainedPairs(t *testing.T) {
+	member := func(id int, tags entity.Tags) *entity.Member {
+		return &entity.Member{User: &entity.User{ID: id}, UserTags: tags}
+	}
+
+	members := []*entity.Member{
+		member(1, entity.Tags{{"department": "sales"}, {"city": "Moscow"}}),
+		member(2, entity.Tags{{"department": "sales"}, {"city": "Kazan"}, {"manager": float64(3)}}),
+		member(3, entity.Tags{{"department": "board"}, {"city": "Moscow"}}),
+		member(4, entity.Tags{{"department": "it"}, {"city": "Kazan"}}),
+	}
+	rules := []entity.ConstraintRule{
+		{Kind: entity.ConstraintNeverEqual, Tag: "department"},
+		{Kind: entity.ConstraintNeverID, Tag: "manager"},
+		{Kind: entity.ConstraintPreferDifferent, Tag: "city"},
+	}
+
+	pr := constrainedPairs(rules, members)
+	assert.Equal(t, map[pairKey]bool{pairOf(1, 2): true, pairOf(2, 3): true}, pr.forbid)
+	assert.Equal(t, map[pairKey]int{pairOf(1, 3): 1, pairOf(2, 4): 1}, pr.penalty)
+
+	// 2 may only meet 4, who goes to 1 first, so 2 is left out
+	groups, unmatched := matchGroups([]int{1, 3, 2, 4}, 2, 3, pr.with(nil), pr.penalty, noShuffle)
+	assert.Equal(t, [][]int{{1, 4, 3}}, groups)
+	assert.Equal(t, []int{2}, unmatched)
+
+	// without hard rules members from one city are kept apart
+	groups, unmatched = matchGroups([]int{1, 3, 2, 4}, 2, 3, nil, pr.penalty, noShuffle)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, groups)
+	assert.Empty(t, unmatched)
+}
+
+func TestPartitionGroupsForbidden(t *testing.T) {
+	// members 1 to 4 are from one department, two groups of three can't keep them apart
+	forbid := metPairs([][]int{{1, 2, 3, 4}})
+	costs := pairRules{forbid: forbid}.costs(nil)
+
+	groups, unmatched := partitionGroups([]int{1, 2, 3, 4, 5, 6}, 3, 2, 3, costs, noShuffle)
+	for _, group := range groups {
+		for i, member := range group {
+			assert.Less(t, cost(group[i+1:], member, member, costs), forbiddenCost)
+		}
+	}
+	assert.Len(t, unmatched, 2)
+}
+
+func TestPinnedGroups(t *testing.T) {
+	swapped, ok := swapMembers([][]int{{1, 2}, {3, 4}, {5, 6}}, 2, 5)
+	assert.True(t, ok)
+	assert.Equal(t, [][]int{{1, 5}, {2, 6}}, swapped)
+
+	_, ok = swapMembers([][]int{{1, 2}, {3, 4}}, 1, 2)
+	assert.False(t, ok)
+
+	overrides := []entity.Override{
+		{Kind: entity.OverridePin, UserIDs: []int{7, 8}},
+		{Kind: entity.OverrideForbid, UserIDs: []int{1, 7}},
+		{Kind: entity.OverrideSwap, UserIDs: []int{2, 5}, Groups: swapped},
+		{Kind: entity.OverridePin, UserIDs: []int{6, 9}},
+	}
+	assert.Equal(t, [][]int{{7, 8}, {1, 5}, {2, 6, 9}}, pinnedGroups(overrides))
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"log/slog"
+	"time"
+)
+
+type IMeetingRepository interface {
+	GetMeeting(ctx context.Context, id int) (*entity.Meeting, error)
+	UpdateMeeting(ctx context.Context, meeting *entity.Meeting, version int) error
+	OpenMeetings(ctx context.Context, spaceId, userId int) ([]*entity.Meeting, error)
+}
+
+func NewMeetingUseCase(mr IMeetingRepository, rm *Rematcher) *MeetingUseCase {
+	return &MeetingUseCase{meetingRepo: mr, rematcher: rm}
+}
+
+// MeetingUseCase moves meetings through their states on behalf of participants,
+// allowed transitions are defined by entity.MeetingState.
+type MeetingUseCase struct {
+	meetingRepo IMeetingRepository
+	rematcher   *Rematcher
+}
+
+func (mc *MeetingUseCase) GetMeeting(ctx context.Context, cmd commands.MeetingByIdCommand) (*entity.Meeting, error) {
+	const op = "Usecase:GetMeeting"
+
+	fail := func(err error) (*entity.Meeting, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", cmd.ID),
+	)
+	log.Debug(op)
+
+	meeting, err := mc.meetingRepo.GetMeeting(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get meeting", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return meeting, nil
+}
+
+func (mc *MeetingUseCase) AcceptMeeting(ctx context.Context, cmd commands.MeetingActionCommand) (*entity.Meeting, error) {
+	return mc.change(ctx, "Usecase:AcceptMeeting", cmd.MeetingID, cmd.UserID, func(m *entity.Meeting) error {
+		return m.Accept(cmd.UserID, time.Now())
+	})
+}
+
+// DeclineMeeting declines the meeting, other participants go to the rematch
+// pool. A participant who joined an accepted meeting leaves it and goes there alone.
+func (mc *MeetingUseCase) DeclineMeeting(ctx context.Context, cmd commands.MeetingActionCommand) (*entity.Meeting, error) {
+	meeting, err := mc.change(ctx, "Usecase:DeclineMeeting", cmd.MeetingID, cmd.UserID, func(m *entity.Meeting) error {
+		return m.Decline(cmd.UserID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the decline is saved, members left alone are a separate concern
+	if err = mc.rematcher.Declined(ctx, meeting, cmd.UserID); err != nil {
+		slog.Warn("couldn't rematch partners of declined meeting", slog.Int("meeting id", meeting.ID), slog.String("error", err.Error()))
+	}
+
+	return meeting, nil
+}
+
+// CompleteMeeting records that the meeting was held or who didn't come to it.
+func (mc *MeetingUseCase) CompleteMeeting(ctx context.Context, cmd commands.CompleteMeetingCommand) (*entity.Meeting, error) {
+	return mc.change(ctx, "Usecase:CompleteMeeting", cmd.MeetingID, cmd.UserID, func(m *entity.Meeting) error {
+		if cmd.NoShow {
+			return m.ReportNoShow(cmd.UserID, cmd.Absent)
+		}
+		return m.ConfirmHeld(cmd.UserID)
+	})
+}
+
+// ScheduleMeeting sets the time of the accepted meeting.
+func (mc *MeetingUseCase) ScheduleMeeting(ctx context.Context, cmd commands.ScheduleMeetingCommand) (*entity.Meeting, error) {
+	return mc.change(ctx, "Usecase:ScheduleMeeting", cmd.MeetingID, cmd.UserID, func(m *entity.Meeting) error {
+		return m.Schedule(cmd.UserID, cmd.At, time.Now())
+	})
+}
+
+// change applies action to the current state of the meeting and saves it,
+// actions of other participants made meanwhile are not lost.
+func (mc *MeetingUseCase) change(ctx context.Context, op string, meetingID, userID int, action func(m *entity.Meeting) error) (*entity.Meeting, error) {
+	fail := func(err error) (*entity.Meeting, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("meeting id", meetingID),
+		slog.Int("user id", userID),
+	)
+	log.Debug(op)
+
+	var meeting *entity.Meeting
+	var from entity.MeetingState
+
+	err := retryUpdate(0, func() error {
+		var err error
+		if meeting, err = mc.meetingRepo.GetMeeting(ctx, meetingID); err != nil {
+			return err
+		}
+
+		from = meeting.State
+		if err = action(meeting); err != nil {
+			return err
+		}
+
+		return mc.meetingRepo.UpdateMeeting(ctx, meeting, meeting.Version)
+	})
+	if err != nil {
+		log.Debug("couldn't change meeting", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if meeting.State != from {
+		metrics.MeetingTransitions.WithLabelValues(string(meeting.State)).Inc()
+		log.Info("meeting changed state", slog.String("from", string(from)), slog.String("to", string(meeting.State)))
+	}
+
+	return meeting, nil
+}
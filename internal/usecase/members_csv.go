@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrImportMapping  = errs.New(errs.Invalid, "import.mapping", "column mapping names unknown field or missing CSV column")
+	ErrImportHeader   = errs.New(errs.Invalid, "import.header", "CSV needs an id or first_name column")
+	ErrImportTooLarge = errs.New(errs.Invalid, "import.too_large", "CSV has too many rows")
+)
+
+// maxImportRows bounds a single import, every row is validated against the database.
+const maxImportRows = 10000
+
+// Fields of members CSV in export column order. Import reads the user fields,
+// the rest are informational and skipped.
+const (
+	fieldID        = "id"
+	fieldFirstName = "first_name"
+	fieldLastName  = "last_name"
+	fieldUserName  = "username"
+	fieldPhotoURL  = "photo_url"
+)
+
+var (
+	memberUserFields   = []string{fieldID, fieldFirstName, fieldLastName, fieldUserName, fieldPhotoURL}
+	memberExportFields = append(memberUserFields[:len(memberUserFields):len(memberUserFields)], "role", "status", "joined_at")
+)
+
+// importRow is a parsed CSV row, user ID is 0 for users to be created.
+type importRow struct {
+	row  int
+	user *entity.User
+	tags entity.Tags
+}
+
+// parseMembersCSV reads rows of members CSV. Rows that can't be imported are
+// reported in the returned MemberImport, error means the file can't be read at all.
+func parseMembersCSV(data []byte, columns map[string]string, tagColumns []string) ([]*importRow, *entity.MemberImport, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, ErrImportHeader
+		}
+		return nil, nil, errors.Join(ErrImportHeader, err)
+	}
+	// record is reused by the next Read
+	header = append([]string(nil), header...)
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	fields := make(map[string]int, len(memberUserFields))
+	for _, field := range memberUserFields {
+		name, mapped := columns[field]
+		if !mapped {
+			name = field
+		}
+
+		i, ok := index[name]
+		switch {
+		case ok:
+			fields[field] = i
+		case mapped:
+			return nil, nil, fmt.Errorf("%w: no column %q for %s", ErrImportMapping, name, field)
+		}
+	}
+
+	for field := range columns {
+		if !isUserField(field) {
+			return nil, nil, fmt.Errorf("%w: unknown field %q", ErrImportMapping, field)
+		}
+	}
+
+	tags := make([]int, len(tagColumns))
+	for i, name := range tagColumns {
+		col, ok := index[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: no tag column %q", ErrImportMapping, name)
+		}
+		tags[i] = col
+	}
+
+	_, hasID := fields[fieldID]
+	_, hasName := fields[fieldFirstName]
+	if !hasID && !hasName {
+		return nil, nil, ErrImportHeader
+	}
+
+	report := &entity.MemberImport{Errors: make([]entity.ImportError, 0)}
+	rows := make([]*importRow, 0)
+	seen := make(map[int]int)
+	now := time.Now()
+
+	for n := 2; ; n++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		report.Rows++
+		if report.Rows > maxImportRows {
+			return nil, nil, ErrImportTooLarge
+		}
+
+		switch {
+		case errors.Is(err, csv.ErrFieldCount):
+			report.Errors = append(report.Errors, entity.ImportError{Row: n, Message: "row has wrong number of columns"})
+			continue
+		case err != nil:
+			// reader can't find where the broken row ends, the rest of file is not trusted
+			report.Errors = append(report.Errors, entity.ImportError{Row: n, Message: "malformed CSV: " + err.Error()})
+			return rows, report, nil
+		}
+
+		cell := func(field string) string {
+			if i, ok := fields[field]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rowErr := func(field, message string) {
+			report.Errors = append(report.Errors, entity.ImportError{Row: n, Column: columnOf(header, fields, field), Message: message})
+		}
+
+		row := &importRow{
+			row: n,
+			user: &entity.User{
+				FirstName: cell(fieldFirstName),
+				LastName:  cell(fieldLastName),
+				UserName:  cell(fieldUserName),
+				PhotoURL:  cell(fieldPhotoURL),
+				AuthDate:  now,
+			},
+		}
+
+		if id := cell(fieldID); id != "" {
+			userID, err := strconv.Atoi(id)
+			if err != nil || userID <= 0 {
+				rowErr(fieldID, "id must be a positive integer")
+				continue
+			}
+			if first, ok := seen[userID]; ok {
+				rowErr(fieldID, fmt.Sprintf("user is already imported by row %d", first))
+				continue
+			}
+			seen[userID] = n
+			row.user.ID = userID
+		} else if row.user.FirstName == "" {
+			if hasName {
+				rowErr(fieldFirstName, "first_name is required for new users")
+			} else {
+				rowErr(fieldID, "id is required without first_name column")
+			}
+			continue
+		}
+
+		for i, col := range tags {
+			if value := strings.TrimSpace(record[col]); value != "" {
+				row.tags = append(row.tags, map[string]interface{}{tagColumns[i]: value})
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, report, nil
+}
+
+func columnOf(header []string, fields map[string]int, field string) string {
+	if i, ok := fields[field]; ok {
+		return strings.TrimSpace(header[i])
+	}
+	return ""
+}
+
+func isUserField(field string) bool {
+	for _, f := range memberUserFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// membersCSVWriter writes members in the format parseMembersCSV reads, so an
+// export can be imported into another space.
+type membersCSVWriter struct {
+	w    *csv.Writer
+	tags []string
+}
+
+func newMembersCSVWriter(w io.Writer, tagColumns []string) *membersCSVWriter {
+	return &membersCSVWriter{w: csv.NewWriter(w), tags: tagColumns}
+}
+
+func (m *membersCSVWriter) header() error {
+	return m.w.Write(append(memberExportFields[:len(memberExportFields):len(memberExportFields)], m.tags...))
+}
+
+func (m *membersCSVWriter) write(members []*entity.Member) error {
+	record := make([]string, 0, len(memberExportFields)+len(m.tags))
+
+	for _, member := range members {
+		record = append(record[:0],
+			strconv.Itoa(member.User.ID),
+			member.User.FirstName,
+			member.User.LastName,
+			member.User.UserName,
+			member.User.PhotoURL,
+			string(member.Role),
+			string(member.Status),
+			member.JoinedAt.UTC().Format(time.RFC3339),
+		)
+
+		for _, name := range m.tags {
+			record = append(record, tagValue(member.UserTags, name))
+		}
+
+		if err := m.w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	m.w.Flush()
+
+	return m.w.Error()
+}
+
+// tagValue formats tag for a CSV cell: strings as is, other values as JSON.
+func tagValue(tags entity.Tags, name string) string {
+	for _, tag := range tags {
+		value, ok := tag[name]
+		if !ok || value == nil {
+			continue
+		}
+
+		if s, ok := value.(string); ok {
+			return s
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+
+	return ""
+}
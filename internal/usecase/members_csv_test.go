@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMembersCSV(t *testing.T) {
+	data := "Name,Telegram,id,department\n" +
+		"Ivan,ivan,,sales\n" +
+		",,42,\n" +
+		",petr,,it\n" +
+		"Anna,anna,x,\n" +
+		"Olga,olga,42,\n" +
+		"Oleg\n"
+
+	rows, report, err := parseMembersCSV([]byte(data), map[string]string{"first_name": "Name", "username": "Telegram"}, []string{"department"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, report.Rows)
+	assert.Len(t, rows, 2)
+
+	assert.Equal(t, 2, rows[0].row)
+	assert.Equal(t, 0, rows[0].user.ID)
+	assert.Equal(t, "Ivan", rows[0].user.FirstName)
+	assert.Equal(t, "ivan", rows[0].user.UserName)
+	assert.Equal(t, entity.Tags{{"department": "sales"}}, rows[0].tags)
+
+	assert.Equal(t, 42, rows[1].user.ID)
+	assert.Empty(t, rows[1].tags)
+
+	assert.Equal(t, []entity.ImportError{
+		{Row: 4, Column: "Name", Message: "first_name is required for new users"},
+		{Row: 5, Column: "id", Message: "id must be a positive integer"},
+		{Row: 6, Column: "id", Message: "user is already imported by row 3"},
+		{Row: 7, Message: "row has wrong number of columns"},
+	}, report.Errors)
+}
+
+func TestParseMembersCSVMapping(t *testing.T) {
+	for name, columns := range map[string]map[string]string{
+		"unknown field":  {"email": "id"},
+		"missing column": {"first_name": "Name"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseMembersCSV([]byte("id,first_name\n1,ivan\n"), columns, nil)
+			assert.ErrorIs(t, err, ErrImportMapping)
+		})
+	}
+
+	_, _, err := parseMembersCSV([]byte("id\n1\n"), nil, []string{"city"})
+	assert.ErrorIs(t, err, ErrImportMapping)
+
+	_, _, err = parseMembersCSV([]byte("last_name,username\nivanov,ivan\n"), nil, nil)
+	assert.ErrorIs(t, err, ErrImportHeader)
+}
+
+func TestMembersCSVRoundTrip(t *testing.T) {
+	members := []*entity.Member{{
+		User:     &entity.User{ID: 7, FirstName: "Ivan", LastName: "Ivanov, Jr", UserName: "ivan"},
+		Role:     entity.RoleMember,
+		Status:   entity.StatusActive,
+		UserTags: entity.Tags{{"department": "sales"}, {"level": 3.0}},
+		JoinedAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}}
+
+	var buf bytes.Buffer
+	w := newMembersCSVWriter(&buf, []string{"department", "level", "city"})
+	assert.NoError(t, w.header())
+	assert.NoError(t, w.write(members))
+
+	assert.Equal(t, "id,first_name,last_name,username,photo_url,role,status,joined_at,department,level,city\n"+
+		"7,Ivan,\"Ivanov, Jr\",ivan,,member,active,2026-10-19T12:00:00Z,sales,3,\n", buf.String())
+
+	rows, report, err := parseMembersCSV(buf.Bytes(), nil, []string{"department"})
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, 7, rows[0].user.ID)
+	assert.Equal(t, "Ivanov, Jr", rows[0].user.LastName)
+	assert.Equal(t, entity.Tags{{"department": "sales"}}, rows[0].tags)
+}
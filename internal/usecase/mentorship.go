@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"log/slog"
+)
+
+var ErrNotMentorshipMember = errs.New(errs.Forbidden, "mentorship.not_member", "user is neither in mentorship nor space admin")
+
+type IMentorshipRepository interface {
+	GetMentorship(ctx context.Context, id int) (*entity.Mentorship, error)
+	GetMentorships(ctx context.Context, spaceId int, withEnded bool) ([]*entity.Mentorship, error)
+	EndMentorship(ctx context.Context, id int) error
+}
+
+func NewMentorshipUseCase(mr IMentorshipRepository, sr ISpaceRepository) *MentorshipUseCase {
+	return &MentorshipUseCase{mentorshipRepo: mr, spaceRepo: sr}
+}
+
+// MentorshipUseCase shows and ends mentorships, they are started by rounds of
+// spaces in mentoring mode.
+type MentorshipUseCase struct {
+	mentorshipRepo IMentorshipRepository
+	spaceRepo      ISpaceRepository
+}
+
+func (mc *MentorshipUseCase) GetMentorships(ctx context.Context, cmd commands.MentorshipsCommand) ([]*entity.Mentorship, error) {
+	const op = "Usecase:GetMentorships"
+
+	fail := func(err error) ([]*entity.Mentorship, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+	)
+	log.Debug(op)
+
+	if _, err := mc.spaceRepo.GetSpace(ctx, cmd.SpaceID); err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	mentorships, err := mc.mentorshipRepo.GetMentorships(ctx, cmd.SpaceID, cmd.WithEnded)
+	if err != nil {
+		log.Debug("couldn't get mentorships", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return mentorships, nil
+}
+
+// EndMentorship ends the mentorship on behalf of its mentor, mentee or a space
+// admin. The mentee gets a new mentor in the next round.
+func (mc *MentorshipUseCase) EndMentorship(ctx context.Context, cmd commands.EndMentorshipCommand) (*entity.Mentorship, error) {
+	const op = "Usecase:EndMentorship"
+
+	fail := func(err error) (*entity.Mentorship, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("mentorship id", cmd.ID),
+		slog.Int("user id", cmd.UserID),
+	)
+	log.Debug(op)
+
+	mentorship, err := mc.mentorshipRepo.GetMentorship(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get mentorship", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if !mentorship.Active() {
+		return fail(entity.ErrMentorshipEnded)
+	}
+
+	if cmd.UserID != mentorship.MentorID && cmd.UserID != mentorship.MenteeID {
+		if err = checkAdmin(ctx, mc.spaceRepo, mentorship.SpaceID, cmd.UserID); err != nil {
+			log.Debug("couldn't check admin", slog.String("error", err.Error()))
+			if errors.Is(err, ErrNotSpaceAdmin) {
+				err = ErrNotMentorshipMember
+			}
+			return fail(err)
+		}
+	}
+
+	if err = mc.mentorshipRepo.EndMentorship(ctx, cmd.ID); err != nil {
+		log.Debug("couldn't end mentorship", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if mentorship, err = mc.mentorshipRepo.GetMentorship(ctx, cmd.ID); err != nil {
+		log.Debug("couldn't get mentorship", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return mentorship, nil
+}
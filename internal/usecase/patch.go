@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
+	"github.com/Slava02/Involvio/pkg/mergepatch"
+)
+
+var (
+	ErrInvalidPatch  = errs.New(errs.Invalid, "patch.invalid", "merge patch doesn't fit the object")
+	ErrRequiredField = errs.New(errs.Invalid, "patch.required_field", "merge patch removes or empties a required field")
+)
+
+// updateAttempts bounds reapplying changes without If-Match that raced with another update.
+const updateAttempts = 3
+
+// Patchable documents, their fields are all a merge patch may change.
+type (
+	spaceDoc struct {
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		Tags        entity.Tags `json:"tags"`
+	}
+
+	userDoc struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		UserName  string `json:"username"`
+		PhotoURL  string `json:"photo_url"`
+	}
+
+	formDoc struct {
+		UserTags     entity.Tags          `json:"user_tags"`
+		PairTags     entity.Tags          `json:"pair_tags"`
+		Availability *entity.Availability `json:"availability"`
+	}
+)
+
+// applyPatch applies RFC 7396 merge patch to doc. Members named in tagFields
+// are entity.Tags: an object there patches single tags keyed by name, see
+// entity.Tags.Patch, an array or null replaces all tags as usual.
+func applyPatch[T any](doc T, patch map[string]any, tagFields ...string) (T, error) {
+	var patched T
+
+	fail := func(err error) (T, error) {
+		return patched, errors.Join(ErrInvalidPatch, err)
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return fail(err)
+	}
+
+	var target map[string]any
+	if err = json.Unmarshal(b, &target); err != nil {
+		return fail(err)
+	}
+
+	p := make(map[string]any, len(patch))
+	for name, value := range patch {
+		p[name] = value
+	}
+
+	for _, field := range tagFields {
+		keyed, ok := p[field].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var tags entity.Tags
+		if b, err = json.Marshal(target[field]); err != nil {
+			return fail(err)
+		}
+		if err = json.Unmarshal(b, &tags); err != nil {
+			return fail(err)
+		}
+
+		p[field] = tags.Patch(keyed)
+	}
+
+	if b, err = json.Marshal(mergepatch.Merge(target, p)); err != nil {
+		return fail(err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&patched); err != nil {
+		return fail(err)
+	}
+
+	return patched, nil
+}
+
+// retryUpdate runs read-modify-write attempt. Attempt reads the object, updates
+// the version it read unless ifMatch is given and applies the change. Changes with
+// If-Match fail on concurrent update, others are applied again to the new state.
+func retryUpdate(ifMatch int, attempt func() error) error {
+	for i := 1; ; i++ {
+		err := attempt()
+		if ifMatch > 0 || i == updateAttempts || !errors.Is(err, errs.ErrVersionMismatch) {
+			return err
+		}
+	}
+}
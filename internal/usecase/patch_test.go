@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc := spaceDoc{Name: "mai", Description: "university", Tags: entity.Tags{{"city": "Moscow"}}}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  spaceDoc
+	}{
+		{"absent keeps", `{}`, doc},
+		{"null clears", `{"description":null}`, spaceDoc{Name: "mai", Tags: doc.Tags}},
+		{"empty sets empty", `{"description":""}`, spaceDoc{Name: "mai", Tags: doc.Tags}},
+		{"array replaces tags", `{"tags":[{"lang":"ru"}]}`, spaceDoc{Name: "mai", Description: "university", Tags: entity.Tags{{"lang": "ru"}}}},
+		{"empty array clears tags", `{"tags":[]}`, spaceDoc{Name: "mai", Description: "university", Tags: entity.Tags{}}},
+		{"object patches tags", `{"tags":{"lang":"ru"}}`, spaceDoc{Name: "mai", Description: "university", Tags: entity.Tags{{"city": "Moscow"}, {"lang": "ru"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+
+			got, err := applyPatch(doc, patch, "tags")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyPatchInvalid(t *testing.T) {
+	for _, patch := range []map[string]any{
+		{"id": 1},
+		{"name": 1},
+		{"tags": "x"},
+	} {
+		_, err := applyPatch(spaceDoc{Name: "mai"}, patch, "tags")
+		assert.ErrorIs(t, err, ErrInvalidPatch)
+	}
+}
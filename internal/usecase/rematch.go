@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+func NewRematcher(rr IRoundRepository, mr IMeetingRepository, sr ISpaceRepository) *Rematcher {
+	return &Rematcher{roundRepo: rr, meetingRepo: mr, spaceRepo: sr}
+}
+
+// Rematcher finds new partners for members left alone when their meeting was
+// declined or cancelled. They wait in the rematch pool of the round and are
+// matched among themselves or join an open meeting until the settings
+// rematch deadline. History, availability, constraint rules and membership
+// status are respected as in CreateRound. Mentoring rounds are never rematched.
+type Rematcher struct {
+	roundRepo   IRoundRepository
+	meetingRepo IMeetingRepository
+	spaceRepo   ISpaceRepository
+}
+
+// Declined pools participants of the declined meeting who didn't decline it,
+// or only the participant who left a meeting they joined.
+func (r *Rematcher) Declined(ctx context.Context, meeting *entity.Meeting, userID int) error {
+	const op = "Usecase:Rematcher.Declined"
+
+	left := meeting.Remaining()
+	if meeting.State != entity.MeetingDeclined {
+		left = []int{userID}
+	}
+
+	if err := r.pool(ctx, meeting, left); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Withdrawn cancels open meetings of the member who was suspended or removed
+// from the space and pools the other participants.
+func (r *Rematcher) Withdrawn(ctx context.Context, spaceID, userID int) error {
+	const op = "Usecase:Rematcher.Withdrawn"
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", spaceID),
+		slog.Int("user id", userID),
+	)
+	log.Debug(op)
+
+	meetings, err := r.meetingRepo.OpenMeetings(ctx, spaceID, userID)
+	if err != nil {
+		log.Debug("couldn't get open meetings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	for _, open := range meetings {
+		meeting := open
+		err = retryUpdate(0, func() error {
+			if meeting, err = r.meetingRepo.GetMeeting(ctx, open.ID); err != nil {
+				return err
+			}
+
+			if meeting.State.Final() {
+				return nil
+			}
+
+			if err = meeting.Cancel(); err != nil {
+				return err
+			}
+
+			return r.meetingRepo.UpdateMeeting(ctx, meeting, meeting.Version)
+		})
+		if err != nil {
+			log.Debug("couldn't cancel meeting", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		if meeting.State != entity.MeetingCancelled {
+			continue
+		}
+		metrics.MeetingTransitions.WithLabelValues(string(entity.MeetingCancelled)).Inc()
+
+		left := make([]int, 0, len(meeting.Participants))
+		for _, id := range meeting.Remaining() {
+			if id != userID {
+				left = append(left, id)
+			}
+		}
+
+		if err = r.pool(ctx, meeting, left); err != nil {
+			return fail(err)
+		}
+	}
+
+	return nil
+}
+
+// pool puts members left alone by the meeting into the rematch pool and
+// rematches its round.
+func (r *Rematcher) pool(ctx context.Context, meeting *entity.Meeting, left []int) error {
+	log := slog.With(
+		slog.Int("round id", meeting.RoundID),
+		slog.Int("meeting id", meeting.ID),
+	)
+
+	if len(left) == 0 {
+		return nil
+	}
+
+	if err := r.roundRepo.AddToPool(ctx, meeting.RoundID, meeting.ID, left); err != nil {
+		log.Debug("couldn't add to rematch pool", slog.String("error", err.Error()))
+		return err
+	}
+
+	return r.rematch(ctx, meeting.RoundID)
+}
+
+// rematch matches the rematch pool of the round if its deadline hasn't passed.
+func (r *Rematcher) rematch(ctx context.Context, roundID int) error {
+	log := slog.With(
+		slog.Int("round id", roundID),
+	)
+
+	var created, joined []*entity.Meeting
+	var unmatched []int
+
+	// retries shuffle the same way, the seed is logged with the result
+	seed := rand.Int64N(entity.MaxSeed)
+
+	err := retryUpdate(0, func() error {
+		created, joined, unmatched = nil, nil, nil
+
+		round, err := r.roundRepo.GetRound(ctx, roundID)
+		if err != nil {
+			return err
+		}
+
+		if len(round.RematchPool) == 0 {
+			return nil
+		}
+
+		settings, err := r.spaceRepo.GetSettings(ctx, round.SpaceID, round.SettingsVersion)
+		if err != nil {
+			return err
+		}
+
+		// mentors and mentees meet nobody but each other
+		if settings.Mode == entity.MatchMentoring {
+			unmatched = round.RematchPool
+			return nil
+		}
+
+		now := time.Now()
+		if !now.Before(settings.RematchDeadline(round.CreatedAt)) {
+			log.Debug("rematch deadline has passed")
+			unmatched = round.RematchPool
+			return nil
+		}
+
+		members, err := activeMembers(ctx, r.spaceRepo, round.SpaceID)
+		if err != nil {
+			return err
+		}
+
+		availability := make(map[int]*entity.Availability, len(members))
+		for _, member := range members {
+			availability[member.User.ID] = member.Availability
+		}
+
+		held, err := r.roundRepo.HeldMeetings(ctx, round.SpaceID, settings.RepeatWindow)
+		if err != nil {
+			return err
+		}
+
+		rules := constrainedPairs(settings.Constraints, members)
+		avoid := rules.with(metPairs(held))
+
+		// members of open meetings aren't alone, open meetings with a member
+		// who is no longer active don't take anyone new
+		busy := make(map[int]bool)
+		open := make([]*entity.Meeting, 0, len(round.Meetings))
+		groups := make([][]int, 0, len(round.Meetings))
+		for _, meeting := range round.Meetings {
+			// who declined a meeting or left one they joined isn't matched with its participants again
+			for _, p := range meeting.Participants {
+				if p.Response == entity.ResponseDeclined {
+					for _, q := range meeting.Participants {
+						if q.UserID != p.UserID {
+							avoid[pairOf(p.UserID, q.UserID)] = true
+						}
+					}
+				}
+			}
+
+			if meeting.State.Final() {
+				continue
+			}
+
+			group := meeting.Remaining()
+			active := true
+			for _, userID := range group {
+				busy[userID] = true
+				if _, ok := availability[userID]; !ok {
+					active = false
+				}
+			}
+
+			if active {
+				open = append(open, meeting)
+				groups = append(groups, group)
+			}
+		}
+
+		pool := make([]int, 0, len(round.RematchPool))
+		for _, id := range round.RematchPool {
+			if _, ok := availability[id]; ok && !busy[id] {
+				pool = append(pool, id)
+			}
+		}
+
+		noOverlapPairs(availability, now, avoid)
+
+		size, minSize, maxSize := settings.GroupBounds()
+		newGroups, joins, left := rematchPool(pool, groups, size, minSize, maxSize, avoid, rules.penalty, rand.New(rand.NewPCG(uint64(seed), 0)).Shuffle)
+		unmatched = left
+		if len(newGroups) == 0 && len(joins) == 0 {
+			return nil
+		}
+
+		matched := make([]int, 0, len(pool))
+		for _, group := range newGroups {
+			meeting := &entity.Meeting{RoundID: round.ID, SpaceID: round.SpaceID, State: entity.MeetingProposed}
+			slots := make([]*entity.Availability, 0, len(group))
+			for _, userID := range group {
+				meeting.Participants = append(meeting.Participants, &entity.Participant{UserID: userID, Response: entity.ResponsePending})
+				slots = append(slots, availability[userID])
+			}
+			meeting.ProposedSlots = entity.ProposeSlots(slots, now, entity.ProposedSlots)
+
+			created = append(created, meeting)
+			matched = append(matched, group...)
+		}
+
+		grown := make(map[int]*entity.Meeting)
+		for userID, i := range joins {
+			meeting := open[i]
+			if err = meeting.Join(userID); err != nil {
+				return err
+			}
+
+			grown[meeting.ID] = meeting
+			matched = append(matched, userID)
+		}
+
+		for _, meeting := range grown {
+			if meeting.State != entity.MeetingScheduled {
+				slots := make([]*entity.Availability, 0, len(meeting.Participants))
+				for _, userID := range meeting.Remaining() {
+					slots = append(slots, availability[userID])
+				}
+				meeting.ProposedSlots = entity.ProposeSlots(slots, now, entity.ProposedSlots)
+			}
+
+			joined = append(joined, meeting)
+		}
+
+		return r.roundRepo.Rematch(ctx, round.ID, matched, created, joined)
+	})
+	if err != nil {
+		log.Debug("couldn't rematch", slog.String("error", err.Error()))
+		return err
+	}
+
+	if len(created) > 0 || len(joined) > 0 {
+		metrics.MeetingTransitions.WithLabelValues(string(entity.MeetingProposed)).Add(float64(len(created)))
+		log.Info("rematched", slog.Int("meetings", len(created)), slog.Int("joined", len(joined)), slog.Int("unmatched", len(unmatched)),
+			slog.Int64("seed", seed))
+	}
+
+	return nil
+}
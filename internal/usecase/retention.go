@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type IRetentionRepository interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+func NewRetentionUseCase(rr IRetentionRepository, period time.Duration) *RetentionUseCase {
+	return &RetentionUseCase{retentionRepo: rr, period: period}
+}
+
+// RetentionUseCase purges rows that stay soft deleted longer than period.
+type RetentionUseCase struct {
+	retentionRepo IRetentionRepository
+	period        time.Duration
+}
+
+func (rc *RetentionUseCase) Purge(ctx context.Context) error {
+	const op = "Usecase:Purge"
+
+	log := slog.With(
+		slog.String("op", op),
+	)
+	log.Debug(op)
+
+	purged, err := rc.retentionRepo.Purge(ctx, time.Now().Add(-rc.period))
+	if err != nil {
+		log.Debug("couldn't purge deleted rows", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if purged > 0 {
+		log.Info("purged deleted rows", slog.Int64("rows", purged))
+	}
+
+	return nil
+}
@@ -0,0 +1,805 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/repository"
+	"github.com/Slava02/Involvio/internal/usecase/commands"
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"github.com/google/uuid"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+type IRoundRepository interface {
+	InsertRound(ctx context.Context, round *entity.Round, ended []int, started []*entity.Mentorship) error
+	GetRound(ctx context.Context, id int) (*entity.Round, error)
+	GetSnapshot(ctx context.Context, roundId int) (*entity.RoundSnapshot, [][]int, error)
+	HeldMeetings(ctx context.Context, spaceId, rounds int) ([][]int, error)
+	AddToPool(ctx context.Context, roundId, meetingId int, userIds []int) error
+	Rematch(ctx context.Context, roundId int, matched []int, created, joined []*entity.Meeting) error
+	InsertPreview(ctx context.Context, preview *entity.RoundPreview) error
+	GetPreview(ctx context.Context, id string) (*entity.RoundPreview, error)
+	UpdatePreview(ctx context.Context, preview *entity.RoundPreview, version int) error
+	PublishPreview(ctx context.Context, id string, round *entity.Round, ended []int, started []*entity.Mentorship) error
+}
+
+func NewRoundUseCase(rr IRoundRepository, sr ISpaceRepository, mr IMentorshipRepository) *RoundUseCase {
+	return &RoundUseCase{roundRepo: rr, spaceRepo: sr, mentorshipRepo: mr}
+}
+
+type RoundUseCase struct {
+	roundRepo      IRoundRepository
+	spaceRepo      ISpaceRepository
+	mentorshipRepo IMentorshipRepository
+}
+
+// roundPlan is a matched round before anything is saved.
+type roundPlan struct {
+	round     *entity.Round
+	meetings  []*entity.PlannedMeeting
+	unmatched []entity.Exclusion
+	metrics   entity.MatchMetrics
+	ended     []int
+	started   []*entity.Mentorship
+	snapshot  *entity.RoundSnapshot
+}
+
+// CreateRound matches active members of the space into meetings with current
+// space settings, see plan, and sends them out.
+func (rc *RoundUseCase) CreateRound(ctx context.Context, cmd commands.CreateRoundCommand) (*entity.Round, error) {
+	const op = "Usecase:CreateRound"
+
+	fail := func(err error) (*entity.Round, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	_, err := rc.spaceRepo.GetSpace(ctx, cmd.SpaceID)
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = checkAdmin(ctx, rc.spaceRepo, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	plan, err := rc.plan(ctx, cmd.SpaceID, nil)
+	if err != nil {
+		log.Debug("couldn't match members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	round := plan.round
+	err = rc.roundRepo.InsertRound(ctx, round, plan.ended, plan.started)
+	if err != nil {
+		log.Debug("couldn't insert round", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	roundCreated(log, round)
+
+	return round, nil
+}
+
+// PreviewRound matches the round as CreateRound does but saves only the
+// preview, nobody is told about it until it's published.
+func (rc *RoundUseCase) PreviewRound(ctx context.Context, cmd commands.CreateRoundCommand) (*entity.RoundPreview, error) {
+	const op = "Usecase:PreviewRound"
+
+	fail := func(err error) (*entity.RoundPreview, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	_, err := rc.spaceRepo.GetSpace(ctx, cmd.SpaceID)
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = checkAdmin(ctx, rc.spaceRepo, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	plan, err := rc.plan(ctx, cmd.SpaceID, nil)
+	if err != nil {
+		log.Debug("couldn't match members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	preview := &entity.RoundPreview{
+		ID:                 uuid.NewString(),
+		SpaceID:            cmd.SpaceID,
+		AdminID:            cmd.AdminID,
+		SettingsVersion:    plan.round.SettingsVersion,
+		Meetings:           plan.meetings,
+		Unmatched:          plan.unmatched,
+		NoOverlap:          plan.round.NoOverlap,
+		Metrics:            plan.metrics,
+		Overrides:          []entity.Override{},
+		Seed:               plan.round.Seed,
+		Strategy:           plan.round.Strategy,
+		StrategyVersion:    plan.round.StrategyVersion,
+		Snapshot:           plan.snapshot,
+		EndedMentorships:   plan.ended,
+		StartedMentorships: plan.started,
+	}
+
+	err = rc.roundRepo.InsertPreview(ctx, preview)
+	if err != nil {
+		log.Debug("couldn't insert preview", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	log.Info("round previewed", slog.String("preview id", preview.ID), slog.Int("meetings", len(preview.Meetings)),
+		slog.Int("unmatched", len(preview.Unmatched)))
+
+	return preview, nil
+}
+
+// OverridePreview adds an admin override to the preview and matches the rest
+// of the round again around all its overrides, a stale preview is fresh
+// again. A swap exchanges members between their meetings in the preview as it
+// is now, and those meetings are kept afterwards.
+func (rc *RoundUseCase) OverridePreview(ctx context.Context, cmd commands.OverridePreviewCommand) (*entity.RoundPreview, error) {
+	const op = "Usecase:OverridePreview"
+
+	fail := func(err error) (*entity.RoundPreview, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.String("preview id", cmd.PreviewID),
+		slog.Int("admin id", cmd.AdminID),
+		slog.String("kind", string(cmd.Kind)),
+	)
+	log.Debug(op)
+
+	override := entity.Override{Kind: cmd.Kind, UserIDs: cmd.UserIDs, AdminID: cmd.AdminID, CreatedAt: time.Now()}
+	if err := override.Validate(); err != nil {
+		return fail(err)
+	}
+
+	err := checkAdmin(ctx, rc.spaceRepo, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	preview, err := rc.roundRepo.GetPreview(ctx, cmd.PreviewID)
+	if err != nil {
+		log.Debug("couldn't get preview", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if preview.SpaceID != cmd.SpaceID {
+		return fail(repository.ErrPreviewNotFound)
+	}
+
+	if len(preview.Overrides) >= entity.MaxOverrides {
+		return fail(entity.ErrTooManyOverrides)
+	}
+
+	if override.Kind == entity.OverrideSwap {
+		groups := make([][]int, 0, len(preview.Meetings))
+		for _, meeting := range preview.Meetings {
+			groups = append(groups, meeting.Participants)
+		}
+
+		var ok bool
+		if override.Groups, ok = swapMembers(groups, override.UserIDs[0], override.UserIDs[1]); !ok {
+			return fail(entity.ErrInvalidSwap)
+		}
+	}
+
+	overrides := append(append(make([]entity.Override, 0, len(preview.Overrides)+1), preview.Overrides...), override)
+
+	plan, err := rc.plan(ctx, cmd.SpaceID, overrides)
+	if err != nil {
+		log.Debug("couldn't match members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if plan.round.SettingsVersion != preview.SettingsVersion {
+		return fail(entity.ErrPreviewStale)
+	}
+
+	preview.Meetings = plan.meetings
+	preview.Unmatched = plan.unmatched
+	preview.NoOverlap = plan.round.NoOverlap
+	preview.Metrics = plan.metrics
+	preview.Overrides = overrides
+	preview.Seed, preview.Strategy, preview.StrategyVersion = plan.round.Seed, plan.round.Strategy, plan.round.StrategyVersion
+	preview.Snapshot = plan.snapshot
+	preview.Stale = ""
+
+	err = rc.roundRepo.UpdatePreview(ctx, preview, preview.Version)
+	if err != nil {
+		log.Debug("couldn't update preview", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	log.Info("round preview overridden", slog.Int("overrides", len(preview.Overrides)), slog.Int("meetings", len(preview.Meetings)))
+
+	return preview, nil
+}
+
+// PublishPreview creates the round of the preview exactly as it was matched.
+// A preview is published once, and only while space settings are the ones it
+// was matched with, all its participants are still active members and no
+// member was erased from it.
+func (rc *RoundUseCase) PublishPreview(ctx context.Context, cmd commands.PublishPreviewCommand) (*entity.Round, error) {
+	const op = "Usecase:PublishPreview"
+
+	fail := func(err error) (*entity.Round, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.String("preview id", cmd.PreviewID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	err := checkAdmin(ctx, rc.spaceRepo, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	preview, err := rc.roundRepo.GetPreview(ctx, cmd.PreviewID)
+	if err != nil {
+		log.Debug("couldn't get preview", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if preview.SpaceID != cmd.SpaceID {
+		return fail(repository.ErrPreviewNotFound)
+	}
+
+	if preview.Stale != "" {
+		return fail(entity.ErrPreviewStale)
+	}
+
+	settings, err := rc.spaceRepo.GetSettings(ctx, cmd.SpaceID, 0)
+	if err != nil {
+		log.Debug("couldn't get settings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if settings.Version != preview.SettingsVersion {
+		return fail(entity.ErrPreviewStale)
+	}
+
+	members, err := activeMembers(ctx, rc.spaceRepo, cmd.SpaceID)
+	if err != nil {
+		log.Debug("couldn't get members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	active := make(map[int]bool, len(members))
+	for _, member := range members {
+		active[member.User.ID] = true
+	}
+
+	round := &entity.Round{
+		SpaceID:         cmd.SpaceID,
+		SettingsVersion: preview.SettingsVersion,
+		Seed:            preview.Seed,
+		Strategy:        preview.Strategy,
+		StrategyVersion: preview.StrategyVersion,
+		Snapshot:        preview.Snapshot,
+		Meetings:        make([]*entity.Meeting, 0, len(preview.Meetings)),
+		Unmatched:       unmatchedOf(preview.Unmatched),
+		NoOverlap:       preview.NoOverlap,
+		RematchPool:     []int{},
+	}
+
+	for _, planned := range preview.Meetings {
+		meeting := &entity.Meeting{State: entity.MeetingProposed, ProposedSlots: planned.ProposedSlots}
+		for _, userID := range planned.Participants {
+			if !active[userID] {
+				return fail(entity.ErrPreviewStale)
+			}
+			meeting.Participants = append(meeting.Participants, &entity.Participant{UserID: userID, Response: entity.ResponsePending})
+		}
+
+		round.Meetings = append(round.Meetings, meeting)
+	}
+
+	err = rc.roundRepo.PublishPreview(ctx, preview.ID, round, preview.EndedMentorships, preview.StartedMentorships)
+	if err != nil {
+		log.Debug("couldn't publish preview", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	roundCreated(log, round)
+
+	return round, nil
+}
+
+func (rc *RoundUseCase) GetRound(ctx context.Context, cmd commands.RoundByIdCommand) (*entity.Round, error) {
+	const op = "Usecase:GetRound"
+
+	fail := func(err error) (*entity.Round, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", cmd.ID),
+	)
+	log.Debug(op)
+
+	round, err := rc.roundRepo.GetRound(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get round", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return round, nil
+}
+
+// ReplayRound matches the round again from its snapshot and seed and diffs
+// the result with meetings as the round matched them. A replay reproduces the
+// round unless its strategy changed since or members of the round were erased.
+func (rc *RoundUseCase) ReplayRound(ctx context.Context, cmd commands.ReplayRoundCommand) (*entity.RoundReplay, error) {
+	const op = "Usecase:ReplayRound"
+
+	fail := func(err error) (*entity.RoundReplay, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("round id", cmd.RoundID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	round, err := rc.roundRepo.GetRound(ctx, cmd.RoundID)
+	if err != nil {
+		log.Debug("couldn't get round", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = checkAdmin(ctx, rc.spaceRepo, round.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	snapshot, matched, err := rc.roundRepo.GetSnapshot(ctx, round.ID)
+	if err != nil {
+		log.Debug("couldn't get snapshot", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	plan, err := planRound(round.SpaceID, snapshot, round.Seed)
+	if err != nil {
+		log.Debug("couldn't match members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	replay := &entity.RoundReplay{
+		RoundID:         round.ID,
+		Seed:            round.Seed,
+		Strategy:        round.Strategy,
+		StrategyVersion: round.StrategyVersion,
+		CurrentVersion:  plan.round.StrategyVersion,
+		Snapshot:        snapshot,
+		Original:        matched,
+		Replayed:        make([][]int, 0, len(plan.meetings)),
+		Redacted:        snapshot.Redacted,
+	}
+	for _, meeting := range plan.meetings {
+		replay.Replayed = append(replay.Replayed, meeting.Participants)
+	}
+
+	replay.Missing, replay.Extra = entity.DiffGroups(replay.Original, replay.Replayed)
+	replay.Reproduced = len(replay.Missing) == 0 && len(replay.Extra) == 0
+
+	log.Info("round replayed", slog.Bool("reproduced", replay.Reproduced), slog.Bool("redacted", replay.Redacted),
+		slog.Int("missing", len(replay.Missing)), slog.Int("extra", len(replay.Extra)))
+
+	return replay, nil
+}
+
+// plan matches active members of the space into meetings with current space
+// settings around admin overrides of a preview, see planRound, with a new
+// random seed.
+func (rc *RoundUseCase) plan(ctx context.Context, spaceID int, overrides []entity.Override) (*roundPlan, error) {
+	snapshot, err := rc.snapshot(ctx, spaceID, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return planRound(spaceID, snapshot, rand.Int64N(entity.MaxSeed))
+}
+
+// snapshot collects everything a round of the space is matched from.
+func (rc *RoundUseCase) snapshot(ctx context.Context, spaceID int, overrides []entity.Override) (*entity.RoundSnapshot, error) {
+	settings, err := rc.spaceRepo.GetSettings(ctx, spaceID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := activeMembers(ctx, rc.spaceRepo, spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := membersByStatus(ctx, rc.spaceRepo, spaceID, entity.StatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	banned, err := membersByStatus(ctx, rc.spaceRepo, spaceID, entity.StatusBanned)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &entity.RoundSnapshot{
+		Settings:    *settings,
+		Members:     make([]entity.SnapshotMember, 0, len(members)),
+		Pending:     make([]int, 0, len(pending)),
+		Banned:      make([]int, 0, len(banned)),
+		Held:        [][]int{},
+		Mentorships: []*entity.Mentorship{},
+		Overrides:   overrides,
+		At:          time.Now(),
+	}
+	if snapshot.Overrides == nil {
+		snapshot.Overrides = []entity.Override{}
+	}
+
+	for _, member := range members {
+		snapshot.Members = append(snapshot.Members, entity.SnapshotMemberOf(member))
+	}
+	for _, member := range pending {
+		snapshot.Pending = append(snapshot.Pending, member.User.ID)
+	}
+	for _, member := range banned {
+		snapshot.Banned = append(snapshot.Banned, member.User.ID)
+	}
+
+	// mentors and mentees meet again and again, other modes avoid repeats
+	if settings.Mode == entity.MatchMentoring {
+		snapshot.Mentorships, err = rc.mentorshipRepo.GetMentorships(ctx, spaceID, false)
+	} else {
+		snapshot.Held, err = rc.roundRepo.HeldMeetings(ctx, spaceID, settings.RepeatWindow)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// planRound matches members of the snapshot into meetings, the same snapshot
+// and seed always give the same plan. Members who met in a held meeting
+// within the settings repeat window are not matched again, nor are members
+// with no common free time. In groups mode such repeats are only kept as few
+// as possible. Hard constraint rules of the settings are never broken, soft
+// ones as rarely as possible. In mentoring mode mentors meet their mentees
+// instead, see mentor, other modes match with the settings strategy around
+// admin overrides, see matchOverridden. Every meeting gets proposed slots
+// from participants' availability.
+func planRound(spaceID int, snapshot *entity.RoundSnapshot, seed int64) (*roundPlan, error) {
+	settings := snapshot.Settings
+	now := snapshot.At
+	mentoring := settings.Mode == entity.MatchMentoring
+	shuffle := rand.New(rand.NewPCG(uint64(seed), 0)).Shuffle
+
+	if mentoring && len(snapshot.Overrides) > 0 {
+		return nil, entity.ErrOverrideMode
+	}
+
+	members := make([]*entity.Member, 0, len(snapshot.Members))
+	availability := make(map[int]*entity.Availability, len(snapshot.Members))
+	for _, m := range snapshot.Members {
+		members = append(members, m.Member())
+		availability[m.UserID] = m.Availability
+	}
+
+	met := metPairs(snapshot.Held)
+	apart := make(map[pairKey]bool)
+	noOverlap := noOverlapPairs(availability, now, apart)
+	avoid := metPairs(snapshot.Held)
+	for key := range apart {
+		avoid[key] = true
+	}
+	rules := constrainedPairs(settings.Constraints, members)
+
+	plan := &roundPlan{ended: []int{}, started: []*entity.Mentorship{}, snapshot: snapshot}
+	plan.round = &entity.Round{
+		SpaceID:         spaceID,
+		SettingsVersion: settings.Version,
+		Seed:            seed,
+		NoOverlap:       noOverlap,
+		RematchPool:     []int{},
+		Snapshot:        snapshot,
+	}
+
+	var groups [][]int
+	if mentoring {
+		plan.round.Strategy, plan.round.StrategyVersion = string(entity.MatchMentoring), mentoringVersion
+		groups, plan.ended, plan.started = mentor(spaceID, settings.SpaceSettings, members, snapshot.Mentorships, avoid, rules, shuffle)
+	} else {
+		strategy, params, err := strategyOf(settings.SpaceSettings)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.round.Strategy, plan.round.StrategyVersion = strategy.Name(), strategy.Version()
+		input := MatchInput{Settings: settings.SpaceSettings, Members: members, Avoid: avoid, Apart: apart, Rules: rules, Shuffle: shuffle}
+		groups, err = matchOverridden(strategy, params, input, snapshot.Overrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan.round.Meetings = make([]*entity.Meeting, 0, len(groups))
+	plan.meetings = make([]*entity.PlannedMeeting, 0, len(groups))
+
+	matched := make(map[int]bool, len(members))
+	plan.metrics = entity.MatchMetrics{Members: len(members), Meetings: len(groups)}
+
+	for _, group := range groups {
+		meeting := &entity.Meeting{State: entity.MeetingProposed}
+		slots := make([]*entity.Availability, 0, len(group))
+		for i, userID := range group {
+			meeting.Participants = append(meeting.Participants, &entity.Participant{UserID: userID, Response: entity.ResponsePending})
+			slots = append(slots, availability[userID])
+			matched[userID] = true
+
+			for _, other := range group[i+1:] {
+				if met[pairOf(userID, other)] {
+					plan.metrics.Repeats++
+				}
+				if rules.penalty[pairOf(userID, other)] > 0 {
+					plan.metrics.SoftBroken++
+				}
+			}
+		}
+		meeting.ProposedSlots = entity.ProposeSlots(slots, now, entity.ProposedSlots)
+
+		if len(meeting.ProposedSlots) == 0 {
+			plan.metrics.WithoutSlots++
+		}
+
+		plan.round.Meetings = append(plan.round.Meetings, meeting)
+		plan.meetings = append(plan.meetings, &entity.PlannedMeeting{Participants: group, ProposedSlots: meeting.ProposedSlots})
+	}
+
+	plan.metrics.Matched = len(matched)
+	if len(members) > 0 {
+		plan.metrics.MatchRate = float64(len(matched)) / float64(len(members))
+	}
+	if len(groups) > 0 {
+		plan.metrics.AverageSize = float64(len(matched)) / float64(len(groups))
+	}
+
+	alone := make(map[int]bool, len(noOverlap))
+	for _, id := range noOverlap {
+		alone[id] = true
+	}
+
+	plan.unmatched = make([]entity.Exclusion, 0)
+	for _, id := range snapshot.Pending {
+		plan.unmatched = append(plan.unmatched, entity.Exclusion{UserID: id, Reason: entity.ExcludedPending})
+	}
+	for _, id := range snapshot.Banned {
+		plan.unmatched = append(plan.unmatched, entity.Exclusion{UserID: id, Reason: entity.ExcludedBanned})
+	}
+	for _, member := range members {
+		id := member.User.ID
+		switch {
+		case matched[id]:
+		case mentoring && member.MatchRole != settings.MentorRole && member.MatchRole != settings.MenteeRole:
+			plan.unmatched = append(plan.unmatched, entity.Exclusion{UserID: id, Reason: entity.ExcludedNoRole})
+		case alone[id]:
+			plan.unmatched = append(plan.unmatched, entity.Exclusion{UserID: id, Reason: entity.ExcludedNoOverlap})
+		default:
+			plan.unmatched = append(plan.unmatched, entity.Exclusion{UserID: id, Reason: entity.ExcludedNoPartner})
+		}
+	}
+
+	plan.round.Unmatched = unmatchedOf(plan.unmatched)
+
+	return plan, nil
+}
+
+// matchOverridden matches members with the strategy around overrides.
+// Members pinned together by pin and swap overrides meet on their own, pairs
+// of forbid overrides never meet. Overrides may break repeats and soft rules
+// but not hard ones.
+func matchOverridden(strategy MatchingStrategy, params any, input MatchInput, overrides []entity.Override) ([][]int, error) {
+	if len(overrides) == 0 {
+		return strategy.Match(input, params), nil
+	}
+
+	active := make(map[int]bool, len(input.Members))
+	for _, member := range input.Members {
+		active[member.User.ID] = true
+	}
+
+	forbid := input.Rules.with(nil)
+	for _, o := range overrides {
+		if !active[o.UserIDs[0]] || !active[o.UserIDs[1]] {
+			return nil, entity.ErrOverrideMember
+		}
+		if o.Kind == entity.OverrideForbid {
+			forbid[pairOf(o.UserIDs[0], o.UserIDs[1])] = true
+		}
+	}
+
+	_, _, maxSize := input.Settings.GroupBounds()
+	pinned := pinnedGroups(overrides)
+	taken := make(map[int]bool)
+	for _, group := range pinned {
+		if len(group) > maxSize {
+			return nil, entity.ErrOverrideConflict
+		}
+
+		for i, member := range group {
+			if !active[member] {
+				return nil, entity.ErrOverrideMember
+			}
+			for _, other := range group[i+1:] {
+				if forbid[pairOf(member, other)] {
+					return nil, entity.ErrOverrideConflict
+				}
+			}
+			taken[member] = true
+		}
+	}
+
+	rest := make([]*entity.Member, 0, len(input.Members))
+	for _, member := range input.Members {
+		if !taken[member.User.ID] {
+			rest = append(rest, member)
+		}
+	}
+
+	input.Members, input.Rules = rest, pairRules{forbid: forbid, penalty: input.Rules.penalty}
+
+	return append(pinned, strategy.Match(input, params)...), nil
+}
+
+// unmatchedOf returns members who could take part in the round but got no partner.
+func unmatchedOf(exclusions []entity.Exclusion) []int {
+	unmatched := make([]int, 0)
+	for _, e := range exclusions {
+		if e.Reason == entity.ExcludedNoOverlap || e.Reason == entity.ExcludedNoPartner {
+			unmatched = append(unmatched, e.UserID)
+		}
+	}
+	return unmatched
+}
+
+func roundCreated(log *slog.Logger, round *entity.Round) {
+	metrics.RoundsCreated.Inc()
+	metrics.MeetingTransitions.WithLabelValues(string(entity.MeetingProposed)).Add(float64(len(round.Meetings)))
+
+	log.Info("round created", slog.Int("round id", round.ID), slog.Int("meetings", len(round.Meetings)),
+		slog.Int("unmatched", len(round.Unmatched)), slog.Int("no overlap", len(round.NoOverlap)))
+}
+
+// mentor plans current mentorships of the space. Mentorships whose mentor
+// and mentee are still active members in their roles, within mentor's
+// capacity and allowed by hard constraint rules are kept, oldest first, the
+// others end. Mentees left without a mentor are assigned to mentors with
+// room: tags of each must satisfy pair tags of the other, the pair must not be
+// in avoid and hard constraint rules must allow it. Returns a group for every
+// mentorship meeting in the round, ended mentorships and started ones.
+func mentor(spaceID int, settings entity.SpaceSettings, members []*entity.Member, current []*entity.Mentorship, avoid map[pairKey]bool, rules pairRules, shuffle func(n int, swap func(i, j int))) ([][]int, []int, []*entity.Mentorship) {
+	byID := make(map[int]*entity.Member, len(members))
+	capacity := make(map[int]int)
+	mentors := make([]int, 0)
+	for _, member := range members {
+		byID[member.User.ID] = member
+
+		if member.MatchRole == settings.MentorRole {
+			mentors = append(mentors, member.User.ID)
+			capacity[member.User.ID] = settings.MentorCapacity
+			if member.Capacity > 0 {
+				capacity[member.User.ID] = member.Capacity
+			}
+		}
+	}
+
+	avoid = rules.with(avoid)
+
+	ended := make([]int, 0)
+	kept := make([]*entity.Mentorship, 0, len(current))
+	hasMentor := make(map[int]bool, len(current))
+	for _, m := range current {
+		mentor, mentee := byID[m.MentorID], byID[m.MenteeID]
+		if mentor == nil || mentee == nil || mentor.MatchRole != settings.MentorRole ||
+			mentee.MatchRole != settings.MenteeRole || capacity[m.MentorID] == 0 || rules.forbid[pairOf(m.MentorID, m.MenteeID)] {
+			ended = append(ended, m.ID)
+			continue
+		}
+
+		capacity[m.MentorID]--
+		hasMentor[m.MenteeID] = true
+		kept = append(kept, m)
+	}
+
+	mentees := make([]int, 0)
+	for _, member := range members {
+		if member.MatchRole == settings.MenteeRole && !hasMentor[member.User.ID] {
+			mentees = append(mentees, member.User.ID)
+		}
+	}
+
+	compatible := func(mentee, mentor int) bool {
+		return !avoid[pairOf(mentee, mentor)] &&
+			byID[mentor].UserTags.Satisfies(byID[mentee].PairTags) &&
+			byID[mentee].UserTags.Satisfies(byID[mentor].PairTags)
+	}
+
+	mentorOf, _ := assignMentees(mentees, mentors, capacity, compatible, rules.penalty, shuffle)
+
+	started := make([]*entity.Mentorship, 0, len(mentorOf))
+	for _, mentee := range mentees {
+		if mentor, ok := mentorOf[mentee]; ok {
+			started = append(started, &entity.Mentorship{SpaceID: spaceID, MentorID: mentor, MenteeID: mentee})
+		}
+	}
+
+	groups := make([][]int, 0, len(kept)+len(started))
+	for _, m := range append(kept, started...) {
+		groups = append(groups, []int{m.MentorID, m.MenteeID})
+	}
+
+	return groups, ended, started
+}
+
+// activeMembers returns all active members of the space.
+func activeMembers(ctx context.Context, spaceRepo ISpaceRepository, spaceID int) ([]*entity.Member, error) {
+	return membersByStatus(ctx, spaceRepo, spaceID, entity.StatusActive)
+}
+
+// membersByStatus returns all members of the space with the status.
+func membersByStatus(ctx context.Context, spaceRepo ISpaceRepository, spaceID int, status entity.MemberStatus) ([]*entity.Member, error) {
+	all := make([]*entity.Member, 0, maxMembersLimit)
+
+	for offset := 0; ; offset += maxMembersLimit {
+		members, _, err := spaceRepo.GetMembers(ctx, spaceID, status, maxMembersLimit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, members...)
+
+		if len(members) < maxMembersLimit {
+			return all, nil
+		}
+	}
+}
@@ -5,26 +5,56 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/Slava02/Involvio/internal/errs"
 	"github.com/Slava02/Involvio/internal/repository"
 	"github.com/Slava02/Involvio/internal/usecase/commands"
 	"github.com/Slava02/Involvio/pkg/hexid"
+	"github.com/Slava02/Involvio/pkg/metrics"
+	"io"
 	"log/slog"
+	"sort"
+)
+
+var (
+	ErrNotSpaceAdmin    = errs.New(errs.Forbidden, "space.not_admin", "user is not space admin")
+	ErrMemberBanned     = errs.New(errs.Forbidden, "membership.banned", "user is banned in space")
+	ErrMemberNotPending = errs.New(errs.Conflict, "membership.not_pending", "membership is not pending")
+	ErrCreatorImmutable = errs.New(errs.Forbidden, "membership.creator_immutable", "space creator can't be kicked or banned")
+	ErrSelfModeration   = errs.New(errs.Forbidden, "membership.self_moderation", "admin can't moderate own membership")
+)
+
+const (
+	defaultMembersLimit = 20
+	maxMembersLimit     = 100
 )
 
 type ISpaceRepository interface {
 	GetSpace(ctx context.Context, id int) (*entity.Space, error)
-	UpdateSpace(ctx context.Context, id int, name, description string) error
+	UpdateSpace(ctx context.Context, id int, name, description string, tags entity.Tags, version int) error
 	DeleteSpace(ctx context.Context, id int) error
-	InsertSpace(ctx context.Context, userId int, space *entity.Space) error
-	AddUser(ctx context.Context, userId, spaceId int) error
+	InsertSpace(ctx context.Context, userId int, space *entity.Space, settings entity.SpaceSettings) error
+	AddUser(ctx context.Context, userId, spaceId int, status entity.MemberStatus) error
+	GetMembers(ctx context.Context, spaceId int, status entity.MemberStatus, limit, offset int) ([]*entity.Member, int, error)
+	GetMember(ctx context.Context, spaceId, userId int) (*entity.Member, error)
+	SetMemberStatus(ctx context.Context, spaceId, userId int, status entity.MemberStatus, reason string) error
+	SetMatchRole(ctx context.Context, spaceId, userId int, role string, capacity int) error
+	RemoveUser(ctx context.Context, spaceId, userId int) error
+	RestoreSpace(ctx context.Context, id int) error
+	RestoreMember(ctx context.Context, spaceId, userId int) error
+	GetDeletedSpaceMember(ctx context.Context, spaceId, userId int) (*entity.Member, error)
+	ExistingUsers(ctx context.Context, ids []int) (map[int]bool, error)
+	ImportMembers(ctx context.Context, spaceId int, users []*entity.User, members []*entity.Member) error
+	GetSettings(ctx context.Context, spaceId, version int) (*entity.SpaceSettingsVersion, error)
+	InsertSettings(ctx context.Context, spaceId int, settings entity.SpaceSettings, version int) error
 }
 
-func NewSpaceUseCase(ur ISpaceRepository) *SpaceUseCase {
-	return &SpaceUseCase{spaceRepo: ur}
+func NewSpaceUseCase(ur ISpaceRepository, rm *Rematcher) *SpaceUseCase {
+	return &SpaceUseCase{spaceRepo: ur, rematcher: rm}
 }
 
 type SpaceUseCase struct {
 	spaceRepo ISpaceRepository
+	rematcher *Rematcher
 }
 
 func (sc *SpaceUseCase) UpdateSpace(ctx context.Context, cmd commands.UpdateSpaceCommand) (*entity.Space, error) {
@@ -39,22 +69,70 @@ func (sc *SpaceUseCase) UpdateSpace(ctx context.Context, cmd commands.UpdateSpac
 	)
 	log.Debug(op)
 
-	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.ID})
+	space, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.ID})
 	if err != nil {
-		log.Debug("couldn't get space: ", err.Error())
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	err = sc.spaceRepo.UpdateSpace(ctx, cmd.ID, cmd.Name, cmd.Description)
+	tags := cmd.Tags
+	if tags == nil {
+		tags = space.Tags
+	}
+
+	err = sc.spaceRepo.UpdateSpace(ctx, cmd.ID, cmd.Name, cmd.Description, tags, cmd.Version)
 	if err != nil {
+		log.Debug("couldn't update space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	return &entity.Space{
-		ID:          cmd.ID,
-		Name:        cmd.Name,
-		Description: cmd.Description,
-	}, nil
+	return sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.ID})
+}
+
+// PatchSpace applies merge patch to name, description and tags.
+func (sc *SpaceUseCase) PatchSpace(ctx context.Context, cmd commands.PatchSpaceCommand) (*entity.Space, error) {
+	const op = "Usecase:PatchSpace"
+
+	fail := func(err error) (*entity.Space, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.ID),
+	)
+	log.Debug(op)
+
+	err := retryUpdate(cmd.Version, func() error {
+		space, err := sc.spaceRepo.GetSpace(ctx, cmd.ID)
+		if err != nil {
+			log.Debug("couldn't get space", slog.String("error", err.Error()))
+			return err
+		}
+
+		version := cmd.Version
+		if version == 0 {
+			version = space.Version
+		}
+
+		doc, err := applyPatch(spaceDoc{Name: space.Name, Description: space.Description, Tags: space.Tags}, cmd.Patch, "tags")
+		if err != nil {
+			log.Debug("couldn't apply patch", slog.String("error", err.Error()))
+			return err
+		}
+
+		if doc.Name == "" {
+			return ErrRequiredField
+		}
+
+		return sc.spaceRepo.UpdateSpace(ctx, cmd.ID, doc.Name, doc.Description, doc.Tags, version)
+	})
+	if err != nil {
+		log.Debug("couldn't patch space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.ID})
 }
 
 func (sc *SpaceUseCase) DeleteSpace(ctx context.Context, cmd commands.SpaceByIdCommand) error {
@@ -71,13 +149,13 @@ func (sc *SpaceUseCase) DeleteSpace(ctx context.Context, cmd commands.SpaceByIdC
 
 	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.ID})
 	if err != nil {
-		log.Debug("couldn't get space: ", err.Error())
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	err = sc.spaceRepo.DeleteSpace(ctx, cmd.ID)
 	if err != nil {
-		log.Debug("couldn't delete space: ", err.Error())
+		log.Debug("couldn't delete space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -96,25 +174,45 @@ func (sc *SpaceUseCase) CreateSpace(ctx context.Context, cmd commands.CreateSpac
 	)
 	log.Debug(op)
 
+	timezone := cmd.Timezone
+	if timezone == "" {
+		timezone = entity.DefaultTimezone
+	}
+
+	if _, err := entity.LoadLocation(timezone); err != nil {
+		log.Debug("invalid timezone", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
 	spaceId, err := hexid.Generate()
 	if err != nil {
-		log.Error("couldn't generate id: ", err.Error())
+		log.Error("couldn't generate id", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	space := &entity.Space{
-		ID:          spaceId,
-		Name:        cmd.Name,
-		Description: cmd.Description,
-		Tags:        cmd.Tags,
+		ID:           spaceId,
+		Name:         cmd.Name,
+		Description:  cmd.Description,
+		Timezone:     timezone,
+		JoinApproval: cmd.JoinApproval,
+		Tags:         cmd.Tags,
+	}
+
+	settings := entity.DefaultSpaceSettings()
+	settings.Timezone = timezone
+	if cmd.JoinApproval {
+		settings.JoinMode = entity.JoinApproval
 	}
 
-	err = sc.spaceRepo.InsertSpace(ctx, cmd.UserID, space)
+	err = sc.spaceRepo.InsertSpace(ctx, cmd.UserID, space, settings)
 	if err != nil {
-		log.Debug("couldn't insert space: ", err.Error())
+		log.Debug("couldn't insert space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
+	metrics.SpacesCreated.Inc()
+
 	return space, nil
 }
 
@@ -134,9 +232,9 @@ func (sc *SpaceUseCase) GetSpace(ctx context.Context, cmd commands.SpaceByIdComm
 	space, err := sc.spaceRepo.GetSpace(ctx, cmd.ID)
 	if err != nil {
 		if errors.Is(err, repository.ErrSpaceNotFound) {
-			log.Info("couldn't get event: ", err.Error())
+			log.Info("couldn't get event", slog.String("error", err.Error()))
 		} else {
-			log.Debug("couldn't get event: ", err.Error())
+			log.Debug("couldn't get event", slog.String("error", err.Error()))
 		}
 		return fail(err)
 	}
@@ -144,28 +242,612 @@ func (sc *SpaceUseCase) GetSpace(ctx context.Context, cmd commands.SpaceByIdComm
 	return space, nil
 }
 
-func (sc *SpaceUseCase) JoinSpace(ctx context.Context, cmd commands.JoinSpaceCommand) error {
+// JoinSpace adds user to space. In spaces with join approval the membership
+// stays pending until an admin approves it.
+func (sc *SpaceUseCase) JoinSpace(ctx context.Context, cmd commands.JoinSpaceCommand) (entity.MemberStatus, error) {
 	const op = "Usecase:JoinSpace"
 
+	fail := func(err error) (entity.MemberStatus, error) {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+	)
+	log.Debug(op)
+
+	space, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.SpaceID})
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	member, err := sc.spaceRepo.GetMember(ctx, cmd.SpaceID, cmd.UserID)
+	switch {
+	case err == nil && member.Status == entity.StatusBanned:
+		log.Info("banned user tried to join space")
+		return fail(ErrMemberBanned)
+	case err == nil:
+		return fail(repository.ErrMemberAlreadyExists)
+	case !errors.Is(err, repository.ErrMemberNotFound):
+		log.Debug("couldn't get member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	status := entity.StatusActive
+	if space.JoinApproval {
+		status = entity.StatusPending
+	}
+
+	err = sc.spaceRepo.AddUser(ctx, cmd.UserID, cmd.SpaceID, status)
+	if err != nil {
+		return fail(err)
+	}
+
+	metrics.SpaceJoins.WithLabelValues(string(status)).Inc()
+
+	return status, nil
+}
+
+func (sc *SpaceUseCase) GetMembers(ctx context.Context, cmd commands.MembersCommand) ([]*entity.Member, int, error) {
+	const op = "Usecase:GetMembers"
+
+	fail := func(err error) ([]*entity.Member, int, error) {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+	)
+	log.Debug(op)
+
+	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.SpaceID})
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	limit := cmd.Limit
+	if limit <= 0 {
+		limit = defaultMembersLimit
+	}
+	limit = min(limit, maxMembersLimit)
+
+	members, total, err := sc.spaceRepo.GetMembers(ctx, cmd.SpaceID, cmd.Status, limit, max(cmd.Offset, 0))
+	if err != nil {
+		log.Debug("couldn't get members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return members, total, nil
+}
+
+func (sc *SpaceUseCase) KickMember(ctx context.Context, cmd commands.MemberCommand) error {
+	const op = "Usecase:KickMember"
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("user id", cmd.UserID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	member, err := sc.moderatedMember(ctx, cmd)
+	if err != nil {
+		log.Debug("couldn't moderate member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if member.Role == entity.RoleCreator {
+		return fail(ErrCreatorImmutable)
+	}
+
+	err = sc.spaceRepo.RemoveUser(ctx, cmd.SpaceID, cmd.UserID)
+	if err != nil {
+		log.Debug("couldn't remove member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	// member is already out, partners left alone are a separate concern
+	if err = sc.rematcher.Withdrawn(ctx, cmd.SpaceID, cmd.UserID); err != nil {
+		log.Warn("couldn't rematch partners of member", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func (sc *SpaceUseCase) BanMember(ctx context.Context, cmd commands.BanMemberCommand) error {
+	const op = "Usecase:BanMember"
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("user id", cmd.UserID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	member, err := sc.moderatedMember(ctx, commands.MemberCommand{SpaceID: cmd.SpaceID, UserID: cmd.UserID, AdminID: cmd.AdminID})
+	if err != nil {
+		log.Debug("couldn't moderate member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if member.Role == entity.RoleCreator {
+		return fail(ErrCreatorImmutable)
+	}
+
+	err = sc.spaceRepo.SetMemberStatus(ctx, cmd.SpaceID, cmd.UserID, entity.StatusBanned, cmd.Reason)
+	if err != nil {
+		log.Debug("couldn't ban member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	// member is already out, partners left alone are a separate concern
+	if err = sc.rematcher.Withdrawn(ctx, cmd.SpaceID, cmd.UserID); err != nil {
+		log.Warn("couldn't rematch partners of member", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func (sc *SpaceUseCase) ApproveMember(ctx context.Context, cmd commands.MemberCommand) error {
+	const op = "Usecase:ApproveMember"
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("user id", cmd.UserID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	member, err := sc.moderatedMember(ctx, cmd)
+	if err != nil {
+		log.Debug("couldn't moderate member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if member.Status != entity.StatusPending {
+		return fail(ErrMemberNotPending)
+	}
+
+	err = sc.spaceRepo.SetMemberStatus(ctx, cmd.SpaceID, cmd.UserID, entity.StatusActive, "")
+	if err != nil {
+		log.Debug("couldn't approve member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// SetMatchRole sets the role the member is matched in by mentoring mode, one
+// of the space settings roles. Admins may set their own role too. Mentorships
+// the member no longer fits are ended by the next round.
+func (sc *SpaceUseCase) SetMatchRole(ctx context.Context, cmd commands.SetMatchRoleCommand) error {
+	const op = "Usecase:SetMatchRole"
+
+	fail := func(err error) error {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("user id", cmd.UserID),
+		slog.Int("admin id", cmd.AdminID),
+		slog.String("role", cmd.Role),
+	)
+	log.Debug(op)
+
+	if err := sc.checkAdmin(ctx, cmd.SpaceID, cmd.AdminID); err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if _, err := sc.spaceRepo.GetMember(ctx, cmd.SpaceID, cmd.UserID); err != nil {
+		log.Debug("couldn't get member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	settings, err := sc.spaceRepo.GetSettings(ctx, cmd.SpaceID, 0)
+	if err != nil {
+		log.Debug("couldn't get settings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	switch cmd.Role {
+	case "", settings.MentorRole, settings.MenteeRole:
+	default:
+		return fail(fmt.Errorf("%w: %q", entity.ErrInvalidRole, cmd.Role))
+	}
+
+	if cmd.Capacity != 0 && cmd.Role != settings.MentorRole {
+		return fail(fmt.Errorf("%w: only mentors have capacity", entity.ErrInvalidRole))
+	}
+
+	if cmd.Capacity < 0 || cmd.Capacity > entity.MaxCapacity {
+		return fail(fmt.Errorf("%w: capacity %d", entity.ErrInvalidRole, cmd.Capacity))
+	}
+
+	err = sc.spaceRepo.SetMatchRole(ctx, cmd.SpaceID, cmd.UserID, cmd.Role, cmd.Capacity)
+	if err != nil {
+		log.Debug("couldn't set match role", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// RestoreSpace brings back a deleted space with memberships and events deleted
+// together with it. Only admins of the space at the moment of deletion may do it.
+func (sc *SpaceUseCase) RestoreSpace(ctx context.Context, cmd commands.RestoreSpaceCommand) (*entity.Space, error) {
+	const op = "Usecase:RestoreSpace"
+
+	fail := func(err error) (*entity.Space, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.ID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	admin, err := sc.spaceRepo.GetDeletedSpaceMember(ctx, cmd.ID, cmd.AdminID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMemberNotFound) {
+			return fail(ErrNotSpaceAdmin)
+		}
+		log.Debug("couldn't get admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if !admin.IsAdmin() {
+		return fail(ErrNotSpaceAdmin)
+	}
+
+	err = sc.spaceRepo.RestoreSpace(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't restore space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	space, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.ID})
+	if err != nil {
+		return fail(err)
+	}
+
+	return space, nil
+}
+
+// RestoreMember brings back a membership removed by kick or by the user.
+func (sc *SpaceUseCase) RestoreMember(ctx context.Context, cmd commands.MemberCommand) error {
+	const op = "Usecase:RestoreMember"
+
 	fail := func(err error) error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	log := slog.With(
 		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("user id", cmd.UserID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	err := sc.checkAdmin(ctx, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = sc.spaceRepo.RestoreMember(ctx, cmd.SpaceID, cmd.UserID)
+	if err != nil {
+		log.Debug("couldn't restore member", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return nil
+}
+
+// GetSettings returns current settings of the space or, with a version, the
+// settings a round was run with.
+func (sc *SpaceUseCase) GetSettings(ctx context.Context, cmd commands.SettingsCommand) (*entity.SpaceSettingsVersion, error) {
+	const op = "Usecase:GetSettings"
+
+	fail := func(err error) (*entity.SpaceSettingsVersion, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+	)
+	log.Debug(op)
+
+	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.SpaceID})
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	settings, err := sc.spaceRepo.GetSettings(ctx, cmd.SpaceID, cmd.Version)
+	if err != nil {
+		log.Debug("couldn't get settings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings saves settings as a new version, previous versions are kept.
+func (sc *SpaceUseCase) UpdateSettings(ctx context.Context, cmd commands.UpdateSettingsCommand) (*entity.SpaceSettingsVersion, error) {
+	const op = "Usecase:UpdateSettings"
+
+	fail := func(err error) (*entity.SpaceSettingsVersion, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("admin id", cmd.AdminID),
 	)
 	log.Debug(op)
 
 	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.SpaceID})
 	if err != nil {
-		log.Debug("couldn't get space: ", err.Error())
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	err = sc.spaceRepo.AddUser(ctx, cmd.UserID, cmd.SpaceID)
+	err = sc.checkAdmin(ctx, cmd.SpaceID, cmd.AdminID)
 	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
+	settings := cmd.Settings
+	if settings.Timezone == "" {
+		settings.Timezone = entity.DefaultTimezone
+	}
+	if settings.Strategy == "" {
+		settings.Strategy = entity.DefaultStrategy
+	}
+	if settings.StrategyParams == nil {
+		settings.StrategyParams = map[string]any{}
+	}
+
+	if err = settings.Validate(); err != nil {
+		log.Debug("invalid settings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	if _, _, err = strategyOf(settings); err != nil {
+		log.Debug("invalid strategy", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = sc.spaceRepo.InsertSettings(ctx, cmd.SpaceID, settings, cmd.Version)
+	if err != nil {
+		log.Debug("couldn't save settings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return sc.spaceRepo.GetSettings(ctx, cmd.SpaceID, 0)
+}
+
+// ImportMembers validates members CSV and, unless it is a dry run, adds all
+// rows as active members in one transaction. Rows are written only if every
+// row is valid, the report lists the errors otherwise.
+func (sc *SpaceUseCase) ImportMembers(ctx context.Context, cmd commands.ImportMembersCommand) (*entity.MemberImport, error) {
+	const op = "Usecase:ImportMembers"
+
+	fail := func(err error) (*entity.MemberImport, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("admin id", cmd.AdminID),
+		slog.Bool("dry run", cmd.DryRun),
+	)
+	log.Debug(op)
+
+	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.SpaceID})
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = sc.checkAdmin(ctx, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	rows, report, err := parseMembersCSV(cmd.CSV, cmd.Columns, cmd.TagColumns)
+	if err != nil {
+		log.Debug("couldn't parse CSV", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	report.DryRun = cmd.DryRun
+
+	ids := make([]int, 0, len(rows))
+	for _, row := range rows {
+		if row.user.ID != 0 {
+			ids = append(ids, row.user.ID)
+		}
+	}
+
+	existing, err := sc.spaceRepo.ExistingUsers(ctx, ids)
+	if err != nil {
+		log.Debug("couldn't get users", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	users := make([]*entity.User, 0)
+	members := make([]*entity.Member, 0, len(rows))
+
+	for _, row := range rows {
+		if row.user.ID == 0 {
+			users = append(users, row.user)
+			members = append(members, &entity.Member{User: row.user, UserTags: row.tags})
+			continue
+		}
+
+		rowErr := func(message string) {
+			report.Errors = append(report.Errors, entity.ImportError{Row: row.row, Message: message})
+		}
+
+		if !existing[row.user.ID] {
+			rowErr("user not found")
+			continue
+		}
+
+		member, err := sc.spaceRepo.GetMember(ctx, cmd.SpaceID, row.user.ID)
+		switch {
+		case err == nil && member.Status == entity.StatusBanned:
+			rowErr("user is banned in space")
+			continue
+		case err == nil:
+			rowErr("user is already a member of space")
+			continue
+		case !errors.Is(err, repository.ErrMemberNotFound):
+			log.Debug("couldn't get member", slog.String("error", err.Error()))
+			return fail(err)
+		}
+
+		members = append(members, &entity.Member{User: row.user, UserTags: row.tags})
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	report.Created, report.Joined = len(users), len(members)
+
+	if cmd.DryRun || len(report.Errors) > 0 || len(members) == 0 {
+		return report, nil
+	}
+
+	for _, user := range users {
+		user.ID, err = hexid.Generate()
+		if err != nil {
+			log.Error("couldn't generate id", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
+	err = sc.spaceRepo.ImportMembers(ctx, cmd.SpaceID, users, members)
+	if err != nil {
+		log.Debug("couldn't import members", slog.String("error", err.Error()))
+		return fail(err)
+	}
+	report.Committed = true
+
+	log.Info("members imported", slog.Int("created", report.Created), slog.Int("joined", report.Joined))
+
+	return report, nil
+}
+
+// ExportMembers checks that admin may read members of the space and returns a
+// function writing them as CSV, so the caller can stream it.
+func (sc *SpaceUseCase) ExportMembers(ctx context.Context, cmd commands.ExportMembersCommand) (func(w io.Writer) error, error) {
+	const op = "Usecase:ExportMembers"
+
+	fail := func(err error) (func(w io.Writer) error, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("space id", cmd.SpaceID),
+		slog.Int("admin id", cmd.AdminID),
+	)
+	log.Debug(op)
+
+	_, err := sc.GetSpace(ctx, commands.SpaceByIdCommand{ID: cmd.SpaceID})
+	if err != nil {
+		log.Debug("couldn't get space", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	err = sc.checkAdmin(ctx, cmd.SpaceID, cmd.AdminID)
+	if err != nil {
+		log.Debug("couldn't check admin", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return func(w io.Writer) error {
+		out := newMembersCSVWriter(w, cmd.TagColumns)
+		if err := out.header(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for offset := 0; ; offset += maxMembersLimit {
+			members, _, err := sc.spaceRepo.GetMembers(ctx, cmd.SpaceID, cmd.Status, maxMembersLimit, offset)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			if err = out.write(members); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			if len(members) < maxMembersLimit {
+				return nil
+			}
+		}
+	}, nil
+}
+
+// checkAdmin returns ErrNotSpaceAdmin unless user is an active admin of the space.
+func (sc *SpaceUseCase) checkAdmin(ctx context.Context, spaceID, userID int) error {
+	return checkAdmin(ctx, sc.spaceRepo, spaceID, userID)
+}
+
+func checkAdmin(ctx context.Context, spaceRepo ISpaceRepository, spaceID, userID int) error {
+	admin, err := spaceRepo.GetMember(ctx, spaceID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMemberNotFound) {
+			return ErrNotSpaceAdmin
+		}
+		return err
+	}
+
+	if !admin.IsAdmin() {
+		return ErrNotSpaceAdmin
+	}
+
 	return nil
 }
+
+// moderatedMember checks that the admin may moderate the space and returns the
+// member they are going to act on.
+func (sc *SpaceUseCase) moderatedMember(ctx context.Context, cmd commands.MemberCommand) (*entity.Member, error) {
+	if cmd.AdminID == cmd.UserID {
+		return nil, ErrSelfModeration
+	}
+
+	if err := sc.checkAdmin(ctx, cmd.SpaceID, cmd.AdminID); err != nil {
+		return nil, err
+	}
+
+	return sc.spaceRepo.GetMember(ctx, cmd.SpaceID, cmd.UserID)
+}
@@ -0,0 +1,328 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/danielgtaylor/huma/v2"
+	"reflect"
+	"sort"
+)
+
+// MatchingStrategy matches members of a round into meetings in pairs and
+// groups modes, mentoring mode always keeps mentorships. Whatever it
+// optimizes, a strategy keeps hard constraints: pairs in MatchInput.Rules.forbid
+// never meet, nobody is in two meetings and every meeting is within
+// GroupBounds of the settings. Members it leaves out are unmatched.
+type MatchingStrategy interface {
+	// Name is the key of the strategy in the registry and in space settings.
+	Name() string
+	// Version changes whenever the strategy may match the same input differently.
+	Version() int
+	// Params returns a pointer to default params of the strategy, nil if it
+	// takes none. Their JSON schema is derived from the type.
+	Params() any
+	// Match matches members with params decoded into the type of Params.
+	Match(input MatchInput, params any) [][]int
+}
+
+// mentoringVersion is the version of matching in mentoring mode, see mentor.
+const mentoringVersion = 1
+
+// MatchInput is what a strategy matches.
+type MatchInput struct {
+	Settings entity.SpaceSettings
+	Members  []*entity.Member
+	// Avoid are pairs who met within the repeat window of the settings and
+	// pairs of Apart.
+	Avoid map[pairKey]bool
+	// Apart are pairs whose availability has no common time.
+	Apart map[pairKey]bool
+	// Rules are pairs constraint rules and admin overrides keep apart.
+	Rules   pairRules
+	Shuffle func(n int, swap func(i, j int))
+}
+
+func (in MatchInput) ids() []int {
+	ids := make([]int, 0, len(in.Members))
+	for _, member := range in.Members {
+		ids = append(ids, member.User.ID)
+	}
+	return ids
+}
+
+// strategies is the registry of matching strategies keyed by name.
+var strategies = map[string]MatchingStrategy{}
+
+// RegisterStrategy adds the strategy to the registry, names are unique.
+func RegisterStrategy(s MatchingStrategy) {
+	if _, ok := strategies[s.Name()]; ok {
+		panic(fmt.Sprintf("matching strategy %q is registered twice", s.Name()))
+	}
+	strategies[s.Name()] = s
+}
+
+// Strategies returns names of registered strategies in alphabetical order.
+func Strategies() []string {
+	all := make([]string, 0, len(strategies))
+	for name := range strategies {
+		all = append(all, name)
+	}
+	sort.Strings(all)
+	return all
+}
+
+func init() {
+	RegisterStrategy(defaultStrategy{})
+	RegisterStrategy(randomStrategy{})
+	RegisterStrategy(tagWeightedStrategy{})
+	RegisterStrategy(diversityStrategy{})
+	RegisterStrategy(mentorStrategy{})
+}
+
+// strategyOf returns the strategy of the settings with its params decoded
+// onto defaults. Unknown strategies and params not matching the strategy
+// schema are ErrInvalidSettings.
+func strategyOf(settings entity.SpaceSettings) (MatchingStrategy, any, error) {
+	strategy, ok := strategies[settings.Strategy]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: strategy %q", entity.ErrInvalidSettings, settings.Strategy)
+	}
+
+	params := strategy.Params()
+	if params == nil {
+		if len(settings.StrategyParams) > 0 {
+			return nil, nil, fmt.Errorf("%w: strategy %q takes no params", entity.ErrInvalidSettings, settings.Strategy)
+		}
+		return strategy, nil, nil
+	}
+
+	raw := settings.StrategyParams
+	if raw == nil {
+		raw = map[string]any{}
+	}
+
+	// params of stored settings come from JSON, the rest is brought to the same shape
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: strategy_params: %v", entity.ErrInvalidSettings, err)
+	}
+
+	var value any
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, nil, fmt.Errorf("%w: strategy_params: %v", entity.ErrInvalidSettings, err)
+	}
+
+	registry := huma.NewMapRegistry("#/components/schemas/", huma.DefaultSchemaNamer)
+	schema := huma.SchemaFromType(registry, reflect.TypeOf(params))
+
+	res := &huma.ValidateResult{}
+	huma.Validate(registry, schema, huma.NewPathBuffer([]byte("strategy_params"), 0), huma.ModeWriteToServer, value, res)
+	if len(res.Errors) > 0 {
+		return nil, nil, fmt.Errorf("%w: %v", entity.ErrInvalidSettings, res.Errors[0])
+	}
+
+	if err = json.Unmarshal(data, params); err != nil {
+		return nil, nil, fmt.Errorf("%w: strategy_params: %v", entity.ErrInvalidSettings, err)
+	}
+
+	return strategy, params, nil
+}
+
+// defaultStrategy matches as the settings mode says avoiding repeats and
+// breaking soft constraint rules as rarely as possible.
+type defaultStrategy struct{}
+
+func (defaultStrategy) Name() string { return entity.DefaultStrategy }
+func (defaultStrategy) Version() int { return 1 }
+func (defaultStrategy) Params() any  { return nil }
+
+func (defaultStrategy) Match(in MatchInput, _ any) [][]int {
+	groups, _ := matchMembers(in.Settings, in.ids(), in.Avoid, in.Rules, in.Shuffle)
+	return groups
+}
+
+// randomStrategy matches members at random. It keeps hard constraint rules
+// and overrides, and pairs without common time apart as the default strategy
+// does, repeats and soft constraint rules are ignored.
+type randomStrategy struct{}
+
+func (randomStrategy) Name() string { return "random" }
+func (randomStrategy) Version() int { return 1 }
+func (randomStrategy) Params() any  { return nil }
+
+func (randomStrategy) Match(in MatchInput, _ any) [][]int {
+	groups, _ := matchMembers(in.Settings, in.ids(), in.Apart, pairRules{forbid: in.Rules.forbid}, in.Shuffle)
+	return groups
+}
+
+type tagWeight struct {
+	Tag    string `doc:"User tag to compare" json:"tag" minLength:"1" maxLength:"100" example:"city"`
+	Weight int    `doc:"How much a pair not as preferred costs" json:"weight" minimum:"1" maximum:"10" example:"3"`
+	Prefer string `doc:"Whether members with the same or different values of the tag are a better match" json:"prefer" enum:"same,different" example:"same"`
+}
+
+type tagWeightedParams struct {
+	Tags []tagWeight `doc:"Weighted tags, pairs breaking preferences of more weight meet more rarely" json:"tags" required:"false" maxItems:"20"`
+}
+
+// tagWeightedStrategy matches as the default strategy and also prefers
+// pairs with the same or different values of weighted tags.
+type tagWeightedStrategy struct{}
+
+func (tagWeightedStrategy) Name() string { return "tag_weighted" }
+func (tagWeightedStrategy) Version() int { return 1 }
+func (tagWeightedStrategy) Params() any  { return &tagWeightedParams{} }
+
+func (tagWeightedStrategy) Match(in MatchInput, params any) [][]int {
+	p := params.(*tagWeightedParams)
+
+	penalty := weighPairs(in, func(a, b *entity.Member) int {
+		n := 0
+		for _, w := range p.Tags {
+			x, okA := a.UserTags.Get(w.Tag)
+			y, okB := b.UserTags.Get(w.Tag)
+			if !okA || !okB {
+				continue
+			}
+
+			if equal := reflect.DeepEqual(x, y); equal != (w.Prefer == "same") {
+				n += w.Weight
+			}
+		}
+		return n
+	})
+
+	groups, _ := matchMembers(in.Settings, in.ids(), in.Avoid, pairRules{forbid: in.Rules.forbid, penalty: penalty}, in.Shuffle)
+	return groups
+}
+
+type diversityParams struct {
+	Tags []string `doc:"User tags to diversify meetings by, all tags of members if empty" json:"tags" required:"false" maxItems:"20"`
+}
+
+// diversityStrategy matches as the default strategy and also keeps members
+// with equal tag values apart, every shared value counts.
+type diversityStrategy struct{}
+
+func (diversityStrategy) Name() string { return "diversity" }
+func (diversityStrategy) Version() int { return 1 }
+func (diversityStrategy) Params() any  { return &diversityParams{} }
+
+func (diversityStrategy) Match(in MatchInput, params any) [][]int {
+	p := params.(*diversityParams)
+
+	penalty := weighPairs(in, func(a, b *entity.Member) int {
+		tags := p.Tags
+		if len(tags) == 0 {
+			tags = tagNames(a.UserTags)
+		}
+
+		n := 0
+		for _, tag := range tags {
+			if (entity.ConstraintRule{Kind: entity.ConstraintPreferDifferent, Tag: tag}).Breaks(a, b) {
+				n++
+			}
+		}
+		return n
+	})
+
+	groups, _ := matchMembers(in.Settings, in.ids(), in.Avoid, pairRules{forbid: in.Rules.forbid, penalty: penalty}, in.Shuffle)
+	return groups
+}
+
+type mentorParams struct {
+	Capacity int `doc:"Mentees a mentor meets in one meeting, bounded by the largest meeting and by member capacity" json:"capacity" required:"false" minimum:"1" maximum:"11" example:"1"`
+}
+
+// mentorStrategy matches mentors with mentees of the settings roles anew
+// every round, nothing is kept between rounds unlike mentoring mode. Mentees
+// never meet mentors they have no common time with, and tags of each must
+// satisfy pair tags of the other as in mentoring mode. Members without a role,
+// mentees whom hard rules keep apart from other mentees of their mentor and
+// meetings below the smallest size are unmatched.
+type mentorStrategy struct{}
+
+func (mentorStrategy) Name() string { return "mentor_bipartite" }
+func (mentorStrategy) Version() int { return 1 }
+func (mentorStrategy) Params() any  { return &mentorParams{Capacity: 1} }
+
+func (mentorStrategy) Match(in MatchInput, params any) [][]int {
+	p := params.(*mentorParams)
+	_, minSize, maxSize := in.Settings.GroupBounds()
+
+	byID := make(map[int]*entity.Member, len(in.Members))
+	mentors, mentees := make([]int, 0), make([]int, 0)
+	capacity := make(map[int]int)
+	for _, member := range in.Members {
+		byID[member.User.ID] = member
+
+		switch member.MatchRole {
+		case in.Settings.MentorRole:
+			c := min(p.Capacity, maxSize-1)
+			if member.Capacity > 0 {
+				c = min(c, member.Capacity)
+			}
+			mentors = append(mentors, member.User.ID)
+			capacity[member.User.ID] = c
+		case in.Settings.MenteeRole:
+			mentees = append(mentees, member.User.ID)
+		}
+	}
+
+	compatible := func(mentee, mentor int) bool {
+		return !in.Rules.forbid[pairOf(mentee, mentor)] && !in.Apart[pairOf(mentee, mentor)] &&
+			byID[mentor].UserTags.Satisfies(byID[mentee].PairTags) &&
+			byID[mentee].UserTags.Satisfies(byID[mentor].PairTags)
+	}
+	penalty := in.Rules.costs(in.Avoid)
+
+	mentorOf, _ := assignMentees(mentees, mentors, capacity, compatible, penalty, in.Shuffle)
+
+	forbidden := in.Rules.costs(nil)
+	groups := make([][]int, 0, len(mentors))
+	for _, mentor := range mentors {
+		group := []int{mentor}
+		for _, mentee := range mentees {
+			if m, ok := mentorOf[mentee]; ok && m == mentor && cost(group, mentee, mentee, forbidden) < forbiddenCost {
+				group = append(group, mentee)
+			}
+		}
+
+		if len(group) >= minSize {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// weighPairs adds weight of every pair of members to soft constraint rules penalty.
+func weighPairs(in MatchInput, weight func(a, b *entity.Member) int) map[pairKey]int {
+	penalty := make(map[pairKey]int, len(in.Rules.penalty))
+	for key, n := range in.Rules.penalty {
+		penalty[key] = n
+	}
+
+	for i, a := range in.Members {
+		for _, b := range in.Members[i+1:] {
+			if n := weight(a, b); n > 0 {
+				penalty[pairOf(a.User.ID, b.User.ID)] += n
+			}
+		}
+	}
+
+	return penalty
+}
+
+// tagNames returns names of tags in order.
+func tagNames(tags entity.Tags) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		for name := range tag {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/Slava02/Involvio/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStrategies checks every registered strategy keeps hard constraints and,
+// outside groups mode, pairs without common time apart.
+func TestStrategies(t *testing.T) {
+	departments := []string{"sales", "it", "board", "hr"}
+	cities := []string{"Moscow", "Kazan", "Perm"}
+
+	members := make([]*entity.Member, 0, 40)
+	byID := make(map[int]*entity.Member, 40)
+	for id := 1; id <= 40; id++ {
+		role := "mentee"
+		if id%4 == 0 {
+			role = "mentor"
+		}
+		member := &entity.Member{
+			User:      &entity.User{ID: id},
+			UserTags:  entity.Tags{{"department": departments[id%len(departments)]}, {"city": cities[id%len(cities)]}},
+			MatchRole: role,
+		}
+		// some mentees look for a mentor from Kazan, a mentor only for mentees from Perm
+		if role == "mentee" && id%5 == 1 {
+			member.PairTags = entity.Tags{{"city": "Kazan"}}
+		}
+		if id == 8 {
+			member.PairTags = entity.Tags{{"city": "Perm"}}
+		}
+		members = append(members, member)
+		byID[id] = member
+	}
+
+	rules := constrainedPairs([]entity.ConstraintRule{
+		{Kind: entity.ConstraintNeverEqual, Tag: "department"},
+		{Kind: entity.ConstraintPreferDifferent, Tag: "city"},
+	}, members)
+	avoid := metPairs([][]int{{1, 2}, {3, 4, 5}, {6, 7}})
+	apart := metPairs([][]int{{8, 9}, {10, 11, 12}})
+	for key := range apart {
+		avoid[key] = true
+	}
+
+	pairs, trios, groups := entity.DefaultSpaceSettings(), entity.DefaultSpaceSettings(), entity.DefaultSpaceSettings()
+	trios.GroupSize = 3
+	groups.Mode, groups.GroupSize, groups.GroupMin, groups.GroupMax = entity.MatchGroups, 4, 3, 5
+
+	params := map[string]map[string]any{
+		"tag_weighted":     {"tags": []any{map[string]any{"tag": "city", "weight": 3, "prefer": "same"}}},
+		"diversity":        {"tags": []any{"city"}},
+		"mentor_bipartite": {"capacity": 2},
+	}
+
+	for _, name := range Strategies() {
+		for mode, settings := range map[string]entity.SpaceSettings{"pairs": pairs, "trios": trios, "groups": groups} {
+			t.Run(fmt.Sprintf("%s in %s", name, mode), func(t *testing.T) {
+				settings.Strategy, settings.StrategyParams = name, params[name]
+
+				strategy, p, err := strategyOf(settings)
+				assert.NoError(t, err)
+
+				shuffle := rand.New(rand.NewPCG(1, 2)).Shuffle
+				matched := strategy.Match(MatchInput{Settings: settings, Members: members, Avoid: avoid, Apart: apart, Rules: rules, Shuffle: shuffle}, p)
+				assert.NotEmpty(t, matched)
+
+				_, minSize, maxSize := settings.GroupBounds()
+				seen := make(map[int]bool)
+				for _, group := range matched {
+					assert.GreaterOrEqual(t, len(group), minSize)
+					assert.LessOrEqual(t, len(group), maxSize)
+
+					for i, member := range group {
+						assert.False(t, seen[member], "member %d is in two meetings", member)
+						assert.True(t, member >= 1 && member <= len(members), "member %d wasn't matched from", member)
+						seen[member] = true
+
+						for _, other := range group[i+1:] {
+							assert.False(t, rules.forbid[pairOf(member, other)], "forbidden pair %d and %d meet", member, other)
+							if settings.Mode != entity.MatchGroups {
+								assert.False(t, apart[pairOf(member, other)], "pair %d and %d without common time meet", member, other)
+							}
+							if name == "mentor_bipartite" && byID[member].MatchRole != byID[other].MatchRole {
+								assert.True(t, byID[member].UserTags.Satisfies(byID[other].PairTags) && byID[other].UserTags.Satisfies(byID[member].PairTags),
+									"mentor and mentee %d and %d don't satisfy pair tags of each other", member, other)
+							}
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestStrategyOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		params   map[string]any
+		wantErr  bool
+	}{
+		{"default", entity.DefaultStrategy, nil, false},
+		{"unknown strategy", "genetic", nil, true},
+		{"params of strategy without them", "random", map[string]any{"seed": 1}, true},
+		{"params by schema", "tag_weighted", map[string]any{"tags": []any{map[string]any{"tag": "city", "weight": 2, "prefer": "different"}}}, false},
+		{"weight out of range", "tag_weighted", map[string]any{"tags": []any{map[string]any{"tag": "city", "weight": 11, "prefer": "same"}}}, true},
+		{"unknown param", "diversity", map[string]any{"tag": "city"}, true},
+		{"default params", "mentor_bipartite", map[string]any{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := entity.DefaultSpaceSettings()
+			settings.Strategy, settings.StrategyParams = tt.strategy, tt.params
+
+			_, _, err := strategyOf(settings)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, entity.ErrInvalidSettings)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	_, p, err := strategyOf(entity.DefaultSpaceSettings())
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	settings := entity.DefaultSpaceSettings()
+	settings.Strategy = "mentor_bipartite"
+	_, p, err = strategyOf(settings)
+	assert.NoError(t, err)
+	assert.Equal(t, &mentorParams{Capacity: 1}, p)
+}
+
+// TestPlanRoundReplay checks a stored snapshot and seed match the round again.
+func TestPlanRoundReplay(t *testing.T) {
+	settings := entity.DefaultSpaceSettings()
+	settings.Strategy = "diversity"
+	settings.Constraints = []entity.ConstraintRule{{Kind: entity.ConstraintNeverEqual, Tag: "team"}}
+
+	snapshot := &entity.RoundSnapshot{
+		Settings:  entity.SpaceSettingsVersion{SpaceID: 1, Version: 2, SpaceSettings: settings},
+		Held:      [][]int{{1, 2}},
+		Overrides: []entity.Override{{Kind: entity.OverridePin, UserIDs: []int{3, 4}}},
+		At:        time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}
+	for id := 1; id <= 21; id++ {
+		snapshot.Members = append(snapshot.Members, entity.SnapshotMember{UserID: id, UserTags: entity.Tags{{"team": float64(id % 5)}}})
+	}
+
+	plan, err := planRound(1, snapshot, 4242)
+	assert.NoError(t, err)
+	assert.Equal(t, "diversity", plan.round.Strategy)
+	assert.Equal(t, []int{3, 4}, plan.meetings[0].Participants)
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	stored := new(entity.RoundSnapshot)
+	assert.NoError(t, json.Unmarshal(data, stored))
+
+	for i := 0; i < 3; i++ {
+		replay, err := planRound(1, stored, 4242)
+		assert.NoError(t, err)
+		assert.Equal(t, plan.meetings, replay.meetings)
+	}
+
+	other, err := planRound(1, stored, 4243)
+	assert.NoError(t, err)
+	assert.NotEqual(t, plan.meetings, other.meetings)
+}
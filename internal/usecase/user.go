@@ -7,16 +7,21 @@ import (
 	"github.com/Slava02/Involvio/internal/usecase/commands"
 	"github.com/Slava02/Involvio/pkg/hexid"
 	"log/slog"
+	"time"
 )
 
 type IUserRepository interface {
 	GetUserData(ctx context.Context, id int) (*entity.User, error)
 	GetUserForms(ctx context.Context, userId int) ([]*entity.Form, error)
 	InsertUser(ctx context.Context, user *entity.User) error
-	UpdateUser(ctx context.Context, id int, firstName, lastName, userName, photoURL string) (*entity.User, error)
+	UpdateUser(ctx context.Context, id int, firstName, lastName, userName, photoURL string, version int) (*entity.User, error)
 	DeleteUser(ctx context.Context, userId, spaceId int) error
 	GetForm(ctx context.Context, userId, spaceId int) (*entity.Form, error)
-	UpdateForm(ctx context.Context, userId, spaceId int, userTags, pairTags entity.Tags) error
+	UpdateForm(ctx context.Context, userId, spaceId int, userTags, pairTags entity.Tags, availability *entity.Availability, version int) error
+	GetMemberships(ctx context.Context, userId int) ([]*entity.Membership, error)
+	GetParticipations(ctx context.Context, userId int) ([]*entity.Participation, error)
+	GetMeetings(ctx context.Context, userId int) ([]*entity.MeetingParticipation, error)
+	EraseUser(ctx context.Context, userId int) error
 }
 
 func NewUserUseCase(ur IUserRepository) *UserUseCase {
@@ -41,13 +46,13 @@ func (uc *UserUseCase) GetUser(ctx context.Context, cmd commands.UserByIdCommand
 
 	userData, err := uc.userRepo.GetUserData(ctx, cmd.ID)
 	if err != nil {
-		log.Debug("couldn't get user: ", err.Error())
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	userForms, err := uc.userRepo.GetUserForms(ctx, cmd.ID)
 	if err != nil {
-		log.Debug("couldn't get user: ", err.Error())
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -68,13 +73,13 @@ func (uc *UserUseCase) DeleteUser(ctx context.Context, cmd commands.FormByIdComm
 
 	_, _, err := uc.GetUser(ctx, commands.UserByIdCommand{ID: cmd.UserID})
 	if err != nil {
-		log.Debug("couldn't get user: ", err.Error())
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	err = uc.userRepo.DeleteUser(ctx, cmd.UserID, cmd.SpaceID)
 	if err != nil {
-		log.Debug("couldn't delete user: ", err.Error())
+		log.Debug("couldn't delete user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -113,20 +118,27 @@ func (uc *UserUseCase) UpdateForm(ctx context.Context, cmd commands.UpdateFormCo
 	)
 	log.Debug(op)
 
+	if cmd.Availability != nil {
+		if err := cmd.Availability.Validate(); err != nil {
+			log.Debug("invalid availability", slog.String("error", err.Error()))
+			return fail(err)
+		}
+	}
+
 	_, err := uc.GetForm(ctx, commands.FormByIdCommand{UserID: cmd.UserID, SpaceID: cmd.SpaceID})
 	if err != nil {
-		log.Debug("couldn't get form: ", err.Error())
+		log.Debug("couldn't get form", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	err = uc.userRepo.UpdateForm(ctx, cmd.UserID, cmd.SpaceID, cmd.UserTags, cmd.PairTags)
+	err = uc.userRepo.UpdateForm(ctx, cmd.UserID, cmd.SpaceID, cmd.UserTags, cmd.PairTags, cmd.Availability, cmd.Version)
 	if err != nil {
 		return fail(err)
 	}
 
 	user, forms, err := uc.GetUser(ctx, commands.UserByIdCommand{ID: cmd.UserID})
 	if err != nil {
-		log.Debug("couldn't get form: ", err.Error())
+		log.Debug("couldn't get form", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -147,7 +159,7 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, cmd commands.CreateUserCo
 
 	userId, err := hexid.Generate()
 	if err != nil {
-		log.Error("couldn't generate id: ", err.Error())
+		log.Error("couldn't generate id", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
@@ -182,15 +194,186 @@ func (uc *UserUseCase) UpdateUser(ctx context.Context, cmd commands.UpdateUserCo
 
 	_, _, err := uc.GetUser(ctx, commands.UserByIdCommand{ID: cmd.ID})
 	if err != nil {
-		log.Debug("couldn't get user: ", err.Error())
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	user, err := uc.userRepo.UpdateUser(ctx, cmd.ID, cmd.FirstName, cmd.LastName, cmd.UserName, cmd.PhotoURL, cmd.Version)
+	if err != nil {
+		log.Debug("couldn't update user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
-	user, err := uc.userRepo.UpdateUser(ctx, cmd.ID, cmd.FirstName, cmd.LastName, cmd.UserName, cmd.PhotoURL)
+	return user, nil
+}
+
+// PatchUser applies merge patch to user profile.
+func (uc *UserUseCase) PatchUser(ctx context.Context, cmd commands.PatchUserCommand) (*entity.User, error) {
+	const op = "Usecase:PatchUser"
+
+	fail := func(err error) (*entity.User, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", cmd.ID),
+	)
+	log.Debug(op)
+
+	var user *entity.User
+
+	err := retryUpdate(cmd.Version, func() error {
+		current, err := uc.userRepo.GetUserData(ctx, cmd.ID)
+		if err != nil {
+			log.Debug("couldn't get user", slog.String("error", err.Error()))
+			return err
+		}
+
+		version := cmd.Version
+		if version == 0 {
+			version = current.Version
+		}
+
+		doc, err := applyPatch(userDoc{
+			FirstName: current.FirstName,
+			LastName:  current.LastName,
+			UserName:  current.UserName,
+			PhotoURL:  current.PhotoURL,
+		}, cmd.Patch)
+		if err != nil {
+			log.Debug("couldn't apply patch", slog.String("error", err.Error()))
+			return err
+		}
+
+		if doc.FirstName == "" {
+			return ErrRequiredField
+		}
+
+		user, err = uc.userRepo.UpdateUser(ctx, cmd.ID, doc.FirstName, doc.LastName, doc.UserName, doc.PhotoURL, version)
+		return err
+	})
 	if err != nil {
-		log.Debug("couldn't update user: ", err.Error())
+		log.Debug("couldn't patch user", slog.String("error", err.Error()))
 		return fail(err)
 	}
 
 	return user, nil
 }
+
+// PatchForm applies merge patch to user form in space.
+func (uc *UserUseCase) PatchForm(ctx context.Context, cmd commands.PatchFormCommand) (*entity.User, []*entity.Form, error) {
+	const op = "Usecase:PatchForm"
+
+	fail := func(err error) (*entity.User, []*entity.Form, error) {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", cmd.UserID),
+		slog.Int("space id", cmd.SpaceID),
+	)
+	log.Debug(op)
+
+	err := retryUpdate(cmd.Version, func() error {
+		form, err := uc.userRepo.GetForm(ctx, cmd.UserID, cmd.SpaceID)
+		if err != nil {
+			log.Debug("couldn't get form", slog.String("error", err.Error()))
+			return err
+		}
+
+		version := cmd.Version
+		if version == 0 {
+			version = form.Version
+		}
+
+		doc, err := applyPatch(formDoc{UserTags: form.UserTags, PairTags: form.PairTags, Availability: form.Availability}, cmd.Patch, "user_tags", "pair_tags")
+		if err != nil {
+			log.Debug("couldn't apply patch", slog.String("error", err.Error()))
+			return err
+		}
+
+		if doc.Availability != nil {
+			if err = doc.Availability.Validate(); err != nil {
+				log.Debug("invalid availability", slog.String("error", err.Error()))
+				return err
+			}
+		}
+
+		return uc.userRepo.UpdateForm(ctx, cmd.UserID, cmd.SpaceID, doc.UserTags, doc.PairTags, doc.Availability, version)
+	})
+	if err != nil {
+		log.Debug("couldn't patch form", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return uc.GetUser(ctx, commands.UserByIdCommand{ID: cmd.UserID})
+}
+
+// ExportUser collects everything stored about the user.
+func (uc *UserUseCase) ExportUser(ctx context.Context, cmd commands.UserByIdCommand) (*entity.UserExport, error) {
+	const op = "Usecase:ExportUser"
+
+	fail := func(err error) (*entity.UserExport, error) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", cmd.ID),
+	)
+	log.Debug(op)
+
+	user, forms, err := uc.GetUser(ctx, cmd)
+	if err != nil {
+		log.Debug("couldn't get user", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	memberships, err := uc.userRepo.GetMemberships(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get memberships", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	participations, err := uc.userRepo.GetParticipations(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get participations", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	meetings, err := uc.userRepo.GetMeetings(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't get meetings", slog.String("error", err.Error()))
+		return fail(err)
+	}
+
+	return &entity.UserExport{
+		User:        user,
+		Memberships: memberships,
+		Forms:       forms,
+		Events:      participations,
+		Meetings:    meetings,
+		ExportedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// EraseUser permanently removes the user from the service.
+func (uc *UserUseCase) EraseUser(ctx context.Context, cmd commands.UserByIdCommand) error {
+	const op = "Usecase:EraseUser"
+
+	log := slog.With(
+		slog.String("op", op),
+		slog.Int("user id", cmd.ID),
+	)
+	log.Debug(op)
+
+	err := uc.userRepo.EraseUser(ctx, cmd.ID)
+	if err != nil {
+		log.Debug("couldn't erase user", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
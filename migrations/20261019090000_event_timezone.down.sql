@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE "space" DROP COLUMN IF EXISTS "timezone";
+
+ALTER TABLE "event" DROP COLUMN IF EXISTS "timezone";
+
+ALTER TABLE "event"
+    ALTER COLUMN "begin_date" TYPE timestamp USING "begin_date" AT TIME ZONE 'UTC',
+    ALTER COLUMN "end_date" TYPE timestamp USING "end_date" AT TIME ZONE 'UTC';
+
+COMMIT;
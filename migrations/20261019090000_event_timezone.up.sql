@@ -0,0 +1,13 @@
+BEGIN;
+
+-- begin_date/end_date were stored as "timestamp" and pgx wrote the client's wall
+-- clock into them without an offset. Existing values are assumed to be UTC.
+ALTER TABLE "event"
+    ALTER COLUMN "begin_date" TYPE timestamptz USING "begin_date" AT TIME ZONE 'UTC',
+    ALTER COLUMN "end_date" TYPE timestamptz USING "end_date" AT TIME ZONE 'UTC';
+
+ALTER TABLE "event" ADD COLUMN "timezone" varchar NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE "space" ADD COLUMN "timezone" varchar NOT NULL DEFAULT 'UTC';
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE "space" DROP COLUMN IF EXISTS "join_approval";
+
+DROP INDEX IF EXISTS "user_space_space_id_status_idx";
+
+ALTER TABLE "user_space" DROP COLUMN IF EXISTS "joined_at";
+ALTER TABLE "user_space" DROP COLUMN IF EXISTS "ban_reason";
+ALTER TABLE "user_space" DROP COLUMN IF EXISTS "status";
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE "user_space" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+ALTER TABLE "user_space" ADD COLUMN "ban_reason" varchar;
+ALTER TABLE "user_space" ADD COLUMN "joined_at" timestamptz NOT NULL DEFAULT now();
+
+CREATE INDEX "user_space_space_id_status_idx" ON "user_space" ("space_id", "status");
+
+ALTER TABLE "space" ADD COLUMN "join_approval" bool NOT NULL DEFAULT false;
+
+COMMIT;
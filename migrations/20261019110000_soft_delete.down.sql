@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP INDEX IF EXISTS "user_event_deleted_at_idx";
+DROP INDEX IF EXISTS "user_space_deleted_at_idx";
+DROP INDEX IF EXISTS "user_deleted_at_idx";
+DROP INDEX IF EXISTS "event_deleted_at_idx";
+DROP INDEX IF EXISTS "space_deleted_at_idx";
+
+ALTER TABLE "user_event" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "user_space" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "event" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "space" DROP COLUMN IF EXISTS "deleted_at";
+
+COMMIT;
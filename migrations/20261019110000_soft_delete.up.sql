@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Rows deleted together (e.g. a space with its memberships and events) share
+-- the same deleted_at, so restore reverts exactly that deletion.
+ALTER TABLE "space" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "event" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "user" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "user_space" ADD COLUMN "deleted_at" timestamptz;
+ALTER TABLE "user_event" ADD COLUMN "deleted_at" timestamptz;
+
+CREATE INDEX "space_deleted_at_idx" ON "space" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "event_deleted_at_idx" ON "event" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "user_deleted_at_idx" ON "user" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "user_space_deleted_at_idx" ON "user_space" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX "user_event_deleted_at_idx" ON "user_event" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+COMMIT;
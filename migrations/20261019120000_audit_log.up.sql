@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    space_id    int         NOT NULL,
+    actor_id    int,
+    action      varchar     NOT NULL,
+    target_type varchar     NOT NULL,
+    target_id   int         NOT NULL,
+    diff        jsonb       NOT NULL DEFAULT '{}',
+    request_id  varchar     NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_space_created_idx ON audit_log (space_id, created_at DESC);
+
+-- audit log is append-only
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_immutable();
+
+COMMIT;
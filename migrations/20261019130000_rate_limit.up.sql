@@ -0,0 +1,14 @@
+BEGIN;
+
+-- token buckets shared by all replicas, NULL state means a new bucket
+CREATE TABLE IF NOT EXISTS rate_limit
+(
+    key        varchar PRIMARY KEY,
+    tokens     double precision,
+    updated_at timestamptz,
+    full_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_full_at_idx ON rate_limit (full_at);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- responses of create requests replayed on retries, NULL status means request is in progress
+CREATE TABLE IF NOT EXISTS idempotency_key
+(
+    key         varchar PRIMARY KEY,
+    fingerprint varchar     NOT NULL,
+    status      int,
+    headers     jsonb,
+    body        bytea,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    expires_at  timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_key_expires_at_idx ON idempotency_key (expires_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE user_space DROP COLUMN IF EXISTS version;
+ALTER TABLE "user" DROP COLUMN IF EXISTS version;
+ALTER TABLE space DROP COLUMN IF EXISTS version;
+
+COMMIT;
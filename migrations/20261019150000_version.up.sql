@@ -0,0 +1,8 @@
+BEGIN;
+
+-- versions for optimistic concurrency control, every update increments them
+ALTER TABLE space ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
+ALTER TABLE user_space ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS space_settings;
+
+COMMIT;
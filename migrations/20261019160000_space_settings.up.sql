@@ -0,0 +1,26 @@
+BEGIN;
+
+-- settings are never updated in place, every change is a new version
+CREATE TABLE IF NOT EXISTS space_settings
+(
+    space_id   int         NOT NULL REFERENCES space (id),
+    version    int         NOT NULL,
+    settings   jsonb       NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (space_id, version)
+);
+
+-- first version of existing spaces keeps their timezone and join approval
+INSERT INTO space_settings (space_id, version, settings)
+SELECT id, 1, jsonb_build_object(
+        'cadence', 'weekly',
+        'group_size', 2,
+        'repeat_window', 4,
+        'min_rating', 0,
+        'join_mode', CASE WHEN join_approval THEN 'approval' ELSE 'open' END,
+        'language', 'en',
+        'timezone', coalesce(timezone, 'UTC'))
+FROM space
+ON CONFLICT DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS meeting_participant;
+DROP TABLE IF EXISTS meeting;
+DROP TABLE IF EXISTS round;
+
+COMMIT;
@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS round
+(
+    id               int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    space_id         int         NOT NULL REFERENCES space (id),
+    settings_version int         NOT NULL,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    FOREIGN KEY (space_id, settings_version) REFERENCES space_settings (space_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS round_space_id_idx ON round (space_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS meeting
+(
+    id           int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    round_id     int         NOT NULL REFERENCES round (id),
+    space_id     int         NOT NULL REFERENCES space (id),
+    state        varchar     NOT NULL DEFAULT 'proposed',
+    scheduled_at timestamptz,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    version      int         NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS meeting_round_id_idx ON meeting (round_id);
+
+-- responses are kept per participant, declines and no-shows make member reliability
+CREATE TABLE IF NOT EXISTS meeting_participant
+(
+    meeting_id   int         NOT NULL REFERENCES meeting (id),
+    user_id      int         NOT NULL REFERENCES "user" (id),
+    response     varchar     NOT NULL DEFAULT 'pending',
+    held         bool        NOT NULL DEFAULT false,
+    no_show      bool        NOT NULL DEFAULT false,
+    responded_at timestamptz,
+    PRIMARY KEY (meeting_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS meeting_participant_user_id_idx ON meeting_participant (user_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE meeting DROP COLUMN IF EXISTS proposed_slots;
+
+ALTER TABLE user_space DROP COLUMN IF EXISTS availability;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- weekly availability pattern, {"timezone": "...", "ranges": [{"weekday", "start", "end"}]}
+ALTER TABLE user_space ADD COLUMN IF NOT EXISTS availability jsonb;
+
+ALTER TABLE meeting ADD COLUMN IF NOT EXISTS proposed_slots timestamptz[] NOT NULL DEFAULT '{}';
+
+COMMIT;
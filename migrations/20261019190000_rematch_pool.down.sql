@@ -0,0 +1,7 @@
+BEGIN;
+
+UPDATE space_settings SET settings = settings - 'rematch_hours';
+
+DROP TABLE IF EXISTS rematch_pool;
+
+COMMIT;
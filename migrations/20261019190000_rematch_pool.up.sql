@@ -0,0 +1,20 @@
+BEGIN;
+
+-- members whose meeting was declined or cancelled wait here for a new partner in the same round
+CREATE TABLE IF NOT EXISTS rematch_pool
+(
+    round_id   int         NOT NULL REFERENCES round (id),
+    user_id    int         NOT NULL REFERENCES "user" (id),
+    meeting_id int         NOT NULL REFERENCES meeting (id),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (round_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS rematch_pool_user_id_idx ON rematch_pool (user_id);
+
+-- stored settings predate rematch, they get its default
+UPDATE space_settings
+SET settings = settings || '{"rematch_hours": 48}'::jsonb
+WHERE NOT settings ? 'rematch_hours';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+UPDATE space_settings SET settings = settings - 'mode' - 'group_min' - 'group_max';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- stored settings predate groups mode, they keep matching pairs
+UPDATE space_settings
+SET settings = '{"mode": "pairs", "group_min": 3, "group_max": 6}'::jsonb || settings
+WHERE NOT settings ? 'mode';
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+UPDATE space_settings SET settings = settings - 'mentor_role' - 'mentee_role' - 'mentor_capacity';
+
+-- spaces in mentoring mode go back to pairs
+UPDATE space_settings SET settings = settings || '{"mode": "pairs"}'::jsonb WHERE settings ->> 'mode' = 'mentoring';
+
+DROP TABLE IF EXISTS mentorship;
+
+ALTER TABLE user_space
+    DROP COLUMN IF EXISTS match_role,
+    DROP COLUMN IF EXISTS capacity;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+ALTER TABLE user_space
+    ADD COLUMN IF NOT EXISTS match_role varchar(30),
+    ADD COLUMN IF NOT EXISTS capacity   int;
+
+-- a mentee has one mentor at a time, ended mentorships are kept as history
+CREATE TABLE IF NOT EXISTS mentorship
+(
+    id         int GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    space_id   int         NOT NULL REFERENCES space (id),
+    mentor_id  int         NOT NULL REFERENCES "user" (id),
+    mentee_id  int         NOT NULL REFERENCES "user" (id),
+    started_at timestamptz NOT NULL DEFAULT now(),
+    ended_at   timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS mentorship_active_mentee_idx ON mentorship (space_id, mentee_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS mentorship_mentor_id_idx ON mentorship (mentor_id);
+
+-- stored settings predate mentoring mode, they get its defaults
+UPDATE space_settings
+SET settings = '{"mentor_role": "mentor", "mentee_role": "mentee", "mentor_capacity": 3}'::jsonb || settings
+WHERE NOT settings ? 'mentor_role';
+
+COMMIT;
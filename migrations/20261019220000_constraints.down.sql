@@ -0,0 +1,5 @@
+BEGIN;
+
+UPDATE space_settings SET settings = settings - 'constraints';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- stored settings predate constraint rules, they have none
+UPDATE space_settings
+SET settings = settings || '{"constraints": []}'::jsonb
+WHERE NOT settings ? 'constraints';
+
+COMMIT;
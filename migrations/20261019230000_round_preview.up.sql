@@ -0,0 +1,18 @@
+BEGIN;
+
+-- previews are matched rounds kept for admins to review before publishing
+CREATE TABLE IF NOT EXISTS round_preview
+(
+    id               uuid PRIMARY KEY,
+    space_id         int         NOT NULL REFERENCES space (id),
+    admin_id         int         NOT NULL REFERENCES "user" (id),
+    settings_version int         NOT NULL,
+    preview          jsonb       NOT NULL,
+    created_at       timestamptz NOT NULL DEFAULT now(),
+    expires_at       timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS round_preview_space_id_idx ON round_preview (space_id);
+CREATE INDEX IF NOT EXISTS round_preview_expires_at_idx ON round_preview (expires_at);
+
+COMMIT;
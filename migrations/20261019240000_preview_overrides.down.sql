@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE round_preview
+    DROP COLUMN IF EXISTS version;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- admins override previews concurrently, the version keeps their edits apart
+ALTER TABLE round_preview
+    ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1;
+
+COMMIT;
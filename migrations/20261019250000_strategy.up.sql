@@ -0,0 +1,8 @@
+BEGIN;
+
+-- stored settings predate matching strategies, they keep matching as before
+UPDATE space_settings
+SET settings = '{"strategy": "default", "strategy_params": {}}'::jsonb || settings
+WHERE NOT settings ? 'strategy';
+
+COMMIT;
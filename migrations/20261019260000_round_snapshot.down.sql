@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS round_snapshot;
+
+ALTER TABLE round
+    DROP COLUMN IF EXISTS seed,
+    DROP COLUMN IF EXISTS strategy,
+    DROP COLUMN IF EXISTS strategy_version;
+
+COMMIT;
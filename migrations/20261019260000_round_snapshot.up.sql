@@ -0,0 +1,17 @@
+BEGIN;
+
+-- rounds before seeds were recorded keep nulls and can't be replayed
+ALTER TABLE round
+    ADD COLUMN IF NOT EXISTS seed             bigint,
+    ADD COLUMN IF NOT EXISTS strategy         varchar(50),
+    ADD COLUMN IF NOT EXISTS strategy_version int;
+
+-- inputs of a round and its meetings as matched, for replays
+CREATE TABLE IF NOT EXISTS round_snapshot
+(
+    round_id int PRIMARY KEY REFERENCES round (id),
+    snapshot jsonb NOT NULL,
+    matched  jsonb NOT NULL
+);
+
+COMMIT;
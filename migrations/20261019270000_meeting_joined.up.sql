@@ -0,0 +1,7 @@
+BEGIN;
+
+-- participants who joined from the rematch pool leave accepted meetings without declining them
+ALTER TABLE meeting_participant
+    ADD COLUMN IF NOT EXISTS joined bool NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE meeting_participant
+    DROP COLUMN IF EXISTS reported_by;
+
+COMMIT;
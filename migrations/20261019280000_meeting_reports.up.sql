@@ -0,0 +1,7 @@
+BEGIN;
+
+-- no-show claims of participants, a participant is a no-show once every other one reported them
+ALTER TABLE meeting_participant
+    ADD COLUMN IF NOT EXISTS reported_by int[];
+
+COMMIT;
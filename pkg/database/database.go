@@ -33,6 +33,7 @@ type (
 		connAttempts int32
 		connTimeout  time.Duration
 		isolation    pgx.TxIsoLevel
+		tracer       pgx.QueryTracer
 
 		Builder squirrel.StatementBuilderType
 		Pool    *pgxpool.Pool
@@ -63,6 +64,9 @@ func New(cfg *config.Config, opts ...Option) (*Postgres, error) {
 	}
 
 	poolConfig.MaxConns = pg.maxPoolSize
+	if pg.tracer != nil {
+		poolConfig.ConnConfig.Tracer = pg.tracer
+	}
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -35,3 +35,10 @@ func Isolation(isolation pgx.TxIsoLevel) Option {
 		c.isolation = isolation
 	}
 }
+
+// Tracer traces every query, e.g. with otelpgx.
+func Tracer(tracer pgx.QueryTracer) Option {
+	return func(c *Postgres) {
+		c.tracer = tracer
+	}
+}
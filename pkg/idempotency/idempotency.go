@@ -0,0 +1,49 @@
+// Package idempotency defines storage of responses replayed for retried requests.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// LockTimeout is how long a request may hold its key. Keys of requests that
+// didn't complete in time, e.g. because replica crashed, can be claimed again.
+const LockTimeout = time.Minute
+
+// Response is replayed to retries of the request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record is a key claimed by a request. Response is nil while request is in progress.
+type Record struct {
+	Fingerprint string
+	Response    *Response
+}
+
+// Store keeps records until they expire.
+type Store interface {
+	// Claim claims key for a new request, if key is taken it returns its record instead.
+	Claim(ctx context.Context, key, fingerprint string, ttl time.Duration) (rec *Record, claimed bool, err error)
+	// Complete stores response of the request that claimed key.
+	Complete(ctx context.Context, key string, resp Response) error
+	// Release frees key, so request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Fingerprint identifies request, retries must have the same one.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	body := []byte(`{"name":"go"}`)
+
+	assert.Equal(t, Fingerprint("POST", "/spaces", body), Fingerprint("POST", "/spaces", body))
+	assert.NotEqual(t, Fingerprint("POST", "/spaces", body), Fingerprint("POST", "/spaces", []byte(`{"name":"rust"}`)))
+	assert.NotEqual(t, Fingerprint("POST", "/spaces", body), Fingerprint("POST", "/events", body))
+	// separator keeps path and body apart
+	assert.NotEqual(t, Fingerprint("POST", "/a", []byte("b")), Fingerprint("POST", "/ab", nil))
+}
@@ -0,0 +1,45 @@
+// Package mergepatch implements JSON Merge Patch, RFC 7396.
+package mergepatch
+
+import "encoding/json"
+
+// Merge applies patch to target, both are decoded JSON values as produced by
+// encoding/json into any. Object members of patch replace members of target,
+// null removes them, anything that isn't an object replaces target as a whole.
+// Target is not modified, patched objects are copies.
+func Merge(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	orig, _ := target.(map[string]any)
+	t := make(map[string]any, len(orig)+len(p))
+	for name, value := range orig {
+		t[name] = value
+	}
+
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+			continue
+		}
+		t[name] = Merge(t[name], value)
+	}
+
+	return t
+}
+
+// Apply applies JSON encoded patch to JSON encoded doc.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var target, p any
+
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Merge(target, p))
+}
@@ -0,0 +1,86 @@
+// Package metrics holds Prometheus collectors of the service.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "involvio"
+
+// Registry is exposed at /metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP
+var (
+	RequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	Responses = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "responses_total",
+		Help:      "HTTP responses by operation and status code.",
+	}, []string{"operation", "status"})
+
+	RateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by rate limiter by operation.",
+	}, []string{"operation"})
+)
+
+// Domain
+var (
+	SpacesCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "spaces_created_total",
+		Help:      "Spaces created.",
+	})
+
+	SpaceJoins = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "space_joins_total",
+		Help:      "Join requests by resulting membership status.",
+	}, []string{"status"})
+
+	EventsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_created_total",
+		Help:      "Events created.",
+	})
+
+	EventJoins = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_joins_total",
+		Help:      "Users joined events.",
+	})
+
+	RoundsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rounds_created_total",
+		Help:      "Matching rounds run.",
+	})
+
+	MeetingTransitions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meeting_transitions_total",
+		Help:      "Meetings entering a state, proposed counts matched meetings.",
+	}, []string{"state"})
+)
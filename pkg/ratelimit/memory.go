@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// Memory keeps buckets in process, limits are not shared between replicas.
+type Memory struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	full time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{now: time.Now, buckets: make(map[string]memoryBucket)}
+}
+
+func (m *Memory) Take(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok, wait := limit.Take(m.buckets[key].Bucket, now)
+	m.buckets[key] = memoryBucket{Bucket: b, full: limit.Full(b)}
+
+	return ok, wait, nil
+}
+
+// sweep drops refilled buckets, a missing bucket behaves the same.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package ratelimit implements token bucket rate limiting with pluggable bucket storage.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit allows PerMinute requests on average with bursts up to Burst requests.
+// Zero PerMinute disables limiting.
+type Limit struct {
+	PerMinute float64
+	Burst     int
+}
+
+// Unlimited reports whether limit lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.PerMinute <= 0
+}
+
+// Bucket is the state of one key, stores persist it between requests.
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills bucket for time passed since last update and takes a token.
+// If there is no token it returns how long to wait for one.
+func (l Limit) Take(b Bucket, now time.Time) (Bucket, bool, time.Duration) {
+	burst := float64(max(l.Burst, 1))
+	rate := l.PerMinute / float64(time.Minute/time.Second)
+
+	switch {
+	case b.Updated.IsZero():
+		b.Tokens = burst
+	case now.After(b.Updated):
+		b.Tokens = math.Min(burst, b.Tokens+now.Sub(b.Updated).Seconds()*rate)
+	}
+	if now.After(b.Updated) {
+		b.Updated = now
+	}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return b, true, 0
+	}
+
+	wait := time.Duration((1 - b.Tokens) / rate * float64(time.Second))
+
+	return b, false, wait
+}
+
+// Full returns when bucket refills completely, after that it is equal to a new one.
+func (l Limit) Full(b Bucket) time.Time {
+	missing := float64(max(l.Burst, 1)) - b.Tokens
+	rate := l.PerMinute / float64(time.Minute/time.Second)
+
+	return b.Updated.Add(time.Duration(missing / rate * float64(time.Second)))
+}
+
+// Store keeps buckets. Take must be atomic per key, so replicas sharing a store share limits.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Limits holds limit of each operation.
+type Limits struct {
+	Default    Limit
+	Operations map[string]Limit
+}
+
+// For returns limit of operation, Default if it has none.
+func (l Limits) For(operation string) Limit {
+	if limit, ok := l.Operations[operation]; ok {
+		return limit
+	}
+
+	return l.Default
+}
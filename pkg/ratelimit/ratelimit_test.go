@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitTake(t *testing.T) {
+	limit := Limit{PerMinute: 60, Burst: 3}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var (
+		b    Bucket
+		ok   bool
+		wait time.Duration
+	)
+
+	// new bucket allows a burst
+	for i := 0; i < 3; i++ {
+		b, ok, _ = limit.Take(b, now)
+		require.True(t, ok, "request %d", i)
+	}
+
+	b, ok, wait = limit.Take(b, now)
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	// one token per second
+	b, ok, _ = limit.Take(b, now.Add(500*time.Millisecond))
+	assert.False(t, ok)
+	b, ok, _ = limit.Take(b, now.Add(time.Second))
+	assert.True(t, ok)
+
+	// refill is capped by burst
+	b, _, _ = limit.Take(b, now.Add(time.Hour))
+	assert.InDelta(t, 2, b.Tokens, 1e-9)
+}
+
+func TestLimitTakeClockSkew(t *testing.T) {
+	limit := Limit{PerMinute: 60, Burst: 1}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b, ok, _ := limit.Take(Bucket{}, now)
+	require.True(t, ok)
+
+	// time going backwards neither refills nor moves the bucket back
+	b, ok, _ = limit.Take(b, now.Add(-time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, now, b.Updated)
+}
+
+func TestLimitFull(t *testing.T) {
+	limit := Limit{PerMinute: 30, Burst: 2}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(4*time.Second), limit.Full(Bucket{Tokens: 0, Updated: now}))
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	limit := Limit{PerMinute: 1, Burst: 1}
+
+	ok, _, err := m.Take(context.Background(), "user:1", limit)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, wait, _ := m.Take(context.Background(), "user:1", limit)
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, wait)
+
+	// keys are independent
+	ok, _, _ = m.Take(context.Background(), "user:2", limit)
+	assert.True(t, ok)
+
+	// refilled buckets are swept
+	now = now.Add(2 * time.Minute)
+	_, _, _ = m.Take(context.Background(), "user:3", limit)
+	assert.Len(t, m.buckets, 1)
+}
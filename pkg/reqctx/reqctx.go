@@ -0,0 +1,33 @@
+// Package reqctx carries request scoped values, such as acting user and request id, through context.
+package reqctx
+
+import "context"
+
+type ctxKey int
+
+const (
+	actorKey ctxKey = iota
+	requestIDKey
+)
+
+// WithActor returns ctx carrying id of the user performing the request.
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorKey, userID)
+}
+
+// Actor returns id of the user performing the request, if known.
+func Actor(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(actorKey).(int)
+	return id, ok
+}
+
+// WithRequestID -.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns request id or empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
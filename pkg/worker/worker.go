@@ -0,0 +1,92 @@
+// Package worker runs periodic background jobs and keeps their status.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a single run of a background job.
+type Job func(ctx context.Context) error
+
+// Status -.
+type Status struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	LastRun   time.Time `json:"last_run"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Worker runs Job every interval.
+type Worker struct {
+	name     string
+	interval time.Duration
+	job      Job
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// New -.
+func New(name string, interval time.Duration, job Job) *Worker {
+	return &Worker{
+		name:     name,
+		interval: interval,
+		job:      job,
+		status:   Status{Name: name},
+	}
+}
+
+// Run executes job right away and then every interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	log := slog.With(slog.String("worker", w.name))
+
+	w.setRunning(true)
+	defer w.setRunning(false)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		err := w.job(ctx)
+		if err != nil {
+			log.Error("job failed", slog.String("error", err.Error()))
+		}
+		w.finished(err)
+
+		select {
+		case <-ctx.Done():
+			log.Info("worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns the state of the last run.
+func (w *Worker) Status() Status {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.status
+}
+
+func (w *Worker) setRunning(running bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.status.Running = running
+}
+
+func (w *Worker) finished(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.status.LastRun = time.Now()
+	w.status.LastError = ""
+	if err != nil {
+		w.status.LastError = err.Error()
+	}
+}